@@ -0,0 +1,424 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.LoadSyntax | packages.NeedModule
+
+var errPackages = errors.New("analysis failure : errors in loaded packages")
+
+type Config struct {
+	Dir       string
+	Platforms []string // GOOS/GOARCH pairs, empty means the current platform
+	Tags      []string
+	TagSets   []string // comma separated tag lists analyzed separately (combined with Tags), empty means Tags alone
+	Verbose   bool
+}
+
+// Use of a standard library package or symbol in analyzed code.
+type Usage struct {
+	Pos          token.Pos
+	Position     token.Position
+	Package      string
+	Symbol       string // empty for the import of the package itself
+	AddedIn      string
+	DeprecatedIn string
+	Replacement  string   // advice for deprecated api (see versiondb.VersionDatas.Replacement)
+	Configs      []string // configurations (platform and tags) where the usage appears, empty when it appears in all
+}
+
+// Return the name in the "pkg.Symbol" form (or "pkg" alone).
+func (u Usage) Name() string {
+	if u.Symbol == "" {
+		return u.Package
+	}
+	return u.Package + "." + u.Symbol
+}
+
+// Return the "pkg.Symbol deprecated in goN" message, followed by the replacement advice if any.
+func (u Usage) DeprecatedMessage() string {
+	message := u.Name() + " deprecated in " + u.DeprecatedIn
+	switch u.Replacement {
+	case "":
+	case versiondb.NoReplacement:
+		message += ", without replacement"
+	default:
+		message += ", use " + u.Replacement + " instead"
+	}
+	return message
+}
+
+// Return usages of deprecated api.
+func Deprecated(usages []Usage) []Usage {
+	var res []Usage
+	for _, usage := range usages {
+		if usage.DeprecatedIn != "" {
+			res = append(res, usage)
+		}
+	}
+	return res
+}
+
+// Return the most recent introducing version among usages (the minimum go version needed) and the usages requiring it.
+func Minimum(usages []Usage) (string, []Usage) {
+	minVersion := ""
+	var responsibles []Usage
+	for _, usage := range usages {
+		switch cmp := versiondb.CompareVersion(usage.AddedIn, minVersion); {
+		case cmp > 0:
+			minVersion, responsibles = usage.AddedIn, []Usage{usage}
+		case cmp == 0:
+			responsibles = append(responsibles, usage)
+		}
+	}
+	return minVersion, responsibles
+}
+
+// Return usages whose introducing version is strictly newer than target.
+func Newer(usages []Usage, target string) []Usage {
+	var res []Usage
+	for _, usage := range usages {
+		if versiondb.CompareVersion(usage.AddedIn, target) > 0 {
+			res = append(res, usage)
+		}
+	}
+	return res
+}
+
+// Return usages appearing in configuration (as named by Configs).
+func InConfig(usages []Usage, config string) []Usage {
+	var res []Usage
+	for _, usage := range usages {
+		if len(usage.Configs) == 0 || slices.Contains(usage.Configs, config) {
+			res = append(res, usage)
+		}
+	}
+	return res
+}
+
+// Return the names of the configurations analyzed with conf, each platform is combined with each tag set.
+func Configs(conf Config) []string {
+	var configs []string
+	for _, platform := range platformsOf(conf) {
+		for _, tagSet := range tagSetsOf(conf) {
+			configs = append(configs, configName(platform, tagSet))
+		}
+	}
+	return configs
+}
+
+// Load and type check the packages matching patterns in each configuration, then list their standard library usages.
+// The files excluded from a configuration are only analyzed in the others, with the versions of their platform.
+func Run(vd versiondb.VersionDatas, conf Config, patterns ...string) ([]Usage, error) {
+	configCount := 0
+	indexes := map[usageKey]int{}
+	var usages []Usage
+	for _, platform := range platformsOf(conf) {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		for _, tagSet := range tagSetsOf(conf) {
+			configCount++
+			pkgs, err := loadPackages(conf, platform, tagSet, patterns)
+			if err != nil {
+				return nil, err
+			}
+
+			config := configName(platform, tagSet)
+			seen := map[usageKey]struct{}{} // a file can belong to several packages (tests)
+			for _, pkg := range pkgs {
+				for _, usage := range InspectOn(vd, pkg.Fset, pkg.Syntax, pkg.TypesInfo, pkg.Types, goos, goarch) {
+					key := usageKey{position: usage.Position, name: usage.Name()}
+					if _, ok := seen[key]; ok {
+						continue
+					}
+					seen[key] = struct{}{}
+
+					index, ok := indexes[key]
+					if !ok {
+						indexes[key] = len(usages)
+						usages = append(usages, usage)
+						index = len(usages) - 1
+					} else if versiondb.CompareVersion(usage.AddedIn, usages[index].AddedIn) > 0 {
+						usages[index].AddedIn = usage.AddedIn // keep the most demanding platform
+					}
+					usages[index].Configs = append(usages[index].Configs, config)
+				}
+			}
+		}
+	}
+
+	for index := range usages {
+		if len(usages[index].Configs) == configCount {
+			usages[index].Configs = nil
+		}
+	}
+
+	slices.SortFunc(usages, compareUsage)
+	return usages, nil
+}
+
+// Return the directories of the packages matching patterns, in any of the configurations of conf
+// (without type checking them).
+func PackageDirs(conf Config, patterns ...string) (map[string]struct{}, error) {
+	dirs := map[string]struct{}{}
+	for _, platform := range platformsOf(conf) {
+		env := os.Environ()
+		if platform != "" {
+			goos, goarch, _ := strings.Cut(platform, "/")
+			env = append(env, "GOOS="+goos, "GOARCH="+goarch)
+		}
+
+		pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles, Dir: conf.Dir, Env: env}, patterns...)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			// a package excluded by the build constraints of the platform only has ignored files
+			for _, file := range slices.Concat(pkg.GoFiles, pkg.IgnoredFiles) {
+				dirs[filepath.Dir(file)] = struct{}{}
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// Load and type check the packages matching patterns for platform (a GOOS/GOARCH pair, empty for the current one).
+func LoadPackages(conf Config, platform string, patterns ...string) ([]*packages.Package, error) {
+	return loadPackages(conf, platform, "", patterns)
+}
+
+func loadPackages(conf Config, platform string, tagSet string, patterns []string) ([]*packages.Package, error) {
+	tags := conf.Tags
+	if tagSet != "" {
+		tags = append(slices.Clip(tags), strings.Split(tagSet, ",")...)
+	}
+
+	var buildFlags []string
+	if len(tags) != 0 {
+		buildFlags = []string{"-tags=" + strings.Join(tags, ",")}
+	}
+
+	env := os.Environ()
+	if platform != "" {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		env = append(env, "GOOS="+goos, "GOARCH="+goarch)
+	}
+
+	if conf.Verbose {
+		fmt.Println("Analyze", strings.Join(patterns, " "), "for", configName(platform, tagSet))
+	}
+
+	loadConf := &packages.Config{Mode: loadMode, Dir: conf.Dir, Env: env, BuildFlags: buildFlags, Fset: token.NewFileSet()}
+	pkgs, err := packages.Load(loadConf, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	if packages.PrintErrors(pkgs) != 0 {
+		return nil, errPackages
+	}
+	return pkgs, nil
+}
+
+// List the standard library usages in files (which must belong to the package self).
+func Inspect(vd versiondb.VersionDatas, fset *token.FileSet, files []*ast.File, info *types.Info, self *types.Package) []Usage {
+	return InspectOn(vd, fset, files, info, self, "", "")
+}
+
+// Same as Inspect with the introducing versions of the platforms matching goos and goarch.
+func InspectOn(vd versiondb.VersionDatas, fset *token.FileSet, files []*ast.File, info *types.Info, self *types.Package, goos string, goarch string) []Usage {
+	var usages []Usage
+	appendUsage := func(pos token.Pos, pkg string, symbol string) {
+		if symbolData, err := vd.SinceOn(pkg, symbol, goos, goarch); err == nil {
+			usage := Usage{
+				Pos: pos, Position: fset.Position(pos), Package: pkg, Symbol: symbol,
+				AddedIn: symbolData[0], DeprecatedIn: symbolData[1],
+			}
+			if usage.DeprecatedIn != "" {
+				usage.Replacement = vd.Replacement(pkg, symbol)
+			}
+			usages = append(usages, usage)
+		}
+	}
+
+	fieldOwners := map[*ast.Ident]*types.TypeName{}
+	for _, file := range files {
+		for _, spec := range file.Imports {
+			if pkgName := info.PkgNameOf(spec); pkgName != nil {
+				appendUsage(spec.Path.Pos(), pkgName.Imported().Path(), "")
+			}
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch typed := n.(type) {
+			case *ast.SelectorExpr:
+				if selection, ok := info.Selections[typed]; ok && selection.Kind() == types.FieldVal {
+					if owner := fieldOwner(selection); owner != nil {
+						fieldOwners[typed.Sel] = owner
+					}
+				}
+			case *ast.CompositeLit:
+				owner := namedOf(info.TypeOf(typed))
+				if owner == nil {
+					break
+				}
+				for _, elt := range typed.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.Ident); ok {
+							fieldOwners[key] = owner
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	for ident, obj := range info.Uses {
+		objPkg := obj.Pkg()
+		if objPkg == nil || objPkg == self {
+			continue
+		}
+
+		symbol := ""
+		switch typed := obj.(type) {
+		case *types.PkgName:
+			continue
+		case *types.Func:
+			if recv := typed.Type().(*types.Signature).Recv(); recv != nil {
+				owner := namedOf(recv.Type())
+				if owner == nil {
+					continue
+				}
+				symbol = owner.Name() + "." + typed.Name()
+			} else if isPackageLevel(obj) {
+				symbol = typed.Name()
+			}
+		case *types.Var:
+			if typed.IsField() {
+				owner, ok := fieldOwners[ident]
+				if !ok {
+					continue
+				}
+				symbol = owner.Name() + "." + typed.Name()
+			} else if isPackageLevel(obj) {
+				symbol = typed.Name()
+			}
+		default:
+			if isPackageLevel(obj) {
+				symbol = obj.Name()
+			}
+		}
+
+		if symbol != "" && obj.Exported() {
+			appendUsage(ident.Pos(), objPkg.Path(), symbol)
+		}
+	}
+
+	slices.SortFunc(usages, compareUsage)
+	return usages
+}
+
+type usageKey struct {
+	position token.Position
+	name     string
+}
+
+func compareUsage(a Usage, b Usage) int {
+	if res := strings.Compare(a.Position.Filename, b.Position.Filename); res != 0 {
+		return res
+	}
+	if res := a.Position.Offset - b.Position.Offset; res != 0 {
+		return res
+	}
+	return strings.Compare(a.Name(), b.Name())
+}
+
+// Follow the embedding path of a field selection to find the named type declaring the field.
+func fieldOwner(selection *types.Selection) *types.TypeName {
+	current := selection.Recv()
+	index := selection.Index()
+	for _, fieldIndex := range index[:len(index)-1] {
+		structType, ok := deref(current).Underlying().(*types.Struct)
+		if !ok {
+			return nil
+		}
+		current = structType.Field(fieldIndex).Type()
+	}
+	return namedOf(current)
+}
+
+func deref(t types.Type) types.Type {
+	if pointer, ok := types.Unalias(t).(*types.Pointer); ok {
+		return pointer.Elem()
+	}
+	return t
+}
+
+// Return the configuration name, like "linux/amd64" or "linux/amd64 [integration]".
+func configName(platform string, tagSet string) string {
+	name := platform
+	if name == "" {
+		name = "default"
+	}
+	if tagSet != "" {
+		name += " [" + tagSet + "]"
+	}
+	return name
+}
+
+func platformsOf(conf Config) []string {
+	if len(conf.Platforms) == 0 {
+		return []string{""}
+	}
+	return conf.Platforms
+}
+
+func tagSetsOf(conf Config) []string {
+	if len(conf.TagSets) == 0 {
+		return []string{""}
+	}
+	return conf.TagSets
+}
+
+func isPackageLevel(obj types.Object) bool {
+	return obj.Parent() != nil && obj.Parent() == obj.Pkg().Scope()
+}
+
+func namedOf(t types.Type) *types.TypeName {
+	if t == nil {
+		return nil
+	}
+	if named, ok := types.Unalias(deref(t)).(*types.Named); ok {
+		return named.Origin().Obj()
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package analysis
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+var ErrNoUsage = errors.New("no standard library api at this position")
+
+// Return the standard library usage at the position (column in bytes, like go/token) of file,
+// the package containing file is loaded for the first platform of conf (the current one when empty).
+func At(vd versiondb.VersionDatas, conf Config, file string, line int, column int) (Usage, error) {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	platform := ""
+	if len(conf.Platforms) != 0 {
+		platform = conf.Platforms[0]
+	}
+	conf.Dir = filepath.Dir(absFile)
+	pkgs, err := loadPackages(conf, platform, "", []string{"file=" + absFile})
+	if err != nil {
+		return Usage{}, err
+	}
+
+	goos, goarch, _ := strings.Cut(platform, "/")
+	for _, pkg := range pkgs {
+		for _, usage := range InspectOn(vd, pkg.Fset, pkg.Syntax, pkg.TypesInfo, pkg.Types, goos, goarch) {
+			position := usage.Position
+			if position.Filename == absFile && position.Line == line && position.Column <= column && column < position.Column+usageWidth(usage) {
+				return usage, nil
+			}
+		}
+	}
+	return Usage{}, ErrNoUsage
+}
+
+// Return the length of the source text of the usage : the quoted import path or the selected identifier.
+func usageWidth(usage Usage) int {
+	if usage.Symbol == "" {
+		return len(usage.Package) + 2
+	}
+	return len(usage.Symbol) - strings.LastIndexByte(usage.Symbol, '.') - 1 // no error when there is no dot
+}
@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package analysis
+
+import (
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"golang.org/x/tools/imports"
+)
+
+const snippetName = "snippet.go"
+
+var errSnippet = errors.New("snippet failure : not a file, declarations or statements")
+
+// List the standard library usages of a code fragment : a complete file, declarations or statements
+// (wrapped in a synthetic main). Missing imports are added and type errors are ignored, the usages
+// are given with the versions of the platforms matching goos and goarch.
+func Snippet(vd versiondb.VersionDatas, src []byte, goos string, goarch string) ([]Usage, error) {
+	fset := token.NewFileSet()
+	var file *ast.File
+	for _, wrap := range []func([]byte) []byte{wrapNone, wrapDeclarations, wrapStatements} {
+		wrapped, err := imports.Process(snippetName, wrap(src), nil)
+		if err != nil {
+			continue
+		}
+		if file, err = parser.ParseFile(fset, snippetName, wrapped, parser.SkipObjectResolution); err == nil {
+			break
+		}
+	}
+	if file == nil {
+		return nil, errSnippet
+	}
+
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{}, Defs: map[*ast.Ident]types.Object{}, Uses: map[*ast.Ident]types.Object{},
+		Implicits: map[ast.Node]types.Object{}, Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	typesConf := types.Config{Importer: importer.Default(), Error: func(error) {}} // fragments are rarely complete
+	self, _ := typesConf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+	return InspectOn(vd, fset, []*ast.File{file}, info, self, goos, goarch), nil
+}
+
+func wrapNone(src []byte) []byte {
+	return src
+}
+
+func wrapDeclarations(src []byte) []byte {
+	return append([]byte("package main\n\n"), src...)
+}
+
+func wrapStatements(src []byte) []byte {
+	wrapped := append([]byte("package main\n\nfunc main() {\n"), src...)
+	return append(wrapped, "\n}\n"...)
+}
@@ -0,0 +1,197 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package analyzer exposes gosince checks as golang.org/x/tools/go/analysis analyzers.
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	gsanalysis "github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"golang.org/x/tools/go/analysis"
+)
+
+const docUrl = "https://github.com/dvaumoron/gosince"
+
+var errNoTarget = errors.New("no target go version : use the go flag or a module with a go directive")
+
+var (
+	Deprecated = &analysis.Analyzer{
+		Name: "gosincedeprecated",
+		Doc:  "report uses of deprecated standard library api",
+		URL:  docUrl,
+		Run:  runDeprecated,
+	}
+	MinVersion = &analysis.Analyzer{
+		Name: "gosince",
+		Doc:  "report uses of standard library api newer than the target go version (default to the go directive of the module)",
+		URL:  docUrl,
+		Run:  runMinVersion,
+	}
+
+	ignoreDeprecated string
+	ignoreMinVersion string
+	target           string
+
+	datasMutex sync.Mutex
+	datas      func() (versiondb.VersionDatas, error) = sync.OnceValues(loadDefaultDatas)
+)
+
+func init() {
+	Deprecated.Flags.StringVar(&ignoreDeprecated, "ignore", "", "comma separated patterns of symbols (pkg.Symbol) to ignore")
+	MinVersion.Flags.StringVar(&ignoreMinVersion, "ignore", "", "comma separated patterns of symbols (pkg.Symbol) to ignore")
+	MinVersion.Flags.StringVar(&target, "go", "", "target go version")
+}
+
+// Return all the gosince analyzers.
+func Analyzers() []*analysis.Analyzer {
+	return []*analysis.Analyzer{MinVersion, Deprecated}
+}
+
+// Lazily load the database with conf instead of the default configuration.
+func SetConfig(conf config.Config) {
+	datasMutex.Lock()
+	defer datasMutex.Unlock()
+
+	datas = sync.OnceValues(func() (versiondb.VersionDatas, error) {
+		return versiondb.LoadDatas(conf)
+	})
+}
+
+// Use vd instead of the database loaded with the default configuration.
+func SetDatas(vd versiondb.VersionDatas) {
+	datasMutex.Lock()
+	defer datasMutex.Unlock()
+
+	datas = func() (versiondb.VersionDatas, error) {
+		return vd, nil
+	}
+}
+
+// Check if args (without program name) come from go vet calling its -vettool :
+// a -flags or -V=full query, or a final .cfg file holding the unitchecker JSON configuration.
+func IsVetToolCall(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "-flags" || args[0] == "-V=full" {
+		return true
+	}
+
+	cfgPath := args[len(args)-1]
+	if !strings.HasSuffix(cfgPath, ".cfg") {
+		return false
+	}
+
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return false
+	}
+
+	var cfg struct {
+		ID         string
+		ImportPath string
+		GoFiles    []string
+	}
+	return json.Unmarshal(data, &cfg) == nil && cfg.ID != "" && cfg.ImportPath != ""
+}
+
+func getDatas() (versiondb.VersionDatas, error) {
+	datasMutex.Lock()
+	current := datas
+	datasMutex.Unlock()
+
+	return current()
+}
+
+func inspect(pass *analysis.Pass, ignore string) ([]gsanalysis.Usage, error) {
+	vd, err := getDatas()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := gsanalysis.Inspect(vd, pass.Fset, pass.Files, pass.TypesInfo, pass.Pkg)
+	if ignore == "" {
+		return usages, nil
+	}
+
+	patterns := strings.Split(ignore, ",")
+	res := usages[:0]
+	for _, usage := range usages {
+		if !matchAny(patterns, usage.Name()) {
+			res = append(res, usage)
+		}
+	}
+	return res, nil
+}
+
+func loadDefaultDatas() (versiondb.VersionDatas, error) {
+	repoPath, sourceUrl, err := config.InitDefault(config.EnvCachePath, config.EnvSourceUrl)
+	if err != nil {
+		return versiondb.VersionDatas{}, err
+	}
+	return versiondb.LoadDatas(config.Config{RepoPath: repoPath, SourceUrl: sourceUrl})
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.TrimSpace(pattern), name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func runDeprecated(pass *analysis.Pass) (any, error) {
+	usages, err := inspect(pass, ignoreDeprecated)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, usage := range gsanalysis.Deprecated(usages) {
+		pass.Reportf(usage.Pos, "%s", usage.DeprecatedMessage())
+	}
+	return nil, nil
+}
+
+func runMinVersion(pass *analysis.Pass) (any, error) {
+	currentTarget := target
+	if currentTarget == "" && pass.Module != nil {
+		currentTarget = pass.Module.GoVersion
+	}
+	if currentTarget = versiondb.NormalizeVersion(currentTarget); currentTarget == "" {
+		return nil, errNoTarget
+	}
+
+	usages, err := inspect(pass, ignoreMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, usage := range gsanalysis.Newer(usages, currentTarget) {
+		pass.Reportf(usage.Pos, "%s added in %s, newer than target %s", usage.Name(), usage.AddedIn, currentTarget)
+	}
+	return nil, nil
+}
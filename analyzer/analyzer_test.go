@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package analyzer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dvaumoron/gosince/analyzer"
+	"github.com/dvaumoron/gosince/versiondb/versiondbtest"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestMinVersion(t *testing.T) {
+	analyzer.SetDatas(versiondbtest.New(t))
+	setFlag(t, analyzer.MinVersion, "go", "1.17")
+	setFlag(t, analyzer.MinVersion, "ignore", "strings.CutP*")
+	setFlag(t, analyzer.Deprecated, "ignore", "strings.*")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.MinVersion, "newer")
+}
+
+func TestDeprecated(t *testing.T) {
+	analyzer.SetDatas(versiondbtest.New(t))
+	setFlag(t, analyzer.MinVersion, "ignore", "io/ioutil.*")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Deprecated, "deprecated")
+}
+
+func TestIsVetToolCall(t *testing.T) {
+	dir := t.TempDir()
+	vetCfg := filepath.Join(dir, "vet.cfg")
+	if err := os.WriteFile(vetCfg, []byte(`{"ID":"a","ImportPath":"a","GoFiles":["a.go"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	otherCfg := filepath.Join(dir, "other.cfg")
+	if err := os.WriteFile(otherCfg, []byte("key = value\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"-flags"}, want: true},
+		{args: []string{"-V=full"}, want: true},
+		{args: []string{"-V=short"}},
+		{args: []string{"-gosince.go=1.21", vetCfg}, want: true},
+		{args: []string{"--config", otherCfg}},
+		{args: []string{"missing.cfg"}},
+		{args: []string{"strings.Cut"}},
+		{},
+	} {
+		if got := analyzer.IsVetToolCall(test.args); got != test.want {
+			t.Errorf("IsVetToolCall(%q) = %t, want %t", test.args, got, test.want)
+		}
+	}
+}
+
+func setFlag(t *testing.T, a *analysis.Analyzer, name string, value string) {
+	t.Helper()
+
+	previous := a.Flags.Lookup(name).Value.String()
+	if err := a.Flags.Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		a.Flags.Set(name, previous)
+	})
+}
@@ -0,0 +1,13 @@
+package deprecated
+
+import (
+	"io"
+	"io/ioutil" // want `io/ioutil deprecated in go1.19, use os and io instead`
+)
+
+func read(r io.Reader) ([]byte, error) {
+	if _, err := ioutil.ReadAll(r); err != nil { // want `io/ioutil.ReadAll deprecated in go1.19`
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,15 @@
+package newer
+
+import (
+	"fmt"
+	"strings"
+)
+
+func cut(s string) string {
+	before, _, _ := strings.Cut(s, ",") // want `strings.Cut added in go1.18, newer than target go1.17`
+	after, _ := strings.CutPrefix(s, "-")
+	var b strings.Builder
+	b.WriteString(before)
+	fmt.Println(after)
+	return b.String()
+}
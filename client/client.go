@@ -0,0 +1,184 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package client calls the HTTP api of a gosince server (see gosince serve and server/openapi.yaml).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Package or symbol with its versions.
+type Entry struct {
+	Package      string   `json:"package"`
+	Symbol       string   `json:"symbol,omitempty"`
+	AddedIn      string   `json:"added_in"`
+	DeprecatedIn string   `json:"deprecated_in,omitempty"`
+	Platforms    []string `json:"platforms,omitempty"`
+}
+
+type AnalyzeRequest struct {
+	Dir       string   `json:"dir"`
+	Patterns  []string `json:"patterns,omitempty"`
+	Go        string   `json:"go,omitempty"`
+	Platforms []string `json:"platforms,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+type Usage struct {
+	File         string   `json:"file"`
+	Line         int      `json:"line"`
+	Column       int      `json:"column"`
+	Package      string   `json:"package"`
+	Symbol       string   `json:"symbol,omitempty"`
+	AddedIn      string   `json:"added_in"`
+	DeprecatedIn string   `json:"deprecated_in,omitempty"`
+	Configs      []string `json:"configs,omitempty"`
+}
+
+type AnalyzeResult struct {
+	MinVersion string  `json:"min_version"`
+	Usages     []Usage `json:"usages"`
+}
+
+// Error answered by the server.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprint("gosince server error (", e.StatusCode, ") : ", e.Message)
+}
+
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+	apiKey     string
+}
+
+// Return a client of the server at baseUrl (like "http://localhost:8080"), httpClient default to http.DefaultClient.
+func New(baseUrl string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseUrl: strings.TrimSuffix(baseUrl, "/"), httpClient: httpClient}
+}
+
+// Send key with each query, for servers requiring an api key.
+func (c *Client) SetApiKey(key string) {
+	c.apiKey = key
+}
+
+// Give the versions of a package or symbol (empty for the package itself), goos and goarch are optional.
+func (c *Client) Since(ctx context.Context, pkg string, symbol string, goos string, goarch string) (Entry, error) {
+	var entry Entry
+	err := c.get(ctx, "/api/since", url.Values{"pkg": {pkg}, "symbol": {symbol}, "goos": {goos}, "goarch": {goarch}}, &entry)
+	return entry, err
+}
+
+// Search the symbols named like query, sortOrder (name, package or version) is optional.
+func (c *Client) Search(ctx context.Context, query string, sortOrder string) ([]Entry, error) {
+	var entries []Entry
+	err := c.get(ctx, "/api/search", url.Values{"q": {query}, "sort": {sortOrder}}, &entries)
+	return entries, err
+}
+
+// List the api added or deprecated after from up to to, pattern and sortOrder are optional.
+func (c *Client) Diff(ctx context.Context, from string, to string, pattern string, sortOrder string) ([]Entry, error) {
+	var entries []Entry
+	err := c.get(ctx, "/api/diff", url.Values{"from": {from}, "to": {to}, "pkg": {pattern}, "sort": {sortOrder}}, &entries)
+	return entries, err
+}
+
+// List the content of the packages matching pattern (like "crypto/*"), sortOrder is optional.
+func (c *Client) List(ctx context.Context, pattern string, sortOrder string) ([]Entry, error) {
+	var entries []Entry
+	err := c.get(ctx, "/api/list", url.Values{"pkg": {pattern}, "sort": {sortOrder}}, &entries)
+	return entries, err
+}
+
+// Return the known go versions, in release order.
+func (c *Client) Versions(ctx context.Context) ([]string, error) {
+	var versions []string
+	err := c.get(ctx, "/api/versions", url.Values{}, &versions)
+	return versions, err
+}
+
+// Analyze packages on the server file system.
+func (c *Client) Analyze(ctx context.Context, req AnalyzeRequest) (AnalyzeResult, error) {
+	var result AnalyzeResult
+	body, err := json.Marshal(req)
+	if err != nil {
+		return result, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseUrl+"/api/analyze", bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	err = c.do(httpReq, &result)
+	return result, err
+}
+
+func (c *Client) get(ctx context.Context, path string, params url.Values, result any) error {
+	for key, values := range params {
+		if values[0] == "" {
+			delete(params, key)
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseUrl+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(httpReq, result)
+}
+
+func (c *Client) do(httpReq *http.Request, result any) error {
+	httpReq.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-Api-Key", c.apiKey)
+	}
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var output struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &output) != nil || output.Error == "" {
+			output.Error = strings.TrimSpace(string(data))
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: output.Error}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
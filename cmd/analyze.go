@@ -0,0 +1,363 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+const (
+	firstPatchedDirective = 21
+	watchDebounce         = 300 * time.Millisecond
+)
+
+var (
+	errNoGoDirective = errors.New("no go directive : go.mod is missing or incomplete")
+	errWatchFormat   = errors.New("watch failure : the changes are only printed in the text format")
+)
+
+func initAnalyze() *cobra.Command {
+	var options profileOptions
+	dryRun := false
+	fixGoMod := false
+	watch := false
+
+	cmd := &cobra.Command{
+		Use:     "analyze [packages]",
+		Aliases: []string{"a"},
+		Short:   "Show the minimum go version needed by the analyzed code.",
+		Long: `Show the minimum go version needed by the analyzed code and the uses requiring it.
+
+With a target version (--go or profile), the uses of api newer than the target are shown instead.
+Settings are read from the project profile (` + config.DefaultProfileName + ` by default), flags override them.
+Packages default to ./... relative to the profile directory.
+With --fix-go-mod, the go directive of go.mod is raised (never lowered) to the minimum required version.
+With --watch, the analysis is re-run on the modified packages matching the patterns after each save (on all of them
+when a go.mod changes) and only the changes are printed (in the text format).
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if options.printSchema(usagesSchema) {
+				return
+			}
+
+			profile, err := options.load(cmd.Flags())
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+			if watch && profile.Format != formatText {
+				printError(errWatchFormat)
+				os.Exit(exitError)
+			}
+
+			target := versiondb.NormalizeVersion(profile.Go)
+			usages, versionDatas, ok := runAnalysis(profile, args)
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			findings := analyzeFindings(usages, target)
+			if err = printUsages(findings, profile, newerRule, func(usage analysis.Usage) string {
+				return fmt.Sprint(usage.Name(), " ", tr(addedIn), " ", usage.AddedIn)
+			}); err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			if profile.Format == formatText {
+				printMinimum(usages, analysis.Configs(analysisConfig(profile, profile.Dir)))
+			}
+
+			if fixGoMod {
+				if err = fixModuleGoVersion(profile.Dir, usages, dryRun); err != nil {
+					printError(err)
+					os.Exit(exitError)
+				}
+			}
+
+			if watch {
+				if err = watchAnalysis(versionDatas, profile, args, target, usages); err != nil {
+					printError(err)
+					os.Exit(exitError)
+				}
+			}
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	options.addFlags(cmdFlags, true)
+	cmdFlags.BoolVarP(&dryRun, "dry-run", "n", false, "With --fix-go-mod, print the suggested edit instead of writing it")
+	cmdFlags.BoolVar(&fixGoMod, "fix-go-mod", false, "Raise the go directive of go.mod to the minimum required version")
+	cmdFlags.BoolVarP(&watch, "watch", "w", false, "Re-run the analysis on save and print the changes")
+
+	return cmd
+}
+
+// Return the uses newer than target, or the uses requiring the minimum version without target.
+func analyzeFindings(usages []analysis.Usage, target string) []analysis.Usage {
+	if target != "" {
+		return analysis.Newer(usages, target)
+	}
+	_, responsibles := analysis.Minimum(usages)
+	return responsibles
+}
+
+// Print the minimum version, followed by the one of each configuration when they differ.
+func printMinimum(usages []analysis.Usage, configs []string) {
+	minVersion, _ := analysis.Minimum(usages)
+	if minVersion == "" {
+		return
+	}
+	fmt.Println(tr(minimumRequired), minVersion)
+
+	if len(configs) < 2 {
+		return
+	}
+
+	configVersions := make([]string, 0, len(configs))
+	differ := false
+	for _, config := range configs {
+		configVersion, _ := analysis.Minimum(analysis.InConfig(usages, config))
+		configVersions = append(configVersions, configVersion)
+		differ = differ || configVersion != minVersion
+	}
+
+	if differ {
+		for index, config := range configs {
+			fmt.Println(" ", config, ":", configVersions[index])
+		}
+	}
+}
+
+// Raise the go directive of the go.mod in dir when usages need a more recent version.
+func fixModuleGoVersion(dir string, usages []analysis.Usage, dryRun bool) error {
+	minVersion, _ := analysis.Minimum(usages)
+	current := config.ModuleGoVersion(dir)
+	if current == "" {
+		return errNoGoDirective
+	}
+	if versiondb.CompareVersion(minVersion, current) <= 0 {
+		fmt.Println(tr(goModRequires), current)
+		return nil
+	}
+
+	directive := strings.TrimPrefix(minVersion, "go")
+	if versiondb.MinorVersion(minVersion) >= firstPatchedDirective {
+		directive += ".0" // since go1.21, "1.N" denotes the development version of the release
+	}
+
+	if dryRun {
+		fmt.Println(tr(goModDryRun), "go", current, "->", "go", directive)
+		return nil
+	}
+
+	if err := config.SetModuleGoVersion(dir, directive); err != nil {
+		return err
+	}
+	fmt.Println(tr(goModUpdated), "go", current, "->", "go", directive)
+	return nil
+}
+
+// Watch the profile directory, re-analyze the directories of the packages matching packages with modified go files
+// (every package when a go.mod is modified) with versionDatas and print the changes. The other directories
+// (like testdata, vendor or packages outside of the patterns) are ignored, the matching ones are listed again
+// when a go.mod or an unknown directory is modified (a new package can match).
+func watchAnalysis(versionDatas versiondb.VersionDatas, profile config.Profile, packages []string, target string, usages []analysis.Usage) error {
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	root, err := filepath.Abs(profile.Dir)
+	if err != nil {
+		return err
+	}
+	if err = addWatches(watcher, root); err != nil {
+		return err
+	}
+
+	usagesByDir := groupByDir(usages)
+	analysisConf := analysisConfig(profile, root)
+	packageDirs, err := analysis.PackageDirs(analysisConf, packages...)
+	if err != nil {
+		return err
+	}
+	previous := analyzeFindings(usages, target)
+	previousMin, _ := analysis.Minimum(usages)
+	pending := map[string]struct{}{}
+	modChanged := false
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	fmt.Printf(tr(watching)+"\n", root)
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addWatches(watcher, event.Name)
+				}
+			}
+			switch {
+			case strings.HasSuffix(event.Name, ".go"):
+				pending[filepath.Dir(event.Name)] = struct{}{}
+				timer.Reset(watchDebounce)
+			case filepath.Base(event.Name) == "go.mod": // a new go directive or dependency can change every package
+				modChanged = true
+				timer.Reset(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Println(err)
+		case <-timer.C:
+			if modChanged {
+				allUsages, err := analysis.Run(versionDatas, analysisConf, packages...)
+				if err == nil {
+					packageDirs, err = analysis.PackageDirs(analysisConf, packages...)
+				}
+				if err != nil {
+					fmt.Println(err)
+				} else {
+					usagesByDir = groupByDir(ignoreUsages(allUsages, profile.Ignore, profile.Dir))
+					clear(pending) // analyzed too
+				}
+				modChanged = false
+			}
+
+			listed := false
+			for dir := range pending {
+				if _, ok := packageDirs[dir]; !ok && !listed {
+					if dirs, err := analysis.PackageDirs(analysisConf, packages...); err == nil {
+						packageDirs = dirs
+					}
+					listed = true
+				}
+				if _, ok := packageDirs[dir]; !ok || !hasGoFiles(dir) {
+					delete(usagesByDir, dir)
+					continue
+				}
+
+				dirUsages, err := analysis.Run(versionDatas, analysisConf, dir)
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+				usagesByDir[dir] = ignoreUsages(dirUsages, profile.Ignore, profile.Dir)
+			}
+			clear(pending)
+
+			var current []analysis.Usage
+			for _, dirUsages := range usagesByDir {
+				current = append(current, dirUsages...)
+			}
+
+			findings := analyzeFindings(current, target)
+			printDelta(previous, findings)
+			if currentMin, _ := analysis.Minimum(current); currentMin != previousMin {
+				fmt.Println(tr(minimumNow), currentMin)
+				previousMin = currentMin
+			}
+			previous = findings
+		}
+	}
+}
+
+func groupByDir(usages []analysis.Usage) map[string][]analysis.Usage {
+	res := map[string][]analysis.Usage{}
+	for _, usage := range usages {
+		dir := filepath.Dir(usage.Position.Filename)
+		res[dir] = append(res[dir], usage)
+	}
+	return res
+}
+
+// Watch dir and its sub directories (except hidden ones, vendor and testdata).
+func addWatches(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return err
+		}
+
+		name := entry.Name()
+		if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+func hasGoFiles(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	return len(matches) != 0
+}
+
+func printDelta(previous []analysis.Usage, current []analysis.Usage) {
+	key := func(usage analysis.Usage) string {
+		return fmt.Sprintf("%s:%d:%d: %s %s %s", relativePath(usage.Position.Filename), usage.Position.Line, usage.Position.Column, usage.Name(), tr(addedIn), usage.AddedIn)
+	}
+
+	previousKeys := map[string]struct{}{}
+	for _, usage := range previous {
+		previousKeys[key(usage)] = struct{}{}
+	}
+
+	var lines []string
+	currentKeys := map[string]struct{}{}
+	for _, usage := range current {
+		usageKey := key(usage)
+		currentKeys[usageKey] = struct{}{}
+		if _, ok := previousKeys[usageKey]; !ok {
+			lines = append(lines, "+ "+usageKey)
+		}
+	}
+	for usageKey := range previousKeys {
+		if _, ok := currentKeys[usageKey]; !ok {
+			lines = append(lines, "- "+usageKey)
+		}
+	}
+
+	slices.Sort(lines)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
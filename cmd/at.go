@@ -0,0 +1,109 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/spf13/cobra"
+)
+
+var errPosition = errors.New("position failure : expected file:line:col")
+
+func initAt() *cobra.Command {
+	var tags []string
+
+	cmd := &cobra.Command{
+		Use:   "at file:line:col",
+		Short: "Show the introducing version of the standard library api at a source position.",
+		Long: `Show the introducing version of the standard library api at a source position (like main.go:42:17),
+the package of the file is type checked to resolve the identifier, which eases editor keybindings.
+
+Exit with status 1 when there is no standard library api at the position and 2 on other failures.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			file, line, column, err := parsePosition(args[0])
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			analysisConf := analysis.Config{Tags: tags, Verbose: conf.Verbose}
+			if targetOs != "" || targetArch != "" {
+				analysisConf.Platforms = []string{cmp.Or(targetOs, runtime.GOOS) + "/" + cmp.Or(targetArch, runtime.GOARCH)}
+			}
+
+			usage, err := analysis.At(versionDatas, analysisConf, file, line, column)
+			switch err {
+			case nil:
+			case analysis.ErrNoUsage:
+				fmt.Println(err)
+				os.Exit(exitNo)
+			default:
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			versions := [2]string{usage.AddedIn, usage.DeprecatedIn}
+			message := []any{usage.Name()}
+			message = append(message, sinceMessage(versionDatas, usage.Package, usage.Symbol, versions, nil)...)
+			message = append(message, supportMessage(versionDatas, usage.Package, usage.Symbol, usage.AddedIn)...)
+			fmt.Println(append(message, "-", versionDatas.DocUrl(usage.Package, usage.Symbol))...)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Build tags")
+
+	return cmd
+}
+
+// Split a position like "main.go:42:17" (the file name can contain colons).
+func parsePosition(position string) (string, int, int, error) {
+	indexColumn := strings.LastIndexByte(position, ':')
+	if indexColumn == -1 {
+		return "", 0, 0, errPosition
+	}
+	indexLine := strings.LastIndexByte(position[:indexColumn], ':')
+	if indexLine == -1 {
+		return "", 0, 0, errPosition
+	}
+
+	line, err := strconv.Atoi(position[indexLine+1 : indexColumn])
+	if err != nil {
+		return "", 0, 0, errPosition
+	}
+	column, err := strconv.Atoi(position[indexColumn+1:])
+	if err != nil {
+		return "", 0, 0, errPosition
+	}
+	return position[:indexLine], line, column, nil
+}
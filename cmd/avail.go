@@ -0,0 +1,83 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exitNo    = 1
+	exitError = 2
+)
+
+// Return the exit status for err : exitNo when the package or symbol is unknown, exitError on other failures.
+func exitStatus(err error) int {
+	if errors.Is(err, versiondb.ErrUnknownPackage) || errors.Is(err, versiondb.ErrUnknownSymbol) {
+		return exitNo
+	}
+	return exitError
+}
+
+func initAvail() *cobra.Command {
+	return &cobra.Command{
+		Use:   "avail version expr1 [expr2]",
+		Short: "Answer yes or no whether a package or symbol is available in a go version.",
+		Long: `Answer yes or no whether a package or symbol is available in a go version.
+
+Exit with status 0 for yes, 1 for no (including unknown package or symbol) and 2 on other failures.
+`,
+		Args: cobra.RangeArgs(2, 3),
+		Run: func(_ *cobra.Command, args []string) {
+			target := versiondb.NormalizeVersion(args[0])
+			if target == "" {
+				printError(versiondb.ErrUnknownVersion)
+				os.Exit(exitError)
+			}
+
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			pkg, symbol := splitQuery(versionDatas, args[1:])
+			symbolData, err := versionDatas.SinceOn(pkg, symbol, targetOs, targetArch)
+			switch err {
+			case nil:
+				if versiondb.CompareVersion(symbolData[0], target) <= 0 {
+					fmt.Println(tr(answerYes))
+					return
+				}
+			case versiondb.ErrUnavailablePlatform, versiondb.ErrUnknownPackage, versiondb.ErrUnknownSymbol:
+				printError(err)
+			default:
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			fmt.Println(tr(answerNo))
+			os.Exit(exitNo)
+		},
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+type blockerOutput struct {
+	Package string         `json:"package"`
+	AddedIn string         `json:"added_in"`
+	Symbols []symbolOutput `json:"symbols"`
+}
+
+type symbolOutput struct {
+	Name      string   `json:"name"`
+	AddedIn   string   `json:"added_in"`
+	Positions []string `json:"positions"`
+}
+
+func initBlockers() *cobra.Command {
+	var options profileOptions
+
+	cmd := &cobra.Command{
+		Use:   "blockers version [packages]",
+		Short: "List the api preventing the analyzed code to build with an older go version.",
+		Long: `List the api preventing the analyzed code to build with an older go version, grouped by standard library package.
+
+Settings are read from the project profile (` + config.DefaultProfileName + ` by default), flags override them.
+Packages default to ./... relative to the profile directory.
+Exit with status 1 when a blocker is found and 2 on other failures.
+`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if options.schema {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if options.printSchema(blockersSchema) {
+				return
+			}
+
+			target := versiondb.NormalizeVersion(args[0])
+			if target == "" {
+				printError(versiondb.ErrUnknownVersion)
+				os.Exit(exitError)
+			}
+
+			profile, err := options.load(cmd.Flags())
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			usages, _, ok := runAnalysis(profile, args[1:])
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			usages = analysis.Newer(usages, target)
+			switch profile.Format {
+			case formatJson:
+				err = printJson(blockersOutput{SchemaVersion: jsonSchemaVersion, Blockers: groupBlockers(usages)})
+			case formatText:
+				printBlockers(groupBlockers(usages), target)
+			default:
+				err = printUsages(usages, profile, newerRule, func(usage analysis.Usage) string {
+					return fmt.Sprint(usage.Name(), " ", tr(addedIn), " ", usage.AddedIn, tr(newerThan), " ", target)
+				})
+			}
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			if len(usages) != 0 {
+				os.Exit(exitNo)
+			}
+		},
+	}
+
+	options.addFlags(cmd.Flags(), false)
+
+	return cmd
+}
+
+// Group usages by standard library package then by symbol, packages needing the most recent version come first.
+func groupBlockers(usages []analysis.Usage) []blockerOutput {
+	byPackage := map[string]map[string]*symbolOutput{}
+	for _, usage := range usages {
+		symbols := byPackage[usage.Package]
+		if symbols == nil {
+			symbols = map[string]*symbolOutput{}
+			byPackage[usage.Package] = symbols
+		}
+
+		name := usage.Name()
+		symbol := symbols[name]
+		if symbol == nil {
+			symbol = &symbolOutput{Name: name, AddedIn: usage.AddedIn, Positions: []string{}}
+			symbols[name] = symbol
+		}
+		position := fmt.Sprintf("%s:%d:%d", relativePath(usage.Position.Filename), usage.Position.Line, usage.Position.Column)
+		symbol.Positions = append(symbol.Positions, position)
+	}
+
+	blockers := make([]blockerOutput, 0, len(byPackage))
+	for pkg, symbols := range byPackage {
+		blocker := blockerOutput{Package: pkg}
+		for _, symbol := range symbols {
+			blocker.Symbols = append(blocker.Symbols, *symbol)
+			if versiondb.CompareVersion(symbol.AddedIn, blocker.AddedIn) > 0 {
+				blocker.AddedIn = symbol.AddedIn
+			}
+		}
+		slices.SortFunc(blocker.Symbols, func(a symbolOutput, b symbolOutput) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		blockers = append(blockers, blocker)
+	}
+
+	slices.SortFunc(blockers, func(a blockerOutput, b blockerOutput) int {
+		if res := versiondb.CompareVersion(b.AddedIn, a.AddedIn); res != 0 {
+			return res
+		}
+		return strings.Compare(a.Package, b.Package)
+	})
+	return blockers
+}
+
+func printBlockers(blockers []blockerOutput, target string) {
+	if len(blockers) == 0 {
+		fmt.Println(tr(nothingBlocks), target)
+		return
+	}
+
+	for _, blocker := range blockers {
+		fmt.Println(blocker.Package, tr(needs), blocker.AddedIn+")")
+		for _, symbol := range blocker.Symbols {
+			fmt.Println(" ", symbol.Name, tr(addedIn), symbol.AddedIn)
+			for _, position := range symbol.Positions {
+				fmt.Println("   ", position)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+func initCache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache of api files.",
+	}
+
+	cmd.AddCommand(initCacheVerify())
+
+	return cmd
+}
+
+func initCacheVerify() *cobra.Command {
+	dryRun := false
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check the cached api files and download again the corrupted ones.",
+		Long: `Check each cached api file against the checksum recorded when it was downloaded
+and check that all its lines parse (a truncated download fails it), the faulty files are downloaded again.
+
+Exit with status 1 when a problem remains (always the case with --dry-run) and 2 on other failures.
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			if confErr != nil {
+				fmt.Println(confErr)
+				os.Exit(exitError)
+			}
+
+			checked, problems, err := versiondb.VerifyCache(conf, !dryRun)
+			remaining := false
+			for _, problem := range problems {
+				if problem.Repaired {
+					fmt.Println(problem.File, ":", problem.Err, "- repaired")
+				} else {
+					fmt.Println(problem.File, ":", problem.Err)
+					remaining = true
+				}
+			}
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			fmt.Println(checked, "api files checked,", len(problems), "problems found")
+			if remaining {
+				os.Exit(exitNo)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Report the problems without downloading the files again")
+
+	return cmd
+}
@@ -0,0 +1,278 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	formatJson     = "json"
+	formatQuickfix = "quickfix"
+	formatSarif    = "sarif"
+	formatText     = "text"
+)
+
+var (
+	errNoTarget      = errors.New("no target go version : set it in profile, with --go or in go.mod")
+	errUnknownFormat = errors.New("unknown output format")
+)
+
+type usageOutput struct {
+	File         string   `json:"file"`
+	Line         int      `json:"line"`
+	Column       int      `json:"column"`
+	Package      string   `json:"package"`
+	Symbol       string   `json:"symbol,omitempty"`
+	AddedIn      string   `json:"added_in"`
+	DeprecatedIn string   `json:"deprecated_in,omitempty"`
+	Replacement  string   `json:"replacement,omitempty"`
+	Configs      []string `json:"configs,omitempty"`
+}
+
+func initCheck() *cobra.Command {
+	var options profileOptions
+
+	cmd := &cobra.Command{
+		Use:   "check [packages]",
+		Short: "Check that the analyzed code does not use api newer than the target go version.",
+		Long: `Check that the analyzed code does not use api newer than the target go version.
+
+Settings are read from the project profile (` + config.DefaultProfileName + ` by default), flags override them.
+Packages default to ./... relative to the profile directory.
+Exit with status 1 when a problem is found and 2 on other failures.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if options.printSchema(usagesSchema) {
+				return
+			}
+
+			profile, err := options.load(cmd.Flags())
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			target := profile.Go
+			if target == "" {
+				target = config.ModuleGoVersion(profile.Dir)
+			}
+			if target = versiondb.NormalizeVersion(target); target == "" {
+				printError(errNoTarget)
+				os.Exit(exitError)
+			}
+
+			usages, _, ok := runAnalysis(profile, args)
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			usages = analysis.Newer(usages, target)
+			if err = printUsages(usages, profile, newerRule, func(usage analysis.Usage) string {
+				return fmt.Sprint(usage.Name(), " ", tr(addedIn), " ", usage.AddedIn, tr(newerThanTarget), " ", target)
+			}); err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			if len(usages) != 0 {
+				os.Exit(exitNo)
+			}
+		},
+	}
+
+	options.addFlags(cmd.Flags(), true)
+
+	return cmd
+}
+
+type profileOptions struct {
+	path     string
+	override config.Profile
+	schema   bool
+}
+
+func (options *profileOptions) addFlags(cmdFlags *pflag.FlagSet, withTarget bool) {
+	cmdFlags.StringVar(&options.path, "profile", config.DefaultProfileName, "Path to the project profile")
+	if withTarget {
+		cmdFlags.StringVar(&options.override.Go, "go", "", "Target go version")
+	}
+	cmdFlags.StringSliceVar(&options.override.Platforms, "platform", nil, "GOOS/GOARCH pairs to analyze")
+	cmdFlags.StringSliceVar(&options.override.Tags, "tags", nil, "Build tags")
+	cmdFlags.StringArrayVar(&options.override.TagSets, "tag-set", nil, "Comma separated build tags analyzed as a separate configuration (repeatable)")
+	cmdFlags.StringSliceVar(&options.override.Ignore, "ignore", nil, "Patterns of symbols (pkg.Symbol) or files to ignore")
+	cmdFlags.StringVarP(&options.override.Format, "format", "f", formatText, "Output format (text, json, quickfix or sarif)")
+	cmdFlags.BoolVar(&options.schema, "schema", false, "Print the JSON Schema of the json output and exit")
+}
+
+// Read the profile and apply the flags explicitly set.
+func (options *profileOptions) load(cmdFlags *pflag.FlagSet) (config.Profile, error) {
+	if cmdFlags.Changed("format") && options.override.Format == formatJson {
+		jsonOutput = true // failures are printed as json objects too
+	}
+
+	profile, err := config.LoadProfile(options.path)
+	if err != nil {
+		return profile, err
+	}
+
+	override := options.override
+	if cmdFlags.Changed("go") {
+		profile.Go = override.Go
+	}
+	if cmdFlags.Changed("platform") {
+		profile.Platforms = override.Platforms
+	}
+	if cmdFlags.Changed("tags") {
+		profile.Tags = override.Tags
+	}
+	if cmdFlags.Changed("tag-set") {
+		profile.TagSets = override.TagSets
+	}
+	if cmdFlags.Changed("ignore") {
+		profile.Ignore = append(profile.Ignore, override.Ignore...)
+	}
+	if cmdFlags.Changed("format") || profile.Format == "" {
+		profile.Format = override.Format
+	}
+	jsonOutput = jsonOutput || profile.Format == formatJson
+	return profile, nil
+}
+
+// Analyze packages (default to ./...) with the profile settings, ignored usages are already removed.
+// The database used (with the profile replacements) is returned too, to re-run the analysis without reloading it.
+// Print the encountered error and return false on failure.
+func runAnalysis(profile config.Profile, packages []string) ([]analysis.Usage, versiondb.VersionDatas, bool) {
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+
+	versionDatas, ok := loadDatas()
+	if !ok {
+		return nil, versiondb.VersionDatas{}, false
+	}
+	versionDatas = versionDatas.WithReplacements(profile.Replacements)
+
+	usages, err := analysis.Run(versionDatas, analysisConfig(profile, profile.Dir), packages...)
+	if err != nil {
+		printError(err)
+		return nil, versiondb.VersionDatas{}, false
+	}
+	return ignoreUsages(usages, profile.Ignore, profile.Dir), versionDatas, true
+}
+
+func analysisConfig(profile config.Profile, dir string) analysis.Config {
+	return analysis.Config{Dir: dir, Platforms: profile.Platforms, Tags: profile.Tags, TagSets: profile.TagSets, Verbose: conf.Verbose}
+}
+
+// Remove usages whose name or file (relative to dir) match one of the patterns.
+func ignoreUsages(usages []analysis.Usage, patterns []string, dir string) []analysis.Usage {
+	if len(patterns) == 0 {
+		return usages
+	}
+
+	absDir, _ := filepath.Abs(dir)
+	res := usages[:0]
+	for _, usage := range usages {
+		fileName := usage.Position.Filename
+		if relName, err := filepath.Rel(absDir, fileName); err == nil {
+			fileName = filepath.ToSlash(relName)
+		}
+
+		if !matchAny(patterns, usage.Name(), fileName) {
+			res = append(res, usage)
+		}
+	}
+	return res
+}
+
+func matchAny(patterns []string, names ...string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func printUsages(usages []analysis.Usage, profile config.Profile, rule reportRule, message func(analysis.Usage) string) error {
+	switch profile.Format {
+	case formatSarif:
+		return printJson(buildSarif(usages, sourceRoot(profile.Dir), rule, message))
+	case formatJson:
+		outputs := make([]usageOutput, 0, len(usages))
+		for _, usage := range usages {
+			outputs = append(outputs, usageOutput{
+				File: relativePath(usage.Position.Filename), Line: usage.Position.Line, Column: usage.Position.Column,
+				Package: usage.Package, Symbol: usage.Symbol, AddedIn: usage.AddedIn, DeprecatedIn: usage.DeprecatedIn,
+				Replacement: usage.Replacement, Configs: usage.Configs,
+			})
+		}
+
+		return printJson(usagesOutput{SchemaVersion: jsonSchemaVersion, Usages: outputs})
+	case formatText, formatQuickfix:
+		for _, usage := range usages {
+			fmt.Printf("%s:%d:%d: %s%s\n", relativePath(usage.Position.Filename), usage.Position.Line, usage.Position.Column, message(usage), configsMessage(usage))
+		}
+		return nil
+	}
+	return errUnknownFormat
+}
+
+func configsMessage(usage analysis.Usage) string {
+	if len(usage.Configs) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(usage.Configs, ", ") + " " + tr(onlyOn) + ")"
+}
+
+func printJson(value any) error {
+	return fprintJson(os.Stdout, value)
+}
+
+func fprintJson(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// Return fileName relative to the working directory when possible.
+func relativePath(fileName string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if relName, err := filepath.Rel(wd, fileName); err == nil {
+			return relName
+		}
+	}
+	return fileName
+}
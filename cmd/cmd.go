@@ -19,28 +19,135 @@
 package cmd
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/dvaumoron/gosince/config"
 	"github.com/dvaumoron/gosince/versiondb"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 const (
-	addedIn      = "added in"
-	deprecatedIn = "and deprecated in"
-	found        = "found"
+	defaultSnapshotFile = "gosince.lock"
+	defaultSuggestions  = 10
+	snapshotRead        = "read"
+	snapshotWrite       = "write"
+
+	// english messages, see tr
+	addedIn           = "added in"
+	additionsTitle    = "Additions by area :"
+	aliasOf           = "- alias of"
+	aliasSince        = "since"
+	allPlatforms      = "all"
+	allSupported      = "present in all supported Go releases"
+	andEarlier        = "and earlier"
+	andMore           = " and %d more"
+	answerNo          = "no"
+	answerYes         = "yes"
+	backportedTo      = "backported to"
+	bothSince         = "both are available since %s"
+	butDeprecated     = " but deprecated in %s"
+	constPrefix       = "- const"
+	deprecatedIn      = "and deprecated in"
+	deprecatedTitle   = "deprecated in"
+	deprecationsTitle = "Deprecations :"
+	embeddedField     = "- embedded field"
+	fieldOfType       = "- field of type"
+	found             = "found"
+	goModDryRun       = "go.mod :"
+	goModRequires     = "go.mod already requires go"
+	goModUpdated      = "go.mod updated :"
+	itself            = "(itself"
+	languageTitle     = "Language :"
+	localToolchain    = "your toolchain is %s, not available"
+	minimumNow        = "minimum required version is now"
+	minimumRequired   = "minimum required version is"
+	missingBefore     = "not available before"
+	missingIn         = "not available in"
+	missingReleases   = "missing in"
+	missingSupported  = "not available in supported"
+	moreHidden        = "and %d more (shown with a greater --suggestions)"
+	needs             = "(needs"
+	newPackagesTitle  = "New packages :"
+	newerThan         = ", newer than"
+	newerThanTarget   = ", newer than target"
+	noReplacement     = "- without replacement"
+	notesMissing      = "release notes do not mention it -"
+	notesTitle        = "Release notes :"
+	nothingBlocks     = "nothing blocks"
+	olderSince        = "%s is older, available since %s (%s for %s)"
+	onlyAvailable     = "%s is the only one available"
+	onlyOn            = "only"
+	otherAdditions    = "constants, variables or fields (%d)"
+	platformDependent = "value depending on the platform"
+	platformsTitle    = "platforms"
+	releaseNotes      = "release notes :"
+	replacementTitle  = "replacement"
+	requiredBy        = "required by"
+	seeAlso           = "- see"
+	severalFound      = "Several possibilities found :"
+	similarSettings   = "Similar settings :"
+	supportedSince    = ": supported since"
+	supportedTitle    = "supported releases"
+	symbolCount       = "(%d symbols)"
+	untypedConst      = "untyped"
+	watching          = "Watching %s for changes..."
 )
 
-var conf config.Config
+var (
+	errSnapshotMode = errors.New("snapshot failure : mode must be " + snapshotRead + " or " + snapshotWrite)
+	errTextOnlyFlag = errors.New("format failure : flag only printed in the text format")
+
+	// flags adding details to a text answer, not to the json or quickfix ones
+	textOnlyFlags = []string{"debug-parse", "example", "explain", "go-doc", "notes", "open", "signature", "target", "with-doc"}
+)
+
+var (
+	conf    config.Config
+	confErr error
+
+	backportDatas versiondb.BackportDatas // api backported to point releases, loaded with the api files
+
+	snapshotFile string
+	snapshotMode string
+	targetArch   string
+	targetOs     string
+	toolVersion  string
+)
 
 func Init(version string) *cobra.Command {
-	envRepoPath, envSourceUrl, err := config.InitDefault("GOSINCE_CACHE_PATH", "GOSINCE_SOURCE_URL")
+	toolVersion = version
+	versiondb.SetUserAgent(version)
+	var envRepoPath, envSourceUrl string
+	envRepoPath, envSourceUrl, confErr = config.InitDefault(config.EnvCachePath, config.EnvSourceUrl)
+	envArchive, _ := strconv.ParseBool(os.Getenv(config.EnvCacheArchive))
 
-	callGoDoc := false
+	docBackend := ""
+	openDoc := false
+	withDoc := false
+	withExample := false
+	withNotes := false
+	explain := false
+	signature := false
+	caseSensitive := false
+	exact := false
+	suggestions := defaultSuggestions
+	debugParse := false
+	noToolchainCheck := false
+	sortOrder := versiondb.SortVersion
+	lookupFormat := formatText
+	goRef := ""
+	target := ""
+	quiet := false
+	schema := false
+	lang := os.Getenv(config.EnvLang)
+	downloadRate := float64(versiondb.DefaultDownloadRate)
 
 	cmd := &cobra.Command{
 		Use:   "gosince expr1 [expr2]",
@@ -52,117 +159,478 @@ gosince <pkg>
 gosince <sym>
 gosince <pkg>.<sym>[.<methodOrField>]
 gosince <pkg> <sym>[.<methodOrField>]
+gosince '(*<pkg>.<Type>).<method>'
 `,
 		Version: version,
-		Args:    cobra.RangeArgs(1, 2),
-		Run: func(_ *cobra.Command, args []string) {
-			if err != nil {
-				fmt.Println(err)
+		Args: func(cmd *cobra.Command, args []string) error {
+			if schema {
+				return nil
+			}
+			return cobra.RangeArgs(1, 2)(cmd, args)
+		},
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			if err := setLanguage(lang); err != nil {
+				confErr = err
 				return
 			}
 
-			if conf.Verbose {
-				fmt.Println("Use the repository", conf.RepoPath, "as local cache")
-				fmt.Println("Use the url", conf.SourceUrl, "as base to download api information")
+			versiondb.SetDownloadRate(downloadRate)
+			conf.NotesUrl = os.Getenv(config.EnvNotesUrl)
+			conf.TargetUrl = os.Getenv(config.EnvTargetUrl)
+			if !quiet {
+				conf.Progress = newProgressPrinter()
 			}
-
-			pkg, symbol := args[0], ""
-			if len(args) == 1 {
-				if index := strings.IndexByte(pkg, '.'); index != -1 {
-					pkg, symbol = pkg[:index], pkg[index+1:]
-				}
-			} else {
-				symbol = args[1]
+			if goRef != "" && confErr == nil {
+				conf, confErr = conf.WithRef(goRef)
+			}
+			if confErr != nil {
+				return
 			}
 
-			versionDatas, err := versiondb.LoadDatas(conf)
-			if err != nil {
-				fmt.Println(err)
+			switch snapshotMode {
+			case "", snapshotWrite:
+			case snapshotRead:
+				conf.Snapshot, confErr = versiondb.ReadSnapshot(snapshotFile)
+			default:
+				confErr = errSnapshotMode
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if schema {
+				fmt.Println(lookupSchema)
 				return
 			}
 
-			pkg = strings.ToLower(pkg)
-			symbol = strings.ToLower(symbol)
-			symbolData, err := versionDatas.Since(pkg, symbol)
-			if err != nil {
-				query := ""
-				switch err {
-				case versiondb.ErrUnknownPackage:
-					if symbol == "" {
-						indexSlash := strings.IndexByte(pkg, '/')
-						query = pkg[indexSlash+1:] // no error when indexSlash is -1
-						break
+			switch lookupFormat {
+			case formatJson:
+				jsonOutput = true
+			case formatQuickfix, formatText:
+			default:
+				printError(errUnknownFormat)
+				os.Exit(exitError)
+			}
+
+			options := lookupOptions{
+				Format: lookupFormat, SortOrder: sortOrder, Suggestions: suggestions,
+				CaseSensitive: caseSensitive, Exact: exact, Goos: targetOs, Goarch: targetArch,
+			}
+			if jsonOutput {
+				options.Format = formatJson
+			}
+			if options.Format != formatText {
+				for _, name := range textOnlyFlags {
+					if cmd.Flags().Changed(name) {
+						printError(fmt.Errorf("%w : --%s", errTextOnlyFlag, name))
+						os.Exit(exitError)
 					}
-					fallthrough
-				case versiondb.ErrUnknownSymbol:
-					indexDot := strings.IndexByte(symbol, '.')
-					query = symbol[indexDot+1:] // no error when indexDot is -1
-				default:
-					fmt.Println(err)
-					return
 				}
+			}
 
-				results := versionDatas.Search(query)
-				switch len(results) {
-				case 0:
-					fmt.Println(err)
-					return
-				case 1:
-					result := results[0]
-					if result[2] == "" {
-						fmt.Println(found, result[0], addedIn, result[1])
-					} else {
-						fmt.Println(found, result[0], addedIn, result[1], deprecatedIn, result[2])
-					}
+			// the replacements of the project profile are advisory, a malformed one does not prevent the lookup
+			var replacements map[string]string
+			if profile, err := config.LoadProfile(config.DefaultProfileName); err == nil {
+				replacements = profile.Replacements
+			} else {
+				fmt.Fprintln(os.Stderr, "Ignore", config.DefaultProfileName, ":", err)
+			}
 
-					if callGoDoc {
-						splitted := strings.Split(result[0], " ")
-						if err = runGoDoc(splitted...); err != nil {
-							fmt.Println(err)
-							return
-						}
-					}
-				default:
-					fmt.Println("Several possibilities found :")
-					for _, result := range results {
-						if result[2] == "" {
-							fmt.Println(result[0], addedIn, result[1])
-						} else {
-							fmt.Println(result[0], addedIn, result[1], deprecatedIn, result[2])
-						}
-					}
+			if answer, ok := askDaemon(cmd.Flags(), args, lang, options, replacements); ok {
+				if answer.AddedIn != "" && !noToolchainCheck {
+					checkToolchain(answer.AddedIn)
+				}
+				if answer.Status != 0 {
+					os.Exit(answer.Status)
 				}
 				return
 			}
 
-			if symbolData[1] == "" {
-				fmt.Println(addedIn, symbolData[0])
-			} else {
-				fmt.Println(addedIn, symbolData[0], deprecatedIn, symbolData[1])
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+			versionDatas = versionDatas.WithReplacements(replacements)
+
+			answer := printLookup(os.Stdout, versionDatas, args, options)
+			pkg, symbol := answer.Package, answer.Symbol
+			if answer.NotFound && debugParse {
+				printDebugParse(versionDatas, pkg, symbol)
+			}
+			if answer.AddedIn == "" {
+				if answer.Status != 0 {
+					os.Exit(answer.Status)
+				}
+				return
+			}
+
+			if signature {
+				printDeclaration(versionDatas, pkg, symbol)
+			}
+			if explain {
+				printExplain(versionDatas, pkg, symbol)
+			}
+			if debugParse {
+				printDebugParse(versionDatas, pkg, symbol)
+			}
+			if withDoc {
+				printDocExcerpt(pkg, symbol)
+			}
+			if withExample {
+				printExamples(pkg, symbol)
+			}
+			if withNotes {
+				printReleaseNotes(versionDatas, pkg, symbol)
+			}
+			targetStatus := 0
+			if target != "" {
+				targetStatus = printTarget(target, pkg, versionDatas.SymbolName(pkg, symbol), answer.AddedIn)
+			}
+			if !noToolchainCheck {
+				checkToolchain(answer.AddedIn)
 			}
 
-			if callGoDoc {
-				if err = runGoDoc(args...); err != nil {
+			docUrl := versionDatas.DocUrl(pkg, symbol)
+			if openDoc {
+				if err := openBrowser(docUrl); err != nil {
 					fmt.Println(err)
+					return
 				}
 			}
+
+			if docBackend != "" {
+				if err := printDoc(docBackend, pkg, versionDatas.SymbolName(pkg, symbol), docUrl); err != nil {
+					fmt.Println(err)
+				}
+			}
+			if targetStatus != 0 {
+				os.Exit(targetStatus)
+			}
 		},
 	}
 
 	cmdFlags := cmd.Flags()
-	cmdFlags.StringVarP(&conf.RepoPath, "cache-path", "p", envRepoPath, "Local path to cache the retrieved api information")
-	cmdFlags.BoolVarP(&callGoDoc, "go-doc", "d", false, "Call go doc command")
-	cmdFlags.StringVarP(&conf.SourceUrl, "source-addr", "a", envSourceUrl, "Location of Go source")
-	cmdFlags.BoolVarP(&conf.Verbose, "verbose", "v", false, "Verbose output")
+	cmdFlags.StringVarP(&docBackend, "go-doc", "d", "", "Show the documentation with a backend (builtin, go, pkgsite or a command template)")
+	cmdFlags.Lookup("go-doc").NoOptDefVal = cmp.Or(os.Getenv(config.EnvDocBackend), docBuiltin)
+	cmdFlags.BoolVar(&caseSensitive, "case-sensitive", false, "Match the case of symbols (ignored by default)")
+	cmdFlags.BoolVar(&debugParse, "debug-parse", false, "Print how the api file lines matching the query are parsed")
+	cmdFlags.BoolVar(&exact, "exact", false, "Do not search similar names when the query is not found (exit with status 1)")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "Print the answer and the failures as json objects (with a stable error code)")
+	cmdFlags.StringVarP(&lookupFormat, "format", "f", formatText, "Output format (text, json or quickfix)")
+	cmdFlags.BoolVar(&withExample, "example", false, "Print the examples of the standard library for the package or symbol (from its test files)")
+	cmdFlags.BoolVar(&withNotes, "notes", false, "Print the paragraphs of the release notes about the answer, for its introducing and deprecating versions")
+	cmdFlags.BoolVar(&explain, "explain", false, "Print the api file lines which produced the answer")
+	cmdFlags.BoolVar(&openDoc, "open", false, "Open the pkg.go.dev documentation in the browser")
+	cmdFlags.IntVar(&suggestions, "suggestions", defaultSuggestions, "Maximum number of similar names listed when the query is not found (0 for none, negative for all)")
+	cmdFlags.BoolVar(&schema, "schema", false, "Print the JSON Schema of the json output and exit")
+	cmdFlags.StringVar(&target, "target", "", "Print the release of another Go implementation (like tinygo) supporting the answer since (exit with status 1 when unsupported)")
+	cmdFlags.BoolVar(&signature, "signature", false, "Print the declaration of the symbol, with its type parameters and constraints")
+	cmdFlags.BoolVar(&withDoc, "with-doc", false, "Print the first paragraph of the documentation under the answer")
+	cmdFlags.BoolVar(&noToolchainCheck, "no-toolchain-check", false, "Do not compare with the local Go toolchain")
+	addSortFlag(cmdFlags, &sortOrder)
+
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.BoolVar(&conf.Archive, "cache-archive", envArchive, "Cache the api files in a single zstd-compressed archive")
+	persistentFlags.StringVarP(&conf.RepoPath, "cache-path", "p", envRepoPath, "Local path to cache the retrieved api information")
+	persistentFlags.StringVarP(&conf.SourceUrl, "source-addr", "a", envSourceUrl, "Location of Go source")
+	persistentFlags.Float64Var(&downloadRate, "download-rate", versiondb.DefaultDownloadRate, "Maximum number of download requests per second (0 for no limit)")
+	persistentFlags.DurationVar(&conf.MaxAge, "max-age", config.InitMaxAge(config.EnvCacheMaxAge), "Age after which the cached api files are downloaded again (0 to keep them forever)")
+	persistentFlags.BoolVar(&conf.Offline, "offline", false, "Only use the cached api files, without any download")
+	persistentFlags.StringVar(&conf.GitUrl, "git-source", os.Getenv(config.EnvGitUrl), "Git repository of Go to read the api files from with a shallow clone, instead of downloading (like git@github.com:golang/go.git)")
+	persistentFlags.StringVar(&goRef, "go-ref", "", "Read the api files at this Go git ref (tag, branch or commit) instead of master")
+	persistentFlags.StringVar(&lang, "lang", lang, "Language of the messages (en or fr)")
+	persistentFlags.BoolVarP(&quiet, "quiet", "q", false, "Do not report the download progress")
+	persistentFlags.StringVar(&snapshotMode, "snapshot", "", "Pin the api files with a lockfile ("+snapshotWrite+" it from the loaded files or "+snapshotRead+" it to use exactly those)")
+	persistentFlags.StringVar(&snapshotFile, "snapshot-file", defaultSnapshotFile, "Path of the lockfile used by --snapshot")
+	persistentFlags.BoolVar(&conf.Docs, "summaries", false, "Attach the first sentence of the doc comments to the answers (downloads the sources of their packages)")
+	persistentFlags.BoolVar(&conf.Strict, "strict", false, "Fail on malformed api file lines instead of skipping them")
+	persistentFlags.BoolVarP(&conf.Verbose, "verbose", "v", false, "Verbose output")
+	persistentFlags.StringVar(&targetArch, "goarch", "", "Restrict answers to the platforms with this GOARCH")
+	persistentFlags.StringVar(&targetOs, "goos", "", "Restrict answers to the platforms with this GOOS")
+
+	cmd.AddCommand(initAnalyze(), initAt(), initAvail(), initBlockers(), initCache(), initCheck(), initCompare(), initDaemon(), initDeprecated(), initDiff(), initExport(), initFeature(), initGen(), initGodebug(), initList(), initLsp(), initMcp(), initMin(), initQuick(cmd), initSearch(), initSelfUpdate(), initServe(), initSnippet(), initStdio(), initValidate(), initVet(), initWarm(), initWhatsnew())
+	registerCompletions(cmd)
 
 	return cmd
 }
 
-func runGoDoc(cmdArgs ...string) error {
-	cmdArgs = append([]string{"doc"}, cmdArgs...)
-	cmd := exec.Command("go", cmdArgs...)
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+// Print the encountered error and return false on failure.
+func loadDatas() (versiondb.VersionDatas, bool) {
+	if confErr != nil {
+		printError(confErr)
+		return versiondb.VersionDatas{}, false
+	}
+
+	if conf.Verbose {
+		fmt.Println("Use the repository", conf.RepoPath, "as local cache")
+		if conf.GitUrl == "" {
+			fmt.Println("Use the url", conf.SourceUrl, "as base to download api information")
+		} else {
+			fmt.Println("Use the git repository", conf.GitUrl, "to clone api information")
+		}
+	}
+
+	versionDatas, backports, docRef, err := loadAllDatas(conf)
+	if err != nil {
+		printError(err)
+		return versiondb.VersionDatas{}, false
+	}
+	backportDatas, conf.DocRef = backports, docRef
+
+	if snapshotMode == snapshotWrite {
+		if err = versiondb.WriteSnapshot(snapshotFile, versionDatas.Snapshot()); err != nil {
+			printError(err)
+			return versiondb.VersionDatas{}, false
+		}
+	}
+
+	// on stderr to keep outputs (like json or lsp protocol) valid
+	if diagnostics := versionDatas.Diagnostics(); len(diagnostics) != 0 {
+		if conf.Verbose {
+			for _, diagnostic := range diagnostics {
+				fmt.Fprintln(os.Stderr, diagnostic)
+			}
+		}
+		fmt.Fprintln(os.Stderr, len(diagnostics), "malformed api lines skipped (listed with --verbose, --strict to fail)")
+	}
+	return versionDatas, true
+}
+
+// Load the api files and the backports with c, also return the ref of the documentation (following the latest release),
+// used again by the refresh of the daemon.
+func loadAllDatas(c config.Config) (versiondb.VersionDatas, versiondb.BackportDatas, string, error) {
+	c.DocRef = "" // not the one of a previous load
+	versionDatas, err := versiondb.LoadDatas(c)
+	if err != nil {
+		return versiondb.VersionDatas{}, versiondb.BackportDatas{}, "", err
+	}
+
+	backports, err := versiondb.LoadBackports(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ignore the backports :", err) // on stderr like the diagnostics
+	}
+	return versionDatas, backports, versionDatas.ReleaseTag(), nil
+}
+
+func addSortFlag(cmdFlags *pflag.FlagSet, sortOrder *string) {
+	cmdFlags.StringVarP(sortOrder, "sort", "s", versiondb.SortVersion, "Order of listed results (name, package or version)")
+}
+
+// Exit with exitError when the value of the --sort flag is unknown, to check before loading the database.
+func checkSortOrder(sortOrder string) {
+	switch sortOrder {
+	case versiondb.SortName, versiondb.SortPackage, versiondb.SortVersion:
+	default:
+		printError(versiondb.ErrUnknownSort)
+		os.Exit(exitError)
+	}
+}
+
+// Return the lowercased package and the symbol from command arguments ("pkg.Symbol" or "pkg" "Symbol"),
+// method expressions are accepted ("(*bytes.Buffer).WriteTo" or "bytes" "(*Buffer).WriteTo").
+func splitQuery(versionDatas versiondb.VersionDatas, args []string) (string, string) {
+	if len(args) == 1 {
+		return versionDatas.SplitExpr(args[0])
+	}
+	return strings.ToLower(versiondb.NormalizeExpr(args[0])), versiondb.NormalizeExpr(args[1])
+}
+
+// Print the declaration of the symbol (nothing for a package), or the encountered error.
+func printDeclaration(versionDatas versiondb.VersionDatas, pkg string, symbol string) {
+	if symbol == "" {
+		return
+	}
+
+	declaration, err := versionDatas.Declaration(pkg, symbol)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(declaration)
+}
+
+// Print the release of the target since which the package or symbol (added in the Go version addedIn)
+// is supported and return 0, else print why and return the exit status (failure without data).
+func printTarget(target string, pkg string, symbol string, addedIn string) int {
+	targetDatas, err := versiondb.LoadTarget(conf, target)
+	if err != nil {
+		fmt.Println(target, ":", err)
+		return exitError
+	}
+
+	since, err := targetDatas.Since(pkg, symbol, addedIn)
+	if err != nil {
+		fmt.Println(target, ":", err, "("+targetDatas.Latest()+")")
+		if errors.Is(err, versiondb.ErrTargetUnknown) {
+			return exitError
+		}
+		return exitNo
+	}
+	fmt.Println(target, tr(supportedSince), since)
+	return 0
+}
+
+// Print the api file lines which produced the versions of the package or symbol, or the encountered error.
+func printExplain(versionDatas versiondb.VersionDatas, pkg string, symbol string) {
+	sourceLines, err := versionDatas.Explain(conf, pkg, symbol)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, sourceLine := range sourceLines {
+		fmt.Printf("%s:%d: %s\n", sourceLine.File, sourceLine.Line, sourceLine.Text)
+	}
+}
+
+// Print the parsing trace of the api file lines declaring the package or symbol, or the encountered error.
+func printDebugParse(versionDatas versiondb.VersionDatas, pkg string, symbol string) {
+	trace, err := versionDatas.DebugParse(conf, pkg, symbol)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(trace)
+}
+
+// Return the elements to print for an index entry.
+func entryMessage(versionDatas versiondb.VersionDatas, entry [3]string) []any {
+	pkg, symbol, _ := strings.Cut(entry[0], " ")
+	display := pkg
+	if symbol != "" {
+		display += " " + versionDatas.DisplayName(pkg, symbol)
+	}
+	return append(append([]any{display}, sinceMessage(versionDatas, pkg, symbol, [2]string{entry[1], entry[2]}, versionDatas.Platforms(pkg, symbol))...), memberMessage(versionDatas, pkg, symbol)...)
+}
+
+func printEntries(versionDatas versiondb.VersionDatas, entries [][3]string) {
+	for _, entry := range entries {
+		fmt.Println(entryMessage(versionDatas, entry)...)
+	}
+}
+
+// Print on w the entries as "pkg.Symbol: added in go1.N" lines, for the quickfix list of editors (at most limit when positive).
+func printQuickfix(w io.Writer, versionDatas versiondb.VersionDatas, entries [][3]string, limit int) {
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	for _, entry := range entries {
+		pkg, symbol, _ := strings.Cut(entry[0], " ")
+		display := pkg
+		if symbol != "" {
+			display += "." + versionDatas.SymbolName(pkg, symbol)
+		}
+		fmt.Fprintln(w, append([]any{display + ":"}, sinceMessage(versionDatas, pkg, symbol, [2]string{entry[1], entry[2]}, versionDatas.Platforms(pkg, symbol))...)...)
+	}
+}
+
+func printSortedEntries(versionDatas versiondb.VersionDatas, entries [][3]string, sortOrder string) {
+	if err := versiondb.SortEntries(entries, sortOrder); err != nil {
+		printError(err)
+		os.Exit(exitError)
+	}
+	printEntries(versionDatas, entries)
+}
+
+// Return the elements to print for added and deprecated versions, followed by platform restriction if any.
+func sinceMessage(versionDatas versiondb.VersionDatas, pkg string, symbol string, versions [2]string, platforms []string) []any {
+	res := []any{tr(addedIn), versions[0]}
+	if backports := backportDatas.Releases(pkg, symbol, versions[0]); len(backports) != 0 {
+		res = append(res, "("+tr(backportedTo), strings.Join(backports, ", ")+")")
+	}
+	if versions[1] != "" {
+		res = append(res, tr(deprecatedIn), versions[1])
+	}
+	if len(platforms) != 0 {
+		res = append(res, "("+strings.Join(versionDatas.CompactPlatforms(platforms), ", ")+" "+tr(onlyOn)+")")
+	}
+	return res
+}
+
+// Return the elements to print about the type of a struct field or the type and value of a constant,
+// if symbol is one (like "- field of type bool" or "- const untyped int = 1024").
+func memberMessage(versionDatas versiondb.VersionDatas, pkg string, symbol string) []any {
+	if field, ok := versionDatas.Field(pkg, symbol); ok {
+		if field.Embedded {
+			return []any{tr(embeddedField), field.Type}
+		}
+		return []any{tr(fieldOfType), field.Type}
+	}
+
+	constInfo, ok := versionDatas.Const(pkg, symbol)
+	if !ok || constInfo.Type == "" {
+		return nil
+	}
+
+	res := []any{tr(constPrefix)}
+	if constInfo.Untyped {
+		res = append(res, tr(untypedConst))
+	}
+	res = append(res, constInfo.Type)
+	switch {
+	case constInfo.Varies:
+		res[len(res)-1] = constInfo.Type + ","
+		res = append(res, tr(platformDependent))
+	case constInfo.Value != "":
+		res = append(res, "=", constInfo.Value)
+	}
+	return res
+}
+
+// Return the elements to print about the replacement of a deprecated package or symbol, if any.
+func replacementMessage(versionDatas versiondb.VersionDatas, pkg string, symbol string, deprecatedIn string) []any {
+	if deprecatedIn == "" {
+		return nil
+	}
+
+	switch replacement := versionDatas.Replacement(pkg, symbol); replacement {
+	case "":
+		return nil
+	case versiondb.NoReplacement:
+		return []any{tr(noReplacement)}
+	default:
+		return []any{tr(seeAlso), replacement}
+	}
+}
+
+// Return the elements to print about the aliased type, if the type is an alias
+// (like "- alias of io/fs.FileMode since go1.16 (itself added in go1.16)").
+func aliasMessage(versionDatas versiondb.VersionDatas, pkg string, symbol string) []any {
+	alias, ok := versionDatas.Alias(pkg, symbol)
+	if !ok {
+		return nil
+	}
+
+	res := []any{tr(aliasOf), alias.Target, tr(aliasSince), alias.Since}
+	if targetVersions, err := versionDatas.Since(alias.Package, alias.Symbol); alias.Package != "" && err == nil {
+		res = append(res, tr(itself), tr(addedIn), targetVersions[0]+")")
+	}
+	return res
+}
+
+// Return the elements to print about the releases missing the introducing version
+// (like "- not available in go1.20 and earlier", or "- not available before go1.20.4" when backported)
+// and the presence in supported Go releases.
+func supportMessage(versionDatas versiondb.VersionDatas, pkg string, symbol string, version string) []any {
+	var res []any
+	if backports := backportDatas.Releases(pkg, symbol, version); len(backports) != 0 {
+		res = []any{"-", tr(missingBefore), backports[0]}
+	} else if previous := versionDatas.PreviousVersion(version); previous != "" {
+		res = []any{"-", tr(missingIn), previous, tr(andEarlier)}
+	}
+
+	supported := versionDatas.SupportedVersions()
+	if len(supported) == 0 {
+		return res
+	}
+
+	if versiondb.CompareVersion(version, supported[0]) <= 0 {
+		return append(res, "-", tr(allSupported))
+	}
+
+	var missing []string
+	for _, supportedVersion := range supported {
+		if versiondb.CompareVersion(supportedVersion, version) < 0 {
+			missing = append(missing, supportedVersion)
+		}
+	}
+	return append(res, "-", tr(missingSupported), strings.Join(missing, ", "))
 }
@@ -0,0 +1,182 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+const compareMissing = "-"
+
+// Availability of one of the compared expressions.
+type comparedSymbol struct {
+	name     string
+	pkg      string
+	symbol   string
+	versions [2]string
+	err      error
+}
+
+func initCompare() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compare expr1 expr2",
+		Short: "Compare the availability of two packages or symbols side by side.",
+		Long: `Compare the availability of two packages or symbols side by side (introducing and deprecating versions,
+replacement, platforms, supported releases), like errors.Join and fmt.Errorf.
+
+Exit with status 1 when one of them is not found (or not available on the --goos and --goarch platforms) and 2 on other failures.
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			compared := [2]comparedSymbol{}
+			for index, arg := range args {
+				pkg, symbol := versionDatas.SplitExpr(arg)
+				versions, err := versionDatas.SinceOn(pkg, symbol, targetOs, targetArch)
+				switch err {
+				case nil, versiondb.ErrUnavailablePlatform, versiondb.ErrUnknownPackage, versiondb.ErrUnknownSymbol:
+				default:
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+
+				name := arg
+				if err == nil {
+					name = pkg
+					if symbol != "" {
+						name += "." + versionDatas.SymbolName(pkg, symbol)
+					}
+				}
+				compared[index] = comparedSymbol{name: name, pkg: pkg, symbol: symbol, versions: versions, err: err}
+			}
+
+			printComparison(versionDatas, compared)
+			if compared[0].err != nil || compared[1].err != nil {
+				os.Exit(exitNo)
+			}
+		},
+	}
+}
+
+func printComparison(versionDatas versiondb.VersionDatas, compared [2]comparedSymbol) {
+	rows := [][3]string{{"", compared[0].name, compared[1].name}}
+	addRow := func(title string, cell func(comparedSymbol) string) {
+		row := [3]string{tr(title)}
+		for index, current := range compared {
+			switch {
+			case current.err == nil:
+				row[index+1] = cell(current)
+			case len(rows) == 1: // the failure is only given once
+				row[index+1] = tr(current.err.Error())
+			default:
+				row[index+1] = compareMissing
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	addRow(addedIn, func(current comparedSymbol) string {
+		return current.versions[0]
+	})
+	addRow(deprecatedTitle, func(current comparedSymbol) string {
+		return orMissing(current.versions[1])
+	})
+	addRow(replacementTitle, func(current comparedSymbol) string {
+		if current.versions[1] == "" {
+			return compareMissing
+		}
+		return orMissing(versionDatas.Replacement(current.pkg, current.symbol))
+	})
+	addRow(platformsTitle, func(current comparedSymbol) string {
+		platforms := versionDatas.Platforms(current.pkg, current.symbol)
+		if len(platforms) == 0 {
+			return tr(allPlatforms)
+		}
+		return strings.Join(versionDatas.CompactPlatforms(platforms), ", ")
+	})
+	addRow(supportedTitle, func(current comparedSymbol) string {
+		return supportedReleases(versionDatas, current.versions[0])
+	})
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row[:], "\t"))
+	}
+	writer.Flush()
+
+	if conclusion := compareConclusion(compared); conclusion != "" {
+		fmt.Println(conclusion)
+	}
+}
+
+// Return "all" when version is in every supported release, or the supported releases missing it (translated).
+func supportedReleases(versionDatas versiondb.VersionDatas, version string) string {
+	var missing []string
+	for _, supported := range versionDatas.SupportedVersions() {
+		if versiondb.CompareVersion(supported, version) < 0 {
+			missing = append(missing, supported)
+		}
+	}
+	if len(missing) == 0 {
+		return tr(allPlatforms)
+	}
+	return tr(missingReleases) + " " + strings.Join(missing, ", ")
+}
+
+// Return the sentence telling which one can be used with the oldest Go release.
+func compareConclusion(compared [2]comparedSymbol) string {
+	first, second := compared[0], compared[1]
+	switch {
+	case first.err != nil && second.err != nil:
+		return ""
+	case first.err != nil:
+		return fmt.Sprintf(tr(onlyAvailable), second.name)
+	case second.err != nil:
+		return fmt.Sprintf(tr(onlyAvailable), first.name)
+	}
+
+	switch comparison := versiondb.CompareVersion(first.versions[0], second.versions[0]); {
+	case comparison == 0:
+		return fmt.Sprintf(tr(bothSince), first.versions[0])
+	case comparison > 0:
+		first, second = second, first
+	}
+	conclusion := fmt.Sprintf(tr(olderSince), first.name, first.versions[0], second.versions[0], second.name)
+	if first.versions[1] != "" {
+		conclusion += fmt.Sprintf(tr(butDeprecated), first.versions[1])
+	}
+	return conclusion
+}
+
+func orMissing(value string) string {
+	if value == "" {
+		return compareMissing
+	}
+	return value
+}
@@ -0,0 +1,264 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/features"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// Values proposed for the flags with a fixed set of choices (the descriptions are shown by fish, zsh and PowerShell).
+var flagChoices = map[string][]string{
+	"format":   {formatText + "\tHuman readable output", formatJson + "\tVersioned json output", formatQuickfix + "\tfile:line:col: message lines for editors", formatSarif + "\tSARIF report for code scanning"},
+	"go-doc":   {docBuiltin + "\tDocumentation parsed from the Go source", docGo + "\tThe go doc command", docPkgsite + "\tThe pkgsite command"},
+	"lang":     {langEn + "\tEnglish", langFr + "\tFrench"},
+	"snapshot": {snapshotRead + "\tUse exactly the files of the lockfile", snapshotWrite + "\tWrite the lockfile from the loaded files"},
+	"sort":     {versiondb.SortName + "\tBy symbol name", versiondb.SortPackage + "\tBy package path", versiondb.SortVersion + "\tBy introducing version"},
+}
+
+// Register the completion of the flag values and of the arguments (packages, symbols, versions and features),
+// on cmd and its subcommands.
+func registerCompletions(cmd *cobra.Command) {
+	registerFlags := func(flag *pflag.Flag) {
+		if choices, ok := flagChoices[flag.Name]; ok {
+			cmd.RegisterFlagCompletionFunc(flag.Name, cobra.FixedCompletions(choices, cobra.ShellCompDirectiveNoFileComp))
+			return
+		}
+
+		switch flag.Name {
+		case "go":
+			cmd.RegisterFlagCompletionFunc(flag.Name, completeVersionFlag)
+		case "goarch":
+			cmd.RegisterFlagCompletionFunc(flag.Name, completePlatformPart(false))
+		case "goos":
+			cmd.RegisterFlagCompletionFunc(flag.Name, completePlatformPart(true))
+		case "pkg":
+			cmd.RegisterFlagCompletionFunc(flag.Name, completePackageFlag)
+		}
+	}
+	cmd.Flags().VisitAll(registerFlags)
+	cmd.PersistentFlags().VisitAll(registerFlags)
+
+	if cmd.ValidArgsFunction == nil {
+		switch cmd.Name() {
+		case "avail":
+			cmd.ValidArgsFunction = completeVersionThenExprs
+		case "blockers":
+			cmd.ValidArgsFunction = completeVersionThenPackages
+		case "diff":
+			cmd.ValidArgsFunction = completeVersions(2)
+		case "feature":
+			cmd.ValidArgsFunction = completeFeatures
+		case "gosince", "q":
+			cmd.ValidArgsFunction = completeQuery
+		case "compare", "min":
+			cmd.ValidArgsFunction = completeExprs
+		case "list":
+			cmd.ValidArgsFunction = completePackagePattern
+		case "whatsnew":
+			cmd.ValidArgsFunction = completeVersions(1)
+		}
+	}
+
+	for _, subCmd := range cmd.Commands() {
+		registerCompletions(subCmd)
+	}
+}
+
+// Load the cached api files without download nor output, a completion must stay fast and silent.
+func completionDatas() (versiondb.VersionDatas, bool) {
+	if confErr != nil {
+		return versiondb.VersionDatas{}, false
+	}
+
+	completionConf := conf
+	completionConf.Offline = true
+	completionConf.Progress = nil
+	completionConf.Verbose = false
+	versionDatas, err := versiondb.LoadDatas(completionConf)
+	return versionDatas, err == nil
+}
+
+// Complete an expression, or the symbol of the package given as first argument.
+func completeQuery(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeExprs(cmd, args, toComplete)
+	case 1:
+		versionDatas, ok := completionDatas()
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return symbolCompletions(versionDatas, args[0], "", toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Complete an expression in <pkg> or <pkg>.<sym> form.
+func completeExprs(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	versionDatas, ok := completionDatas()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if indexDot := strings.LastIndexByte(toComplete, '.'); indexDot > strings.LastIndexByte(toComplete, '/') {
+		pkg := toComplete[:indexDot]
+		if _, err := versionDatas.SinceInfo(pkg, ""); err == nil {
+			return symbolCompletions(versionDatas, pkg, pkg+".", toComplete[indexDot+1:]), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return packageCompletions(versionDatas, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// Complete a version then expressions.
+func completeVersionThenExprs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeVersions(1)(cmd, args, toComplete)
+	}
+	return completeExprs(cmd, args[1:], toComplete)
+}
+
+// Complete a version then package paths.
+func completeVersionThenPackages(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeVersions(1)(cmd, args, toComplete)
+	}
+	return completePackagePattern(cmd, nil, toComplete)
+}
+
+// Complete the single package pattern argument.
+func completePackagePattern(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completePackageFlag(nil, nil, toComplete)
+}
+
+func completePackageFlag(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	versionDatas, ok := completionDatas()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return packageCompletions(versionDatas, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// Complete the count first arguments with the loaded versions (newest first).
+func completeVersions(count int) completionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= count {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeVersionFlag(nil, nil, toComplete)
+	}
+}
+
+func completeVersionFlag(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	versionDatas, ok := completionDatas()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	versions := versionDatas.Versions()
+	res := make([]string, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		if strings.HasPrefix(versions[i], toComplete) {
+			res = append(res, versions[i])
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// Complete the GOOS (or GOARCH) part of the platforms met in the api files.
+func completePlatformPart(goos bool) completionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		versionDatas, ok := completionDatas()
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var res []string
+		for _, platform := range versionDatas.KnownPlatforms() {
+			platformOs, platformArch, _ := strings.Cut(platform, "-")
+			part := platformArch
+			if goos {
+				part = platformOs
+			}
+			if part != "" && strings.HasPrefix(part, toComplete) && !slices.Contains(res, part) {
+				res = append(res, part)
+			}
+		}
+		return res, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeFeatures(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	all, err := features.All()
+	if err != nil || len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var res []string
+	for _, feature := range all {
+		if strings.HasPrefix(feature.Name, toComplete) {
+			res = append(res, feature.Name+"\t"+tr(addedIn)+" "+feature.Version)
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// Return the package paths starting with prefix, described by their introducing version.
+func packageCompletions(versionDatas versiondb.VersionDatas, prefix string) []string {
+	var res []string
+	for _, pkg := range versionDatas.PackagesWithPrefix(prefix) {
+		info, _ := versionDatas.SinceInfo(pkg, "")
+		res = append(res, completionEntry(pkg, info))
+	}
+	return res
+}
+
+// Return the symbols of pkg starting with prefix (case is ignored), each preceded by before.
+func symbolCompletions(versionDatas versiondb.VersionDatas, pkg string, before string, prefix string) []string {
+	symbols, err := versionDatas.Symbols(pkg)
+	if err != nil {
+		return nil
+	}
+
+	prefix = strings.ToLower(prefix)
+	var res []string
+	for _, symbol := range symbols {
+		if symbol.Name != "" && strings.HasPrefix(strings.ToLower(symbol.Name), prefix) {
+			res = append(res, completionEntry(before+symbol.Name, symbol))
+		}
+	}
+	return res
+}
+
+func completionEntry(value string, info versiondb.SymbolInfo) string {
+	if info.AddedIn == "" {
+		return value
+	}
+	return value + "\t" + tr(addedIn) + " " + info.AddedIn
+}
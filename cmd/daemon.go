@@ -0,0 +1,233 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	daemonSocketName  = "gosince.sock"
+	daemonDialTimeout = 100 * time.Millisecond
+	daemonTimeout     = 5 * time.Second
+)
+
+var (
+	errDaemonRunning = errors.New("daemon failure : a daemon already listens on the socket")
+	errDaemonSource  = errors.New("daemon failure : the daemon reads another Go source")
+)
+
+// Flags a lookup forwarded to the daemon can use, the others (like --explain or --cache-path) need the local database.
+var daemonFlags = map[string]struct{}{
+	"case-sensitive": {}, "exact": {}, "format": {}, "goarch": {}, "goos": {}, "json": {}, "lang": {},
+	"no-toolchain-check": {}, "quiet": {}, "sort": {}, "suggestions": {},
+}
+
+// Lookup forwarded by the command line.
+type daemonRequest struct {
+	Args         []string          `json:"args"`
+	Lang         string            `json:"lang,omitempty"`
+	Options      lookupOptions     `json:"options"`
+	Replacements map[string]string `json:"replacements,omitempty"` // from the profile of the command line directory
+	Source       daemonSource      `json:"source"`
+}
+
+// Go source of the database, the daemon refuses the lookups expecting another one.
+type daemonSource struct {
+	SourceUrl string `json:"source_url"`
+	GitUrl    string `json:"git_url,omitempty"`
+	GitRef    string `json:"git_ref,omitempty"`
+}
+
+type daemonResponse struct {
+	Output  string       `json:"output"` // as printed by the lookup
+	Answer  lookupAnswer `json:"answer"`
+	Refused string       `json:"refused,omitempty"` // reason to do the lookup locally
+}
+
+func initDaemon() *cobra.Command {
+	refreshInterval := 24 * time.Hour
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep the database in memory and answer the lookups of the command line over a unix socket.",
+		Long: `Keep the database in memory and answer the lookups of the command line over a unix socket
+(GOSINCE_DAEMON_SOCKET, or gosince.sock in the cache directory) : while it runs, a plain "gosince <expr>" is forwarded
+to it instead of loading the api files, the flags needing the local database (like --explain) disable the forwarding.
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			socketPath := daemonSocketPath()
+			if conn, err := net.DialTimeout("unix", socketPath, daemonDialTimeout); err == nil {
+				conn.Close()
+				printError(errDaemonRunning)
+				os.Exit(exitError)
+			}
+
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+			os.Remove(socketPath) // left by a daemon which did not stop cleanly
+
+			listener, err := net.Listen("unix", socketPath)
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				listener.Close() // remove the socket too
+			}()
+
+			var lock sync.Mutex
+			if refreshInterval > 0 {
+				go func() {
+					ticker := time.NewTicker(refreshInterval)
+					defer ticker.Stop()
+					for range ticker.C {
+						reloaded, backports, docRef, err := loadAllDatas(conf)
+						if err != nil {
+							fmt.Println("Refresh failure :", err)
+							continue
+						}
+
+						lock.Lock() // the backports and the documentation ref are global
+						versionDatas, backportDatas, conf.DocRef = reloaded, backports, docRef
+						lock.Unlock()
+					}
+				}()
+			}
+
+			fmt.Println("Listen on", socketPath)
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					if ctx.Err() == nil {
+						fmt.Println(err)
+						os.Exit(exitError)
+					}
+					fmt.Println("Shutting down")
+					return
+				}
+
+				go func() {
+					defer conn.Close()
+					conn.SetDeadline(time.Now().Add(daemonTimeout))
+
+					var request daemonRequest
+					if err := json.NewDecoder(conn).Decode(&request); err != nil {
+						return
+					}
+
+					lock.Lock() // the language and the json output are global
+					response := answerDaemonRequest(versionDatas, request)
+					lock.Unlock()
+					json.NewEncoder(conn).Encode(response)
+				}()
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&refreshInterval, "refresh", refreshInterval, "Interval between reloads of the database (downloading the new api files), 0 to disable")
+
+	return cmd
+}
+
+func daemonSocketPath() string {
+	return cmp.Or(os.Getenv(config.EnvDaemonSocket), filepath.Join(conf.RepoPath, daemonSocketName))
+}
+
+func currentDaemonSource() daemonSource {
+	return daemonSource{SourceUrl: conf.SourceUrl, GitUrl: conf.GitUrl, GitRef: conf.GitRef}
+}
+
+func answerDaemonRequest(versionDatas versiondb.VersionDatas, request daemonRequest) daemonResponse {
+	if request.Source != currentDaemonSource() {
+		return daemonResponse{Refused: errDaemonSource.Error()}
+	}
+
+	jsonOutput = request.Options.Format == formatJson
+	var output bytes.Buffer
+	if err := setLanguage(request.Lang); err != nil {
+		fprintError(&output, err)
+		return daemonResponse{Output: output.String(), Answer: lookupAnswer{Status: exitError}}
+	}
+
+	answer := printLookup(&output, versionDatas.WithReplacements(request.Replacements), request.Args, request.Options)
+	return daemonResponse{Output: output.String(), Answer: answer}
+}
+
+// Forward the lookup to the daemon when one listens on the same Go source and the set flags allow it, print its output
+// and return its outcome (false when the lookup is to be done locally).
+func askDaemon(cmdFlags *pflag.FlagSet, args []string, lang string, options lookupOptions, replacements map[string]string) (lookupAnswer, bool) {
+	if confErr != nil {
+		return lookupAnswer{}, false
+	}
+
+	forward := true
+	cmdFlags.Visit(func(flag *pflag.Flag) {
+		_, allowed := daemonFlags[flag.Name]
+		forward = forward && allowed
+	})
+	if !forward {
+		return lookupAnswer{}, false
+	}
+
+	conn, err := net.DialTimeout("unix", daemonSocketPath(), daemonDialTimeout)
+	if err != nil {
+		return lookupAnswer{}, false
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(daemonTimeout))
+
+	if err = json.NewEncoder(conn).Encode(daemonRequest{
+		Args: args, Lang: lang, Options: options, Replacements: replacements, Source: currentDaemonSource(),
+	}); err != nil {
+		return lookupAnswer{}, false
+	}
+
+	var response daemonResponse
+	if err = json.NewDecoder(conn).Decode(&response); err != nil || response.Refused != "" {
+		return lookupAnswer{}, false
+	}
+
+	fmt.Print(response.Output)
+	return response.Answer, true
+}
@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/config"
+	"github.com/spf13/cobra"
+)
+
+func initDeprecated() *cobra.Command {
+	var options profileOptions
+
+	cmd := &cobra.Command{
+		Use:   "deprecated [packages]",
+		Short: "Report the uses of deprecated api in the analyzed code.",
+		Long: `Report the uses of deprecated api in the analyzed code.
+
+Settings are read from the project profile (` + config.DefaultProfileName + ` by default), flags override them.
+Packages default to ./... relative to the profile directory.
+Exit with status 1 when a deprecated use is found and 2 on other failures.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if options.printSchema(usagesSchema) {
+				return
+			}
+
+			profile, err := options.load(cmd.Flags())
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			usages, _, ok := runAnalysis(profile, args)
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			usages = analysis.Deprecated(usages)
+			if err = printUsages(usages, profile, deprecatedRule, analysis.Usage.DeprecatedMessage); err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			if len(usages) != 0 {
+				os.Exit(exitNo)
+			}
+		},
+	}
+
+	options.addFlags(cmd.Flags(), false)
+
+	return cmd
+}
@@ -0,0 +1,211 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"github.com/dvaumoron/gosince/pkgdoc"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const (
+	docBuiltin = "builtin"
+	docGo      = "go"
+	docPkgsite = "pkgsite"
+)
+
+var (
+	errEmptyDocCommand    = errors.New("documentation failure : empty command")
+	errUnclosedDocCommand = errors.New("documentation failure : unclosed quote in command")
+)
+
+// predefined command templates, by backend name
+var docTemplates = map[string]string{
+	docGo: "go doc {{.Package}} {{.Symbol}}",
+}
+
+// Query given to the command templates of the documentation backends.
+type docQuery struct {
+	Package string // like "net/http"
+	Symbol  string // like "Client.Do", empty for the package itself
+	Query   string // like "net/http.Client.Do"
+	Url     string // pkg.go.dev url
+}
+
+// Print (or open for pkgsite) the documentation of the package or symbol with backend :
+// builtin renders it from the cached Go source, others are command templates (text/template on a docQuery)
+// executed as a whole before being split into arguments.
+func printDoc(backend string, pkg string, symbol string, docUrl string) error {
+	switch backend {
+	case docBuiltin:
+		docPkg, err := pkgdoc.Load(conf, pkg)
+		if err != nil {
+			return err
+		}
+		return docPkg.Render(os.Stdout, symbol)
+	case docPkgsite:
+		return openBrowser(docUrl)
+	}
+
+	if predefined, ok := docTemplates[backend]; ok {
+		backend = predefined
+	}
+
+	query := docQuery{Package: pkg, Symbol: symbol, Query: pkg, Url: docUrl}
+	if symbol != "" {
+		query.Query += "." + symbol
+	}
+
+	tmpl, err := template.New(backend).Parse(backend)
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	if err = tmpl.Execute(&builder, query); err != nil {
+		return err
+	}
+
+	cmdArgs, err := splitCommand(builder.String())
+	if err != nil {
+		return err
+	}
+	if len(cmdArgs) == 0 {
+		return errEmptyDocCommand
+	}
+
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	return cmd.Run()
+}
+
+// Split the executed command template into arguments like a shell, without expansion :
+// single quotes keep their content as is, double quotes group it and a backslash escapes the next character.
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'' && r != '\'':
+			current.WriteRune(r)
+		case r == quote:
+			quote = 0
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errUnclosedDocCommand
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// Print the first paragraph of the documentation of the package or symbol, or the encountered error.
+func printDocExcerpt(pkg string, symbol string) {
+	docPkg, err := pkgdoc.Load(conf, pkg)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	excerpt, err := docPkg.FirstParagraph(symbol)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(excerpt)
+}
+
+// Print the paragraphs of the release notes about the package or symbol, for the versions introducing
+// and deprecating it, or the encountered error.
+func printReleaseNotes(versionDatas versiondb.VersionDatas, pkg string, symbol string) {
+	versions, err := versionDatas.Since(pkg, symbol)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if symbol != "" {
+		symbol = versionDatas.SymbolName(pkg, symbol)
+	}
+	for _, version := range versions {
+		if version == "" {
+			continue
+		}
+
+		notes, err := versiondb.LoadReleaseNotes(conf, version)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+
+		paragraphs := notes.About(pkg, symbol)
+		if len(paragraphs) == 0 {
+			fmt.Println(version, tr(notesMissing), notes.Url)
+			continue
+		}
+
+		fmt.Println(version, tr(releaseNotes))
+		for _, paragraph := range paragraphs {
+			fmt.Println(" ", paragraph.Text, "-", paragraph.Url)
+		}
+	}
+}
+
+// Print the examples of the package or symbol, or the encountered error.
+func printExamples(pkg string, symbol string) {
+	examples, err := pkgdoc.LoadExamples(conf, pkg)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err = examples.Render(os.Stdout, symbol); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dvaumoron/gosince/export"
+	"github.com/spf13/cobra"
+)
+
+var errNoExportFormat = errors.New("export failure : choose a format with --sqlite or --parquet")
+
+func initExport() *cobra.Command {
+	parquetDir := ""
+	sqlitePath := ""
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the whole database for data analysis.",
+		Long: `Export the whole database for data analysis.
+
+With --sqlite, the versions, packages, symbols (with their deprecation) and platforms are written
+in a normalized schema, with an entries view joining them.
+
+With --parquet, the entries (packages and symbols) and the versions are written as entries.parquet
+and versions.parquet in the directory.
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			if sqlitePath == "" && parquetDir == "" {
+				fmt.Println(errNoExportFormat)
+				os.Exit(exitError)
+			}
+
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			if sqlitePath != "" {
+				if err := export.WriteSqlite(versionDatas, sqlitePath); err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				fmt.Println("database exported to", sqlitePath)
+			}
+			if parquetDir != "" {
+				if err := export.WriteParquet(versionDatas, parquetDir); err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				fmt.Println("database exported to", parquetDir)
+			}
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.StringVar(&parquetDir, "parquet", "", "Write Parquet files to this directory")
+	cmdFlags.StringVar(&sqlitePath, "sqlite", "", "Write a SQLite database to this file")
+
+	return cmd
+}
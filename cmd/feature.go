@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dvaumoron/gosince/features"
+	"github.com/spf13/cobra"
+)
+
+func initFeature() *cobra.Command {
+	return &cobra.Command{
+		Use:   "feature [name]",
+		Short: "Show the introducing version of a language feature (like generics or range-over-func).",
+		Long: `Show the introducing version of a language feature (like generics, loopvar or range-over-func),
+with the GOEXPERIMENT previewing it in an earlier release when there was one.
+Without name, list the known features in release order.
+
+Exit with status 1 when the feature is unknown and 2 on other failures.
+`,
+		Args: cobra.RangeArgs(0, 1),
+		Run: func(_ *cobra.Command, args []string) {
+			if len(args) == 0 {
+				all, err := features.All()
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+
+				for _, feature := range all {
+					fmt.Println(featureMessage(feature)...)
+				}
+				return
+			}
+
+			feature, err := features.Find(args[0])
+			switch err {
+			case nil:
+				fmt.Println(featureMessage(feature)...)
+				fmt.Println(feature.Description)
+			case features.ErrUnknownFeature:
+				fmt.Println(err)
+				if similar, _ := features.Search(args[0]); len(similar) != 0 {
+					fmt.Println(tr(severalFound))
+					for _, feature := range similar {
+						fmt.Println(featureMessage(feature)...)
+					}
+				}
+				os.Exit(exitNo)
+			default:
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+		},
+	}
+}
+
+// Return the elements to print for a feature, like "loopvar added in go1.22 (GOEXPERIMENT=loopvar in go1.21)".
+func featureMessage(feature features.Feature) []any {
+	res := []any{feature.Name, tr(addedIn), feature.Version}
+	if feature.Experiment != "" {
+		experiment := "GOEXPERIMENT=" + feature.Experiment
+		if strings.HasPrefix(feature.Experiment, "GO") { // an environment variable of its own
+			experiment = feature.Experiment + "=1"
+		}
+		res = append(res, "("+experiment, "in", feature.ExperimentVersion+")")
+	}
+	return res
+}
@@ -0,0 +1,64 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+func initGen() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate files for packaging.",
+		Args:  cobra.NoArgs,
+	}
+
+	manCmd := &cobra.Command{
+		Use:   "man dir",
+		Short: "Write the man pages of gosince and its commands in the directory.",
+		Long: `Write the man pages (section 1) of gosince and its commands in the directory, created if needed.
+
+The date of the pages is read from SOURCE_DATE_EPOCH when set, for reproducible builds.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := os.MkdirAll(args[0], 0755); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			root := cmd.Root()
+			root.DisableAutoGenTag = true
+			// the default depends on the user generating the pages
+			root.PersistentFlags().Lookup("cache-path").DefValue = "$HOME/.gosince"
+			header := &doc.GenManHeader{Title: "GOSINCE", Section: "1", Source: "gosince " + toolVersion, Manual: "gosince manual"}
+			if err := doc.GenManTree(root, header, args[0]); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+		},
+	}
+	cmd.AddCommand(manCmd)
+
+	return cmd
+}
@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+func initGodebug() *cobra.Command {
+	return &cobra.Command{
+		Use:   "godebug setting",
+		Short: "Show the introducing version of a GODEBUG setting and its later changes.",
+		Long: `Show the introducing version of a GODEBUG setting and its later changes (like a default change),
+from the history section of doc/godebug.md in the Go source.
+
+Exit with status 1 when the setting is unknown and 2 on other failures.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if confErr != nil {
+				fmt.Println(confErr)
+				os.Exit(exitError)
+			}
+
+			godebugDatas, err := versiondb.LoadGodebug(conf)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			name, _, _ := strings.Cut(args[0], "=") // accept "name=value"
+			setting, err := godebugDatas.Since(name)
+			if err != nil {
+				fmt.Println(err)
+				if names := godebugDatas.Search(name); len(names) != 0 {
+					fmt.Println(tr(similarSettings), strings.Join(names, ", "))
+				}
+				os.Exit(exitNo)
+			}
+
+			fmt.Println(setting.Name, tr(addedIn), setting.Changes[0].Version)
+			for _, change := range setting.Changes {
+				fmt.Println("-", change.Version, ":", change.Text)
+			}
+		},
+	}
+}
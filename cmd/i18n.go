@@ -0,0 +1,141 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const (
+	langEn = "en"
+	langFr = "fr"
+
+	languageSeparators = "_.-" // like "fr_FR.UTF-8"
+)
+
+var errLang = errors.New("language failure : expect " + strings.Join(slices.Sorted(maps.Keys(catalogs)), " or "))
+
+// Translations by language of the english messages, english has no entry.
+var catalogs = map[string]map[string]string{
+	langEn: nil,
+	langFr: {
+		addedIn:          "ajouté dans",
+		allSupported:     "présent dans toutes les versions de Go maintenues",
+		andEarlier:       "et antérieures",
+		backportedTo:     "rétroporté dans",
+		deprecatedIn:     "et déprécié dans",
+		found:            "trouvé",
+		missingBefore:    "non disponible avant",
+		missingIn:        "non disponible dans",
+		missingSupported: "non disponible dans les versions maintenues",
+
+		aliasOf:           "- alias de",
+		aliasSince:        "depuis",
+		constPrefix:       "- constante",
+		embeddedField:     "- champ embarqué",
+		fieldOfType:       "- champ de type",
+		itself:            "(lui-même",
+		moreHidden:        "et %d de plus (affichées avec un --suggestions plus grand)",
+		newerThan:         ", plus récent que",
+		newerThanTarget:   ", plus récent que la cible",
+		noReplacement:     "- sans remplacement",
+		onlyOn:            "uniquement",
+		platformDependent: "valeur dépendant de la plateforme",
+		seeAlso:           "- voir",
+		severalFound:      "Plusieurs possibilités trouvées :",
+		similarSettings:   "Réglages similaires :",
+		untypedConst:      "non typée",
+
+		allPlatforms:     "toutes",
+		answerNo:         "non",
+		answerYes:        "oui",
+		bothSince:        "les deux sont disponibles depuis %s",
+		butDeprecated:    " mais déprécié dans %s",
+		deprecatedTitle:  "déprécié dans",
+		localToolchain:   "votre chaîne d'outils est %s, non disponible",
+		missingReleases:  "absent de",
+		needs:            "(nécessite",
+		nothingBlocks:    "rien ne bloque",
+		olderSince:       "%s est plus ancien, disponible depuis %s (%s pour %s)",
+		onlyAvailable:    "%s est le seul disponible",
+		platformsTitle:   "plateformes",
+		replacementTitle: "remplacement",
+		requiredBy:       "requis par",
+		supportedTitle:   "versions maintenues",
+
+		errEmptyDiff.Error(): "aucune api ajoutée ou dépréciée dans l'intervalle",
+
+		additionsTitle:    "Ajouts par domaine :",
+		andMore:           " et %d de plus",
+		deprecationsTitle: "Dépréciations :",
+		languageTitle:     "Langage :",
+		newPackagesTitle:  "Nouveaux paquets :",
+		notesTitle:        "Notes de version :",
+		otherAdditions:    "constantes, variables ou champs (%d)",
+		symbolCount:       "(%d symboles)",
+
+		notesMissing: "les notes de version ne le mentionnent pas -",
+		releaseNotes: "notes de version :",
+
+		supportedSince: ": supporté depuis",
+
+		goModDryRun:     "go.mod à modifier :",
+		goModRequires:   "go.mod requiert déjà go",
+		goModUpdated:    "go.mod mis à jour :",
+		minimumRequired: "la version minimale requise est",
+
+		minimumNow: "la version minimale requise est maintenant",
+		watching:   "Surveillance des modifications de %s...",
+
+		versiondb.ErrUnavailablePlatform.Error(): "non disponible sur la plateforme demandée",
+		versiondb.ErrUnknownPackage.Error():      "paquet introuvable",
+		versiondb.ErrUnknownSymbol.Error():       "symbole introuvable",
+		versiondb.ErrUnknownVersion.Error():      "version de go inconnue",
+	},
+}
+
+var messages map[string]string // of the selected language
+
+// Select the language of the messages, like "fr" or "fr_FR.UTF-8" (empty for english).
+func setLanguage(lang string) error {
+	if index := strings.IndexAny(lang, languageSeparators); index != -1 {
+		lang = lang[:index]
+	}
+
+	catalog, ok := catalogs[strings.ToLower(cmp.Or(lang, langEn))]
+	if !ok {
+		return errLang
+	}
+	messages = catalog
+	return nil
+}
+
+// Return the translation of the english message in the selected language.
+func tr(message string) string {
+	if translated, ok := messages[message]; ok {
+		return translated
+	}
+	return message
+}
@@ -0,0 +1,132 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+// Stable codes of the json errors.
+const (
+	codeFailure             = "failure" // any other failure, the message tells more
+	codeNetwork             = "network"
+	codeNoTarget            = "no_target"
+	codeUnavailablePlatform = "unavailable_platform"
+	codeUnknownPackage      = "unknown_package"
+	codeUnknownSymbol       = "unknown_symbol"
+	codeUnknownVersion      = "unknown_version"
+)
+
+// Set by --json (or a json output format), the answers and the failures are then printed as json objects.
+var jsonOutput bool
+
+type queryOutput struct {
+	SchemaVersion int            `json:"schema_version"`
+	Results       []resultOutput `json:"results"`
+	Hidden        int            `json:"hidden,omitempty"` // results beyond the --suggestions limit
+}
+
+type resultOutput struct {
+	Package      string   `json:"package"`
+	Symbol       string   `json:"symbol,omitempty"`
+	AddedIn      string   `json:"added_in"`
+	DeprecatedIn string   `json:"deprecated_in,omitempty"`
+	Replacement  string   `json:"replacement,omitempty"`
+	Platforms    []string `json:"platforms,omitempty"`
+	Summary      string   `json:"summary,omitempty"` // with --summaries
+	DocUrl       string   `json:"doc_url"`
+}
+
+type errorOutput struct {
+	SchemaVersion int         `json:"schema_version"`
+	Error         errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Print the index entries as a json object, limited to the first ones when limit is positive.
+func printResults(w io.Writer, versionDatas versiondb.VersionDatas, entries [][3]string, limit int) {
+	output := queryOutput{SchemaVersion: jsonSchemaVersion, Results: make([]resultOutput, 0, len(entries))}
+	if limit > 0 && len(entries) > limit {
+		entries, output.Hidden = entries[:limit], len(entries)-limit
+	}
+	for _, entry := range entries {
+		pkg, symbol, _ := strings.Cut(entry[0], " ")
+		result := resultOutput{
+			Package: pkg, Symbol: symbol, AddedIn: entry[1], DeprecatedIn: entry[2],
+			Platforms: versionDatas.Platforms(pkg, symbol), Summary: versionDatas.Summary(pkg, symbol),
+			DocUrl: versionDatas.DocUrl(pkg, symbol),
+		}
+		if result.DeprecatedIn != "" {
+			result.Replacement = versionDatas.Replacement(pkg, symbol)
+		}
+		output.Results = append(output.Results, result)
+	}
+
+	if err := fprintJson(w, output); err != nil {
+		fprintError(w, err)
+	}
+}
+
+// Print err, as a json object with a stable code when the output is json.
+func printError(err error) {
+	fprintError(os.Stdout, err)
+}
+
+func fprintError(w io.Writer, err error) {
+	if !jsonOutput {
+		fmt.Fprintln(w, tr(err.Error()))
+		return
+	}
+
+	if printErr := fprintJson(w, errorOutput{SchemaVersion: jsonSchemaVersion, Error: errorDetail{Code: errorCode(err), Message: err.Error()}}); printErr != nil {
+		fmt.Fprintln(w, err)
+	}
+}
+
+func errorCode(err error) string {
+	var netErr net.Error
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, versiondb.ErrUnknownPackage):
+		return codeUnknownPackage
+	case errors.Is(err, versiondb.ErrUnknownSymbol):
+		return codeUnknownSymbol
+	case errors.Is(err, versiondb.ErrUnknownVersion):
+		return codeUnknownVersion
+	case errors.Is(err, versiondb.ErrUnavailablePlatform):
+		return codeUnavailablePlatform
+	case errors.Is(err, errNoTarget):
+		return codeNoTarget
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return codeNetwork
+	}
+	return codeFailure
+}
@@ -0,0 +1,128 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+var errEmptyDiff = errors.New("no api added or deprecated in the range")
+
+func initList() *cobra.Command {
+	members := false
+	sortOrder := versiondb.SortVersion
+
+	cmd := &cobra.Command{
+		Use:     "list pkgPattern",
+		Aliases: []string{"l"},
+		Short:   "List the content of the packages matching the pattern (like 'crypto/*').",
+		Long: `List the content of the packages matching the pattern (like 'crypto/*').
+
+With --members, the argument is a type (like net.Conn) and its methods (or fields) are listed
+with the version they were added to the type.
+Exit with status 1 when nothing matches and 2 on other failures.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			checkSortOrder(sortOrder)
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			var results [][3]string
+			var err error
+			if members {
+				indexDot := strings.LastIndexByte(args[0], '.')
+				if indexDot == -1 {
+					printError(versiondb.ErrUnknownSymbol)
+					os.Exit(exitNo)
+				}
+				results, err = versionDatas.Members(args[0][:indexDot], args[0][indexDot+1:])
+			} else {
+				results, err = versionDatas.List(args[0])
+			}
+			if err != nil {
+				printError(err)
+				os.Exit(exitStatus(err))
+			}
+
+			results = versionDatas.FilterPlatform(results, targetOs, targetArch)
+			if len(results) == 0 && !members {
+				printError(versiondb.ErrUnknownPackage)
+				os.Exit(exitNo)
+			}
+			printSortedEntries(versionDatas, results, sortOrder)
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.BoolVar(&members, "members", false, "List the members of a type (like net.Conn) instead of packages")
+	addSortFlag(cmdFlags, &sortOrder)
+
+	return cmd
+}
+
+func initDiff() *cobra.Command {
+	pkgPattern := ""
+	sortOrder := versiondb.SortVersion
+
+	cmd := &cobra.Command{
+		Use:     "diff fromVersion toVersion",
+		Aliases: []string{"d"},
+		Short:   "List the api added or deprecated after fromVersion up to toVersion.",
+		Long: `List the api added or deprecated after fromVersion up to toVersion.
+
+Exit with status 1 when nothing is listed (no api in the range, no package matching --pkg or fromVersion not older
+than toVersion) and 2 on other failures.
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			checkSortOrder(sortOrder)
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			results, err := versionDatas.Diff(args[0], args[1], pkgPattern)
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			results = versionDatas.FilterPlatform(results, targetOs, targetArch)
+			if len(results) == 0 {
+				printError(errEmptyDiff)
+				os.Exit(exitNo)
+			}
+			printSortedEntries(versionDatas, results, sortOrder)
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.StringVar(&pkgPattern, "pkg", "", "Restrict to packages matching the pattern (like 'encoding/*')")
+	addSortFlag(cmdFlags, &sortOrder)
+
+	return cmd
+}
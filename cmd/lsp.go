@@ -0,0 +1,50 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dvaumoron/gosince/lsp"
+	"github.com/spf13/cobra"
+)
+
+func initLsp() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server showing the versions of the api under the cursor.",
+		Long: `Run a language server (over stdin and stdout) answering hover and code action requests
+with the introducing and deprecating versions of the standard library api under the cursor.
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			conf.Verbose = false // stdout is reserved to the protocol
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			if err := lsp.Serve(versionDatas, os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitError)
+			}
+		},
+	}
+}
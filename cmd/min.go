@@ -0,0 +1,78 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func initMin() *cobra.Command {
+	return &cobra.Command{
+		Use:   "min [expr...]",
+		Short: "Show the minimum go version needed by a list of packages and symbols.",
+		Long: `Show the minimum go version needed by a list of packages and symbols (in <pkg> or <pkg>.<sym>[.<methodOrField>] form).
+
+Without argument (or with -), expressions are read from the standard input, separated by spaces or new lines.
+Exit with status 1 when an expression is not found.
+`,
+		Run: func(_ *cobra.Command, args []string) {
+			if len(args) == 0 || (len(args) == 1 && args[0] == "-") {
+				var err error
+				if args, err = readWords(); err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+			}
+
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			minVersion, responsibles, failures := versionDatas.MinVersion(args, targetOs, targetArch)
+			for _, arg := range args {
+				if err, ok := failures[arg]; ok {
+					fmt.Println(arg, ":", err)
+				}
+			}
+
+			if minVersion != "" {
+				fmt.Println(minVersion, tr(requiredBy), strings.Join(responsibles, ", "))
+			}
+			if len(failures) != 0 {
+				os.Exit(exitNo)
+			}
+		},
+	}
+}
+
+func readWords() ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+	}
+	return words, scanner.Err()
+}
@@ -0,0 +1,81 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+const progressInterval = 100 * time.Millisecond
+
+var spinnerFrames = [...]string{"|", "/", "-", `\`}
+
+// Return a progress function drawing a status line on the standard error,
+// nil when it is not a terminal (the outputs stay clean in pipes and CI logs).
+func newProgressPrinter() config.ProgressFunc {
+	info, err := os.Stderr.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return progressPrinter(os.Stderr)
+}
+
+func progressPrinter(w io.Writer) config.ProgressFunc {
+	var mutex sync.Mutex
+	var lastDraw time.Time
+	frame := 0
+	return func(file string, read int64, total int64, done bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if done {
+			fmt.Fprintf(w, "\r\033[Kdownloaded %s (%s)\n", file, formatSize(read))
+			lastDraw = time.Time{}
+			return
+		}
+		if time.Since(lastDraw) < progressInterval {
+			return
+		}
+		lastDraw = time.Now()
+
+		if total > 0 {
+			fmt.Fprintf(w, "\r\033[Kdownloading %s %3d%% (%s / %s)", file, read*100/total, formatSize(read), formatSize(total))
+			return
+		}
+		frame = (frame + 1) % len(spinnerFrames)
+		fmt.Fprintf(w, "\r\033[Kdownloading %s %s (%s)", file, spinnerFrames[frame], formatSize(read))
+	}
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprint(size, " B")
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+// Settings of a lookup, shared by the root command and the daemon.
+type lookupOptions struct {
+	Format        string `json:"format"`
+	SortOrder     string `json:"sort"`
+	Suggestions   int    `json:"suggestions"`
+	CaseSensitive bool   `json:"case_sensitive,omitempty"`
+	Exact         bool   `json:"exact,omitempty"`
+	Goos          string `json:"goos,omitempty"`
+	Goarch        string `json:"goarch,omitempty"`
+}
+
+// Outcome of a printed lookup.
+type lookupAnswer struct {
+	Package  string `json:"package"` // the queried one when not found
+	Symbol   string `json:"symbol,omitempty"`
+	AddedIn  string `json:"added_in,omitempty"` // set only when a single text answer was printed
+	NotFound bool   `json:"not_found,omitempty"`
+	Status   int    `json:"status,omitempty"` // exit status
+}
+
+// Print on w the answer to the query in args (an expression, or a package and a symbol), or the similar names
+// when it is not found.
+func printLookup(w io.Writer, versionDatas versiondb.VersionDatas, args []string, options lookupOptions) lookupAnswer {
+	if options.CaseSensitive {
+		versionDatas = versionDatas.ExactCase()
+	}
+
+	pkg, symbol := splitQuery(versionDatas, args)
+	answer := lookupAnswer{Package: pkg, Symbol: symbol}
+
+	symbolData, err := versionDatas.SinceOn(pkg, symbol, options.Goos, options.Goarch)
+	if err != nil {
+		if options.Exact {
+			fprintError(w, err)
+			answer.Status = exitNo
+			return answer
+		}
+
+		var queries []string
+		switch err {
+		case versiondb.ErrUnknownPackage:
+			if symbol == "" {
+				indexSlash := strings.IndexByte(pkg, '/')
+				queries = []string{pkg[indexSlash+1:]} // no error when indexSlash is -1
+				break
+			}
+			if strings.IndexByte(symbol, '.') == -1 {
+				queries = []string{pkg + "." + symbol} // pkg could be a receiver type
+			}
+			fallthrough
+		case versiondb.ErrUnknownSymbol:
+			indexDot := strings.IndexByte(symbol, '.')
+			if indexDot != -1 {
+				queries = append(queries, symbol) // receiver-scoped first
+			}
+			queries = append(queries, symbol[indexDot+1:]) // no error when indexDot is -1
+		default:
+			fprintError(w, err)
+			answer.Status = exitError
+			if err == versiondb.ErrUnavailablePlatform {
+				answer.Status = exitNo
+			}
+			return answer
+		}
+
+		var results [][3]string
+		for _, query := range queries {
+			results = versionDatas.FilterPlatform(versionDatas.Search(query), options.Goos, options.Goarch)
+			if len(results) != 0 {
+				break
+			}
+		}
+		if err := versiondb.SortEntries(results, options.SortOrder); err != nil {
+			fprintError(w, err)
+			answer.Status = exitError
+			return answer
+		}
+
+		switch len(results) {
+		case 0:
+			fprintError(w, err)
+			answer.NotFound = true
+			answer.Status = exitNo
+		case 1:
+			switch options.Format {
+			case formatJson:
+				printResults(w, versionDatas, results, 0)
+				return answer
+			case formatQuickfix:
+				printQuickfix(w, versionDatas, results, 0)
+				return answer
+			}
+
+			result := results[0]
+			resultPkg, resultSymbol, _ := strings.Cut(result[0], " ")
+			message := []any{tr(found)}
+			message = append(message, entryMessage(versionDatas, result)...)
+			message = append(message, replacementMessage(versionDatas, resultPkg, resultSymbol, result[2])...)
+			message = append(message, aliasMessage(versionDatas, resultPkg, resultSymbol)...)
+			message = append(message, supportMessage(versionDatas, resultPkg, resultSymbol, result[1])...)
+			fmt.Fprintln(w, append(message, "-", versionDatas.DocUrl(resultPkg, resultSymbol))...)
+			printSummary(w, versionDatas, resultPkg, resultSymbol)
+			answer.Package, answer.Symbol, answer.AddedIn = resultPkg, resultSymbol, result[1]
+		default:
+			if options.Suggestions == 0 {
+				fprintError(w, err)
+				answer.Status = exitNo
+				return answer
+			}
+
+			switch options.Format {
+			case formatJson:
+				printResults(w, versionDatas, results, options.Suggestions)
+				return answer
+			case formatQuickfix:
+				printQuickfix(w, versionDatas, results, options.Suggestions)
+				return answer
+			}
+
+			fmt.Fprintln(w, tr(severalFound))
+			hidden := 0
+			if options.Suggestions > 0 && len(results) > options.Suggestions {
+				results, hidden = results[:options.Suggestions], len(results)-options.Suggestions
+			}
+			for _, result := range results {
+				resultPkg, resultSymbol, _ := strings.Cut(result[0], " ")
+				fmt.Fprintln(w, append(entryMessage(versionDatas, result), "-", versionDatas.DocUrl(resultPkg, resultSymbol))...)
+			}
+			if hidden != 0 {
+				fmt.Fprintf(w, tr(moreHidden)+"\n", hidden)
+			}
+		}
+		return answer
+	}
+
+	entry := pkg
+	if symbol != "" {
+		entry += " " + versionDatas.SymbolName(pkg, symbol)
+	}
+	switch options.Format {
+	case formatJson:
+		printResults(w, versionDatas, [][3]string{{entry, symbolData[0], symbolData[1]}}, 0)
+		return answer
+	case formatQuickfix:
+		printQuickfix(w, versionDatas, [][3]string{{entry, symbolData[0], symbolData[1]}}, 0)
+		return answer
+	}
+
+	message := sinceMessage(versionDatas, pkg, symbol, symbolData, versionDatas.Platforms(pkg, symbol))
+	message = append(message, memberMessage(versionDatas, pkg, symbol)...)
+	message = append(message, replacementMessage(versionDatas, pkg, symbol, symbolData[1])...)
+	message = append(message, aliasMessage(versionDatas, pkg, symbol)...)
+	message = append(message, supportMessage(versionDatas, pkg, symbol, symbolData[0])...)
+	fmt.Fprintln(w, append(message, "-", versionDatas.DocUrl(pkg, symbol))...)
+	printSummary(w, versionDatas, pkg, symbol)
+	answer.AddedIn = symbolData[0]
+	return answer
+}
+
+// Print the first sentence of the doc comment (when loaded with --summaries).
+func printSummary(w io.Writer, versionDatas versiondb.VersionDatas, pkg string, symbol string) {
+	if summary := versionDatas.Summary(pkg, symbol); summary != "" {
+		fmt.Fprintln(w, " ", summary)
+	}
+}
@@ -0,0 +1,34 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import "github.com/spf13/cobra"
+
+// The quick lookup is the root command under an explicit name, sharing its flags (useful in scripts
+// and aliases, where an expression could be mistaken for a command).
+func initQuick(root *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "q expr1 [expr2]",
+		Short: "Show the introducing version of a go package or symbol (same as gosince expr1 [expr2]).",
+		Args:  root.Args,
+		Run:   root.Run,
+	}
+	cmd.Flags().AddFlagSet(root.Flags())
+	return cmd
+}
@@ -0,0 +1,175 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dvaumoron/gosince/analysis"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolUri      = "https://github.com/dvaumoron/gosince"
+)
+
+// Kind of finding reported by an analysis command.
+type reportRule struct {
+	id          string
+	description string
+	level       string // SARIF level : "error", "warning" or "note"
+}
+
+var (
+	deprecatedRule = reportRule{id: "deprecated-api", description: "Use of deprecated standard library api", level: "warning"}
+	newerRule      = reportRule{id: "newer-api", description: "Use of standard library api newer than the target go version", level: "error"}
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	Uri       string `json:"uri"`
+	UriBaseId string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// Locations are relative to root (the %SRCROOT% base), those outside of it are absolute file uris.
+func buildSarif(usages []analysis.Usage, root string, rule reportRule, message func(analysis.Usage) string) sarifLog {
+	results := make([]sarifResult, 0, len(usages))
+	for _, usage := range usages {
+		artifact := sarifArtifact(root, usage.Position.Filename)
+
+		results = append(results, sarifResult{
+			RuleId: rule.id, Level: rule.level, Message: sarifMessage{Text: message(usage)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: artifact,
+				Region:           sarifRegion{StartLine: usage.Position.Line, StartColumn: usage.Position.Column},
+			}}},
+		})
+	}
+
+	driver := sarifDriver{
+		Name: "gosince", InformationUri: toolUri, Version: toolVersion,
+		Rules: []sarifRule{{Id: rule.id, ShortDescription: sarifMessage{Text: rule.description}}},
+	}
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}}}
+}
+
+// Return the location of fileName, relative to root when it is inside it.
+func sarifArtifact(root string, fileName string) sarifArtifactLocation {
+	absName, err := filepath.Abs(fileName)
+	if err != nil {
+		return sarifArtifactLocation{Uri: filepath.ToSlash(fileName)}
+	}
+
+	if relName, err := filepath.Rel(root, absName); err == nil && filepath.IsLocal(relName) {
+		return sarifArtifactLocation{Uri: (&url.URL{Path: filepath.ToSlash(relName)}).String(), UriBaseId: "%SRCROOT%"}
+	}
+
+	slashed := filepath.ToSlash(absName)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed // windows drive, like "/C:/src/main.go"
+	}
+	return sarifArtifactLocation{Uri: (&url.URL{Scheme: "file", Path: slashed}).String()}
+}
+
+// Return the root of the repository containing dir (the first parent with a .git entry),
+// else the one of its module (the first parent with a go.mod file), else dir itself.
+func sourceRoot(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+
+	moduleRoot := ""
+	for current := absDir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		if moduleRoot == "" {
+			if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+				moduleRoot = current
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	if moduleRoot != "" {
+		return moduleRoot
+	}
+	return absDir
+}
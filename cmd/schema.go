@@ -0,0 +1,151 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import "fmt"
+
+// Version of the json outputs, increased on incompatible changes only (a field removed, renamed or of another type),
+// new fields can be added without increase.
+const jsonSchemaVersion = 1
+
+const schemaVersionProperty = `"schema_version": {"const": 1, "description": "increased on incompatible changes only"}`
+
+const errorProperty = `"error": {
+      "type": "object",
+      "description": "present instead of the answer on failure",
+      "required": ["code", "message"],
+      "properties": {
+        "code": {"enum": ["failure", "network", "no_target", "unavailable_platform", "unknown_package", "unknown_symbol", "unknown_version"]},
+        "message": {"type": "string"}
+      }
+    }`
+
+const lookupSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "gosince lookup",
+  "type": "object",
+  "required": ["schema_version"],
+  "oneOf": [{"required": ["results"]}, {"required": ["error"]}],
+  "properties": {
+    ` + schemaVersionProperty + `,
+    ` + errorProperty + `,
+    "results": {
+      "type": "array",
+      "description": "the answer, or the possibilities found when the query is not found exactly",
+      "items": {
+        "type": "object",
+        "required": ["package", "added_in", "doc_url"],
+        "properties": {
+          "package": {"type": "string"},
+          "symbol": {"type": "string", "description": "absent for a package"},
+          "added_in": {"type": "string"},
+          "deprecated_in": {"type": "string"},
+          "replacement": {"type": "string", "description": "advice for deprecated api, (none) when there is no replacement"},
+          "platforms": {"type": "array", "items": {"type": "string"}, "description": "absent when available on all platforms"},
+          "summary": {"type": "string", "description": "first sentence of the doc comment, with --summaries"},
+          "doc_url": {"type": "string"}
+        }
+      }
+    },
+    "hidden": {"type": "integer", "description": "number of results beyond the --suggestions limit"}
+  }
+}`
+
+const usagesSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "gosince usages",
+  "type": "object",
+  "required": ["schema_version"],
+  "oneOf": [{"required": ["usages"]}, {"required": ["error"]}],
+  "properties": {
+    ` + schemaVersionProperty + `,
+    ` + errorProperty + `,
+    "usages": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["file", "line", "column", "package", "added_in"],
+        "properties": {
+          "file": {"type": "string", "description": "relative to the working directory when possible"},
+          "line": {"type": "integer"},
+          "column": {"type": "integer"},
+          "package": {"type": "string"},
+          "symbol": {"type": "string", "description": "absent for a package import"},
+          "added_in": {"type": "string"},
+          "deprecated_in": {"type": "string"},
+          "replacement": {"type": "string", "description": "advice for deprecated api, (none) when there is no replacement"},
+          "configs": {"type": "array", "items": {"type": "string"}, "description": "analyzed configurations where the use is found, absent when found in all"}
+        }
+      }
+    }
+  }
+}`
+
+const blockersSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "gosince blockers",
+  "type": "object",
+  "required": ["schema_version"],
+  "oneOf": [{"required": ["blockers"]}, {"required": ["error"]}],
+  "properties": {
+    ` + schemaVersionProperty + `,
+    ` + errorProperty + `,
+    "blockers": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["package", "added_in", "symbols"],
+        "properties": {
+          "package": {"type": "string"},
+          "added_in": {"type": "string", "description": "most recent version needed by the uses of the package"},
+          "symbols": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "required": ["name", "added_in", "positions"],
+              "properties": {
+                "name": {"type": "string"},
+                "added_in": {"type": "string"},
+                "positions": {"type": "array", "items": {"type": "string"}}
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+type usagesOutput struct {
+	SchemaVersion int           `json:"schema_version"`
+	Usages        []usageOutput `json:"usages"`
+}
+
+type blockersOutput struct {
+	SchemaVersion int             `json:"schema_version"`
+	Blockers      []blockerOutput `json:"blockers"`
+}
+
+// Print schema when asked with --schema, return true when printed.
+func (options *profileOptions) printSchema(schema string) bool {
+	if options.schema {
+		fmt.Println(schema)
+	}
+	return options.schema
+}
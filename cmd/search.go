@@ -0,0 +1,138 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dvaumoron/gosince/pkgdoc"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+var errSearchCriteria = errors.New("search needs a name, a --param or a --returns")
+
+func initSearch() *cobra.Command {
+	var params, returns []string
+	sortOrder := versiondb.SortVersion
+	docs := false
+	limit := defaultSuggestions
+
+	cmd := &cobra.Command{
+		Use:     "search [name]",
+		Aliases: []string{"s"},
+		Short:   "List the packages and symbols with a matching name or signature.",
+		Long: `List the packages and symbols with a matching name or signature.
+
+With --param or --returns, only functions and methods having all the given types (as written in Go code, like context.Context) in their parameters or results are listed, the name is then optional.
+With --docs, the words are searched in the documentation comments instead, the most relevant answers first
+(the package sources are downloaded on the first use, in a single archive with the GitHub source, see also warm --doc).
+Exit with status 1 when nothing matches and 2 on other failures.
+`,
+		Args: cobra.RangeArgs(0, 1),
+		Run: func(_ *cobra.Command, args []string) {
+			name := ""
+			if len(args) != 0 {
+				name = args[0]
+			} else if len(params) == 0 && len(returns) == 0 {
+				printError(errSearchCriteria)
+				os.Exit(exitError)
+			}
+
+			checkSortOrder(sortOrder)
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			if docs {
+				if err := printDocMatches(versionDatas, name, limit); err != nil {
+					printError(err)
+					os.Exit(exitStatus(err))
+				}
+				return
+			}
+
+			var results [][3]string
+			if len(params) == 0 && len(returns) == 0 {
+				results = versionDatas.Search(name)
+			} else {
+				var err error
+				if results, err = versionDatas.SearchSignature(name, params, returns); err != nil {
+					printError(err)
+					os.Exit(exitStatus(err))
+				}
+			}
+
+			results = versionDatas.FilterPlatform(results, targetOs, targetArch)
+			if len(results) == 0 {
+				printError(versiondb.ErrUnknownSymbol)
+				os.Exit(exitNo)
+			}
+			printSortedEntries(versionDatas, results, sortOrder)
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.StringArrayVar(&params, "param", nil, "Type expected in parameters (repeatable)")
+	cmdFlags.StringArrayVar(&returns, "returns", nil, "Type expected in results (repeatable)")
+	cmdFlags.BoolVar(&docs, "docs", false, "Search the words in the documentation comments (like \"constant-time comparison\")")
+	cmdFlags.IntVar(&limit, "limit", limit, "With --docs, maximum number of listed answers (0 or negative for all)")
+	addSortFlag(cmdFlags, &sortOrder)
+
+	return cmd
+}
+
+// Print the packages and symbols whose documentation best matches the words of query, at most limit when positive.
+func printDocMatches(versionDatas versiondb.VersionDatas, query string, limit int) error {
+	index, err := pkgdoc.LoadIndex(conf, versionDatas.Packages())
+	if err != nil {
+		return err
+	}
+
+	var results [][3]string
+	for _, match := range index.Search(query) {
+		if limit > 0 && len(results) == limit {
+			break
+		}
+
+		versions, err := versionDatas.SinceOn(match.Package, match.Symbol, targetOs, targetArch)
+		if err != nil {
+			continue // not in the api files (like the methods of an unexported type) or not on the platform
+		}
+
+		entry := match.Package
+		if match.Symbol != "" {
+			entry += " " + match.Symbol
+		}
+		results = append(results, [3]string{entry, versions[0], versions[1]})
+	}
+	if len(results) == 0 {
+		return versiondb.ErrUnknownSymbol
+	}
+
+	for _, result := range results {
+		pkg, symbol, _ := strings.Cut(result[0], " ")
+		fmt.Println(append(entryMessage(versionDatas, result), "-", versionDatas.DocUrl(pkg, symbol))...)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dvaumoron/gosince/selfupdate"
+	"github.com/spf13/cobra"
+)
+
+var errDevBuild = errors.New("update failure : the current binary is a dev build, use --force to replace it")
+
+func initSelfUpdate() *cobra.Command {
+	check := false
+	force := false
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Replace gosince by the binary of the latest release.",
+		Long: `Check the latest GitHub release, download the archive for the current platform, verify its checksum
+and replace the running binary (for installations from a prebuilt binary, prefer the package manager otherwise).
+
+With --check, only report whether a newer release exists (exit with status 1 when there is one).
+The versions are compared as semantic versions, a dev build is only replaced with --force.
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			release, err := selfupdate.Latest(selfupdate.DefaultReleasesUrl, nil)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			if !release.Newer(toolVersion) && !force {
+				fmt.Println("gosince", toolVersion, "is up to date")
+				return
+			}
+			if check {
+				fmt.Println("gosince", release.Version(), "is available (current is", toolVersion+")")
+				os.Exit(exitNo)
+			}
+			if !selfupdate.Released(toolVersion) && !force {
+				fmt.Println(errDevBuild)
+				os.Exit(exitError)
+			}
+
+			if err = release.Install(); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+			fmt.Println("gosince updated to", release.Version())
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.BoolVar(&check, "check", false, "Only report whether a newer release exists")
+	cmdFlags.BoolVar(&force, "force", false, "Install the latest release even when it is not newer than the current version, or over a dev build")
+
+	return cmd
+}
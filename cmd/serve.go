@@ -0,0 +1,266 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/notify"
+	"github.com/dvaumoron/gosince/server"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultGrpcAddr = "localhost:9090"
+
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = time.Minute
+	writeTimeout      = 10 * time.Minute // analyze queries load packages
+	idleTimeout       = 2 * time.Minute
+)
+
+var errLoadFailure = errors.New("server stopped : database loading failure")
+
+func initServe() *cobra.Command {
+	addr := "localhost:8080"
+	grpcAddr := ""
+	apiKeysFile := ""
+	webhooksFile := ""
+	var allowedOrigins, analyzeRoots []string
+	rateLimit := 0
+	var cacheMaxAge, refreshInterval, shutdownTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the api history over HTTP (and gRPC).",
+		Long: `Serve the api history over HTTP with a JSON api (/api/since, /api/search, /api/diff, /api/list, /api/versions and /api/analyze),
+a GraphQL endpoint (/graphql) with Package, Symbol and Release types, a web UI (/),
+an Atom feed (/feed.atom) of the api added in api/next and in the latest release, and Prometheus metrics (/metrics).
+
+The analyze queries are refused unless --analyze-root is set, their directory must then be under one of the roots.
+With --api-keys-file or ` + config.EnvApiKeys + `, the queries require a key (in the X-Api-Key header or as a Bearer token),
+each key is rate limited (see --rate-limit, the file can give a limit by key) and only the keys marked "analyze"
+in the file can analyze. /metrics, /healthz and /readyz stay unauthenticated.
+The server checks periodically (see --refresh) for the api file of a new Go release and reloads the database when one appears,
+then the webhooks declared in --webhooks-file (Slack, Discord or generic JSON) receive the api added in their subscribed packages.
+The health (/healthz) and readiness (/readyz) endpoints allow deployment on Kubernetes, the server is ready
+once the database is loaded and stops gracefully on SIGTERM.
+With --grpc, the gRPC service described in gosincepb/gosince.proto is also served (on ` + defaultGrpcAddr + ` by default).
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			srv := server.New()
+			apiKeys := strings.FieldsFunc(os.Getenv(config.EnvApiKeys), func(r rune) bool { return r == ',' })
+			if len(apiKeys) != 0 || apiKeysFile != "" {
+				auth, err := server.NewAuthenticator(apiKeys, apiKeysFile, rateLimit)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				srv.SetAuthenticator(auth)
+			}
+			var notifier notify.Notifier
+			if webhooksFile != "" {
+				webhooks, err := config.LoadWebhooks(webhooksFile)
+				if err == nil {
+					notifier, err = notify.New(webhooks, nil)
+				}
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+			}
+			if err := srv.SetAnalyzeRoots(analyzeRoots); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+			srv.SetAllowedOrigins(allowedOrigins)
+			srv.SetCacheMaxAge(cacheMaxAge)
+
+			handler, err := srv.Handler()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			errs := make(chan error, 3)
+			var grpcServer *grpc.Server
+			if grpcAddr != "" {
+				listener, err := net.Listen("tcp", grpcAddr)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+
+				grpcServer = grpc.NewServer(grpc.UnaryInterceptor(srv.UnaryInterceptor))
+				srv.RegisterGrpc(grpcServer)
+				fmt.Println("Serve gRPC on", grpcAddr)
+				go func() {
+					errs <- grpcServer.Serve(listener)
+				}()
+			}
+
+			httpServer := &http.Server{
+				Addr: addr, Handler: handler, ReadHeaderTimeout: readHeaderTimeout,
+				ReadTimeout: readTimeout, WriteTimeout: writeTimeout, IdleTimeout: idleTimeout,
+			}
+			fmt.Println("Serve HTTP on", addr)
+			go func() {
+				errs <- httpServer.ListenAndServe()
+			}()
+
+			go func() { // ready once loaded (see /readyz)
+				versionDatas, ok := loadDatas()
+				if !ok {
+					errs <- errLoadFailure
+					return
+				}
+				srv.SetDatas(versionDatas)
+				fmt.Println("Database loaded")
+				loadNext(srv, versionDatas)
+
+				if refreshInterval > 0 {
+					refreshDatas(ctx, srv, notifier, versionDatas, refreshInterval)
+				}
+			}()
+
+			select {
+			case err = <-errs:
+				fmt.Println(err)
+				os.Exit(exitError)
+			case <-ctx.Done():
+			}
+
+			fmt.Println("Shutting down")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			if grpcServer != nil {
+				go func() {
+					<-shutdownCtx.Done()
+					grpcServer.Stop() // force when graceful stop is too long
+				}()
+				grpcServer.GracefulStop()
+			}
+			if err = httpServer.Shutdown(shutdownCtx); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.StringArrayVar(&analyzeRoots, "analyze-root", nil, "Directory allowed (with its subdirectories) in the analyze queries (repeatable), they are refused when none is set")
+	cmdFlags.StringVar(&apiKeysFile, "api-keys-file", "", "File of accepted api key hashes (sha256 hex), each optionally followed by its rate limit and \"analyze\"")
+	cmdFlags.DurationVar(&cacheMaxAge, "cache-max-age", 0, "Duration the GET answers can be cached without revalidation (their ETag)")
+	cmdFlags.StringSliceVar(&allowedOrigins, "cors-origin", nil, "Origins allowed to query from a browser (* for any)")
+	cmdFlags.IntVar(&rateLimit, "rate-limit", 0, "Queries per minute allowed for each api key, 0 for unlimited")
+	cmdFlags.StringVar(&addr, "addr", addr, "Address of the HTTP server")
+	cmdFlags.StringVar(&grpcAddr, "grpc", "", "Address of the gRPC server, disabled when empty")
+	cmdFlags.Lookup("grpc").NoOptDefVal = defaultGrpcAddr
+	cmdFlags.DurationVar(&refreshInterval, "refresh", 24*time.Hour, "Interval between checks for a new Go release (and reloads of api/next), 0 to disable")
+	cmdFlags.StringVar(&webhooksFile, "webhooks-file", "", "YAML file of the webhooks notified of the api added by a new Go release")
+	cmdFlags.DurationVar(&shutdownTimeout, "shutdown-timeout", 10*time.Second, "Maximum wait for the running queries on SIGTERM")
+
+	return cmd
+}
+
+// Check every interval for a new Go release after the latest one of versionDatas, and swap the database of srv
+// when one is found (then notifier sends the api added by the release), api/next is reloaded after each check.
+func refreshDatas(ctx context.Context, srv *server.Server, notifier notify.Notifier, versionDatas versiondb.VersionDatas, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		versionDatas = refreshRelease(ctx, srv, notifier, versionDatas)
+		loadNext(srv, versionDatas)
+	}
+}
+
+// Return the new database when a Go release after the latest one of versionDatas is found, else versionDatas.
+func refreshRelease(ctx context.Context, srv *server.Server, notifier notify.Notifier, versionDatas versiondb.VersionDatas) versiondb.VersionDatas {
+	previous := versionDatas.LatestVersion()
+	found, err := versiondb.FetchNextVersion(conf, previous)
+	if err != nil {
+		fmt.Println("Refresh failure :", err)
+		return versionDatas
+	}
+	if !found {
+		return versionDatas
+	}
+
+	newDatas, err := versiondb.LoadDatas(conf)
+	if err != nil {
+		fmt.Println("Refresh failure :", err)
+		return versionDatas
+	}
+
+	srv.SetDatas(newDatas)
+	latest := newDatas.LatestVersion()
+	fmt.Println("Database refreshed, latest version is", latest)
+
+	entries, err := newDatas.Diff(previous, latest, "")
+	if err == nil {
+		err = versiondb.SortEntries(entries, versiondb.SortPackage)
+	}
+	if err == nil {
+		err = notifier.Notify(ctx, latest, entries)
+	}
+	if err != nil {
+		fmt.Println("Notification failure :", err)
+	}
+	return newDatas
+}
+
+// Give the api of the next Go release to srv (for its feed), with the api withdrawn from api/next
+// before appearing in the releases of versionDatas, the failure is only printed.
+func loadNext(srv *server.Server, versionDatas versiondb.VersionDatas) {
+	files, err := versiondb.LoadNext(conf)
+	if err != nil {
+		fmt.Println("Failed to load api/next :", err)
+		return
+	}
+
+	withdrawn, err := versiondb.RecordNext(conf, files, versionDatas)
+	if err != nil {
+		fmt.Println("Failed to record api/next :", err)
+	}
+	srv.SetNext(files, withdrawn)
+}
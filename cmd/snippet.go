@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/spf13/cobra"
+)
+
+func initSnippet() *cobra.Command {
+	return &cobra.Command{
+		Use:   "snippet [file]",
+		Short: "Show the minimum go version needed by a code fragment.",
+		Long: `Show the minimum go version needed by a code fragment and the version of each standard library api it uses.
+
+The fragment can be a complete file, declarations or statements (wrapped in a synthetic main), missing imports are added.
+Without argument (or with -), the fragment is read from the standard input.
+`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			var src []byte
+			var err error
+			if len(args) == 0 || args[0] == "-" {
+				src, err = io.ReadAll(os.Stdin)
+			} else {
+				src, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			usages, err := analysis.Snippet(versionDatas, src, targetOs, targetArch)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			seen := map[string]struct{}{}
+			for _, usage := range usages {
+				name := usage.Name()
+				if _, ok := seen[name]; ok {
+					continue
+				}
+				seen[name] = struct{}{}
+				fmt.Println(append([]any{name}, sinceMessage(versionDatas, usage.Package, usage.Symbol, [2]string{usage.AddedIn, usage.DeprecatedIn}, nil)...)...)
+			}
+			printMinimum(usages, nil)
+		},
+	}
+}
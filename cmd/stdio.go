@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/stdio"
+	"github.com/spf13/cobra"
+)
+
+func initStdio() *cobra.Command {
+	var tags []string
+
+	cmd := &cobra.Command{
+		Use:   "stdio",
+		Short: "Answer newline delimited JSON requests on stdin, for editor plugins.",
+		Long: `Answer newline delimited JSON requests read on stdin with JSON responses written on stdout,
+the database is loaded once for the session. The methods are :
+
+  {"id":1,"method":"lookup","expr":"net/http.Client.Do"}
+  {"id":2,"method":"search","query":"Join","limit":10}
+  {"id":3,"method":"search","query":"Buffer.Wr","prefix":true}
+  {"id":4,"method":"resolve","file":"main.go","line":12,"column":21}
+
+A response repeats the id with either a "results" list or an "error" object with a stable "code".
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.Verbose = false // stdout is reserved to the protocol
+			conf.Progress = nil
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			analysisConf := analysis.Config{Tags: tags}
+			if targetOs != "" || targetArch != "" {
+				analysisConf.Platforms = []string{cmp.Or(targetOs, runtime.GOOS) + "/" + cmp.Or(targetArch, runtime.GOARCH)}
+			}
+
+			if err := stdio.Serve(versionDatas, analysisConf, os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitError)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Build tags")
+
+	return cmd
+}
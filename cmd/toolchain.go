@@ -0,0 +1,53 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+// Warn when version is newer than the local toolchain (silently ignored when there is no go command).
+func checkToolchain(version string) {
+	localVersion := localGoVersion()
+	if localVersion == "" || versiondb.MinorVersion(localVersion) == -1 {
+		return
+	}
+
+	if versiondb.CompareVersion(version, localVersion) > 0 {
+		fmt.Printf(tr(localToolchain)+"\n", localVersion)
+	}
+}
+
+// Return the version of the toolchain selected by the go command (GOTOOLCHAIN is taken into account),
+// the go command is run once per process.
+var localGoVersion = sync.OnceValue(func() string {
+	output, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		if conf.Verbose {
+			fmt.Println("Failed to retrieve local go version :", err)
+		}
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+})
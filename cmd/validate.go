@@ -0,0 +1,66 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+func initValidate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate file1 [file2...]",
+		Short: "Report the structural problems of api files (like api/next/*.txt).",
+		Long: `Report the structural problems of api files (like api/next/*.txt) with their line numbers :
+malformed and duplicate lines, and lines without proposal issue in files named after an issue.
+
+Exit with status 1 when a problem is found and 2 on other failures.
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			problemCount := 0
+			for _, fileName := range args {
+				data, err := os.ReadFile(fileName)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+
+				diagnostics, err := versiondb.Validate(fileName, data)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+
+				for _, diagnostic := range diagnostics {
+					fmt.Println(diagnostic)
+				}
+				problemCount += len(diagnostics)
+			}
+
+			if problemCount != 0 {
+				fmt.Println(problemCount, "problems found")
+				os.Exit(exitNo)
+			}
+		},
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/analyzer"
+	"github.com/spf13/cobra"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+type positionedMessage struct {
+	position token.Position
+	message  string
+}
+
+func initVet() *cobra.Command {
+	var target string
+	var ignore, tags []string
+
+	cmd := &cobra.Command{
+		Use:   "vet [packages]",
+		Short: "Run the gosince analyzers like go vet does.",
+		Long: `Run the gosince analyzers (gosince for newer api, gosincedeprecated for deprecated api) like go vet does.
+
+Packages default to ./... and the target version to the go directive of the module.
+The gosince binary can also be used directly as go vet tool : go vet -vettool=$(which gosince) ./...
+Exit with status 1 when a problem is found and 2 on other failures.
+`,
+		Run: func(_ *cobra.Command, args []string) {
+			if len(args) == 0 {
+				args = []string{"./..."}
+			}
+
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			analyzer.SetDatas(versionDatas)
+			if err := setAnalyzerFlags(target, strings.Join(ignore, ",")); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			pkgs, err := analysis.LoadPackages(analysis.Config{Dir: ".", Tags: tags, Verbose: conf.Verbose}, "", args...)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			graph, err := checker.Analyze(analyzer.Analyzers(), pkgs, nil)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			failed := false
+			var diagnostics []positionedMessage
+			for _, action := range graph.Roots {
+				if action.Err != nil {
+					fmt.Println(action.Package.PkgPath, ":", action.Err)
+					failed = true
+					continue
+				}
+
+				for _, diagnostic := range action.Diagnostics {
+					diagnostics = append(diagnostics, positionedMessage{
+						position: action.Package.Fset.Position(diagnostic.Pos), message: diagnostic.Message,
+					})
+				}
+			}
+
+			slices.SortFunc(diagnostics, func(a positionedMessage, b positionedMessage) int {
+				if res := strings.Compare(a.position.Filename, b.position.Filename); res != 0 {
+					return res
+				}
+				return a.position.Offset - b.position.Offset
+			})
+			for _, diagnostic := range diagnostics {
+				position := diagnostic.position
+				fmt.Printf("%s:%d:%d: %s\n", relativePath(position.Filename), position.Line, position.Column, diagnostic.message)
+			}
+
+			switch {
+			case failed:
+				os.Exit(exitError)
+			case len(diagnostics) != 0:
+				os.Exit(exitNo)
+			}
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.StringVar(&target, "go", "", "Target go version")
+	cmdFlags.StringSliceVar(&ignore, "ignore", nil, "Patterns of symbols (pkg.Symbol) to ignore")
+	cmdFlags.StringSliceVar(&tags, "tags", nil, "Build tags")
+
+	return cmd
+}
+
+func setAnalyzerFlags(target string, ignore string) error {
+	if err := analyzer.MinVersion.Flags.Set("go", target); err != nil {
+		return err
+	}
+	for _, a := range analyzer.Analyzers() {
+		if err := a.Flags.Set("ignore", ignore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dvaumoron/gosince/pkgdoc"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+func initWarm() *cobra.Command {
+	withDoc := false
+
+	cmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Download every available api file into the local cache.",
+		Long: `Download every available api file into the local cache, in one go (intended for container image builds and first-run provisioning).
+
+With --doc, the GODEBUG history and the package sources used by the documentation are downloaded too.
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			count := len(versionDatas.Versions())
+			fmt.Println(count, "api files cached in", conf.RepoPath, "("+fmt.Sprint(count-versionDatas.LoadStats().CacheHits), "downloaded)")
+			if !withDoc {
+				return
+			}
+
+			failed := false
+			if _, err := versiondb.LoadGodebug(conf); err != nil {
+				fmt.Println(err)
+				failed = true
+			}
+
+			// first for search --docs, it downloads the sources in a single archive when possible
+			if _, err := pkgdoc.LoadIndex(conf, versionDatas.Packages()); err != nil {
+				fmt.Println(err)
+				failed = true
+			}
+
+			count = 0
+			for _, pkg := range versionDatas.Packages() {
+				if _, err := pkgdoc.Load(conf, pkg); err != nil {
+					fmt.Println(pkg, ":", err)
+					failed = true
+					continue
+				}
+				count++
+			}
+			fmt.Println("sources of", count, "packages cached")
+
+			if failed {
+				os.Exit(exitError)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&withDoc, "doc", false, "Download the documentation data too (GODEBUG history and package sources)")
+
+	return cmd
+}
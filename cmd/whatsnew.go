@@ -0,0 +1,224 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/features"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/spf13/cobra"
+)
+
+// Additions of a release to a package existing before it.
+type packageAdditions struct {
+	notable []string // functions, types and methods
+	others  int      // constants, variables and fields
+}
+
+// Changes of a release, derived from the api files.
+type releaseSummary struct {
+	newPackages  map[string]int                          // number of symbols by new package
+	additions    map[string]map[string]*packageAdditions // by area (first element of the package path) then package
+	deprecations [][2]string                             // deprecated packages and symbols (empty for a package), by package then name
+}
+
+func initWhatsnew() *cobra.Command {
+	limit := defaultSuggestions
+	withNotes := false
+
+	cmd := &cobra.Command{
+		Use:   "whatsnew version",
+		Short: "Summarize the changes of a release (language, new packages, additions by area and deprecations).",
+		Long: `Summarize the changes of a release (like go1.23) : the language features, the new packages,
+the functions, types and methods added to existing packages grouped by area, and the deprecations with their replacement.
+
+With --notes, the release notes are downloaded (and cached) to add the first sentence of their sections.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			versionDatas, ok := loadDatas()
+			if !ok {
+				os.Exit(exitError)
+			}
+
+			version := versiondb.NormalizeVersion(args[0])
+			if !slices.Contains(versionDatas.Versions(), version) {
+				printError(versiondb.ErrUnknownVersion)
+				os.Exit(exitError)
+			}
+
+			summary, err := summarizeRelease(versionDatas, version)
+			if err != nil {
+				printError(err)
+				os.Exit(exitError)
+			}
+
+			var notes versiondb.ReleaseNotes
+			if withNotes {
+				if notes, err = versiondb.LoadReleaseNotes(conf, version); err != nil {
+					fmt.Println(err)
+				}
+			}
+			printWhatsnew(versionDatas, version, summary, notes, limit)
+		},
+	}
+
+	cmdFlags := cmd.Flags()
+	cmdFlags.IntVar(&limit, "limit", defaultSuggestions, "Maximum number of additions listed by package (negative for all)")
+	cmdFlags.BoolVar(&withNotes, "notes", false, "Add the first sentence of the release notes sections")
+
+	return cmd
+}
+
+func summarizeRelease(versionDatas versiondb.VersionDatas, version string) (releaseSummary, error) {
+	summary := releaseSummary{newPackages: map[string]int{}, additions: map[string]map[string]*packageAdditions{}}
+	err := versionDatas.Walk(versiondb.Filter{From: version, To: version}, func(entry versiondb.Entry) bool {
+		if entry.Kind == versiondb.KindPackage {
+			summary.newPackages[entry.Package] = 0
+			return true
+		}
+		if count, ok := summary.newPackages[entry.Package]; ok {
+			summary.newPackages[entry.Package] = count + 1
+			return true
+		}
+
+		area, _, _ := strings.Cut(entry.Package, "/")
+		byPackage := summary.additions[area]
+		if byPackage == nil {
+			byPackage = map[string]*packageAdditions{}
+			summary.additions[area] = byPackage
+		}
+		additions := byPackage[entry.Package]
+		if additions == nil {
+			additions = &packageAdditions{}
+			byPackage[entry.Package] = additions
+		}
+
+		switch entry.Kind {
+		case versiondb.KindFunc, versiondb.KindMethod, versiondb.KindType:
+			additions.notable = append(additions.notable, entry.Name)
+		default:
+			additions.others++
+		}
+		return true
+	})
+	if err != nil {
+		return releaseSummary{}, err
+	}
+
+	err = versionDatas.Walk(versiondb.Filter{Deprecated: true}, func(entry versiondb.Entry) bool {
+		if entry.DeprecatedIn == version {
+			summary.deprecations = append(summary.deprecations, [2]string{entry.Package, entry.Name})
+		}
+		return true
+	})
+	return summary, err
+}
+
+func printWhatsnew(versionDatas versiondb.VersionDatas, version string, summary releaseSummary, notes versiondb.ReleaseNotes, limit int) {
+	fmt.Println("Go", strings.TrimPrefix(version, "go"), "-", versiondb.NotesUrl(version))
+
+	all, err := features.All()
+	if err != nil {
+		fmt.Println(err)
+	}
+	printedTitle := false
+	for _, feature := range all {
+		if feature.Version != version {
+			continue
+		}
+		if !printedTitle {
+			fmt.Println("\n" + tr(languageTitle))
+			printedTitle = true
+		}
+		fmt.Println(" ", feature.Name, ":", feature.Description)
+	}
+
+	printedTitle = false
+	for _, section := range notes.Sections {
+		sentence := section.Summary()
+		if section.Package != "" || sentence == "" {
+			continue
+		}
+		if !printedTitle {
+			fmt.Println("\n" + tr(notesTitle))
+			printedTitle = true
+		}
+		fmt.Println(" ", section.Title, ":", sentence)
+	}
+
+	if len(summary.newPackages) != 0 {
+		fmt.Println("\n" + tr(newPackagesTitle))
+		for _, pkg := range slices.Sorted(maps.Keys(summary.newPackages)) {
+			fmt.Println(" ", pkg, fmt.Sprintf(tr(symbolCount), summary.newPackages[pkg]))
+		}
+	}
+
+	if len(summary.additions) != 0 {
+		fmt.Println("\n" + tr(additionsTitle))
+		for _, area := range slices.Sorted(maps.Keys(summary.additions)) {
+			fmt.Println(" ", area, ":")
+			byPackage := summary.additions[area]
+			for _, pkg := range slices.Sorted(maps.Keys(byPackage)) {
+				fmt.Println("   ", pkg, ":", additionsMessage(byPackage[pkg], limit))
+				for _, section := range notes.Package(pkg) {
+					if sentence := section.Summary(); sentence != "" {
+						fmt.Println("     ", sentence)
+					}
+				}
+			}
+		}
+	}
+
+	if len(summary.deprecations) != 0 {
+		fmt.Println("\n" + tr(deprecationsTitle))
+		for _, deprecation := range summary.deprecations {
+			pkg, symbol := deprecation[0], deprecation[1]
+			name := pkg
+			if symbol != "" {
+				name += "." + symbol
+			}
+			fmt.Println(append([]any{" ", name}, replacementMessage(versionDatas, pkg, symbol, version)...)...)
+		}
+	}
+}
+
+// Return the notable names (limit of them, all when negative), followed by the number of the other additions.
+func additionsMessage(additions *packageAdditions, limit int) string {
+	names := additions.notable
+	hidden := additions.others
+	if limit >= 0 && len(names) > limit {
+		names, hidden = names[:limit], hidden+len(names)-limit
+	}
+
+	message := strings.Join(names, ", ")
+	switch {
+	case hidden == 0:
+	case message == "":
+		message = fmt.Sprintf(tr(otherAdditions), hidden)
+	default:
+		message += fmt.Sprintf(tr(andMore), hidden)
+	}
+	return message
+}
@@ -19,16 +19,89 @@
 package config
 
 import (
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
 	"path"
+	"strings"
+	"time"
 )
 
-const defaultGoSourceUrl = "https://raw.githubusercontent.com/golang/go/master"
+const (
+	EnvApiKeys      = "GOSINCE_API_KEYS"
+	EnvBackportUrl  = "GOSINCE_BACKPORT_URL"
+	EnvCacheArchive = "GOSINCE_CACHE_ARCHIVE"
+	EnvCacheMaxAge  = "GOSINCE_CACHE_MAX_AGE"
+	EnvCachePath    = "GOSINCE_CACHE_PATH"
+	EnvDaemonSocket = "GOSINCE_DAEMON_SOCKET"
+	EnvDocBackend   = "GOSINCE_DOC_BACKEND"
+	EnvGitUrl       = "GOSINCE_GIT_URL"
+	EnvGithubToken  = "GITHUB_TOKEN"
+	EnvLang         = "GOSINCE_LANG"
+	EnvNotesUrl     = "GOSINCE_NOTES_URL"
+	EnvSourceUrl    = "GOSINCE_SOURCE_URL"
+	EnvTargetUrl    = "GOSINCE_TARGET_URL"
+
+	DefaultMaxAge = 7 * 24 * time.Hour
+
+	defaultGoRef       = "master"
+	defaultGoSourceUrl = "https://raw.githubusercontent.com/golang/go/" + defaultGoRef
+	refsDir            = "refs"
+)
+
+var errRefSource = errors.New("ref failure : the source address does not end with the " + defaultGoRef + " ref")
+
+// Called while downloading file (total is -1 when the length is unknown), a last time with done set.
+type ProgressFunc func(file string, read int64, total int64, done bool)
 
 type Config struct {
-	RepoPath  string
-	SourceUrl string
-	Verbose   bool
+	Archive     bool          // cache the api files in a single zstd-compressed archive instead of one file each
+	BackportUrl string        // base location of backports.txt (api backported to point releases), none when empty
+	Client      *http.Client  // used for the downloads, http.DefaultClient when nil
+	Docs        bool          // attach the first sentence of the doc comments, read from the package sources (downloaded when not cached)
+	DocRef      string        // Go source ref of the package sources (like "go1.22.0"), the ref of SourceUrl when empty
+	GitRef      string        // branch, tag or commit sha read from GitUrl, master when empty
+	GitUrl      string        // when set, the api files come from a shallow clone of this repository instead of SourceUrl
+	Logger      *slog.Logger  // receives the verbose messages (at debug level) instead of the standard output when set
+	MaxAge      time.Duration // cached api files older than it are downloaded again (0 keeps them forever)
+	NotesUrl    string        // base location of the release notes (go1.N.md files), the golang/website repository when empty
+	Offline     bool          // never download, only the cached api files are used
+	Progress    ProgressFunc  // nil for none
+	RepoPath    string
+	SourceUrl   string
+	Snapshot    map[string]string // sha256 of the api files to use by file name (like "go1.21.txt"), nil to follow the source
+	Strict      bool              // fail on malformed api file lines instead of skipping them
+	TargetUrl   string            // base location of the data files of the other Go implementations (like tinygo.txt), none when empty
+	Verbose     bool
+}
+
+// Return a copy reading the Go source at ref (like "go1.23rc1", "release-branch.go1.22" or a commit sha)
+// instead of master, cached in a directory of its own.
+func (c Config) WithRef(ref string) (Config, error) {
+	if c.GitUrl != "" {
+		c.GitRef = ref
+		c.RepoPath = path.Join(c.RepoPath, refsDir, ref)
+		return c, nil
+	}
+
+	base, ok := strings.CutSuffix(strings.TrimSuffix(c.SourceUrl, "/"), "/"+defaultGoRef)
+	if !ok {
+		return c, errRefSource
+	}
+
+	c.SourceUrl = base + "/" + ref
+	c.RepoPath = path.Join(c.RepoPath, refsDir, ref)
+	return c, nil
+}
+
+// Return the duration read in the environment variable envMaxAgeName (like "24h"), DefaultMaxAge when unset or invalid.
+func InitMaxAge(envMaxAgeName string) time.Duration {
+	maxAge, err := time.ParseDuration(os.Getenv(envMaxAgeName))
+	if err != nil {
+		return DefaultMaxAge
+	}
+	return maxAge
 }
 
 func InitDefault(envRepoPathName string, envSourceUrlName string) (string, string, error) {
@@ -0,0 +1,98 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultProfileName = "gosince.yaml"
+
+// Project profile, usually checked in as gosince.yaml at the module root.
+type Profile struct {
+	Go           string            `yaml:"go"`           // target go version, default to the go directive of go.mod
+	Platforms    []string          `yaml:"platforms"`    // GOOS/GOARCH pairs like "linux/amd64"
+	Tags         []string          `yaml:"tags"`         // build tags
+	TagSets      []string          `yaml:"tag_sets"`     // comma separated build tag lists, each one analyzed separately
+	Ignore       []string          `yaml:"ignore"`       // path.Match patterns on "pkg.Symbol" or on file path
+	Replacements map[string]string `yaml:"replacements"` // advice for deprecated api by "pkg.Symbol" name (like "io/ioutil.ReadAll"), empty for none
+	Format       string            `yaml:"format"`       // output format
+	Dir          string            `yaml:"-"`            // directory containing the profile
+}
+
+// Read the profile at filePath, a missing file give an empty profile rooted in the current directory.
+func LoadProfile(filePath string) (Profile, error) {
+	profile := Profile{Dir: filepath.Dir(filePath)}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return profile, nil
+		}
+		return profile, err
+	}
+
+	if err = yaml.Unmarshal(data, &profile); err != nil {
+		return profile, err
+	}
+	return profile, nil
+}
+
+// Return the go directive of the go.mod file in dir, or "" if there is none.
+func ModuleGoVersion(dir string) string {
+	modPath := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return ""
+	}
+
+	modFile, err := modfile.ParseLax(modPath, data, nil)
+	if err != nil || modFile.Go == nil {
+		return ""
+	}
+	return modFile.Go.Version
+}
+
+// Rewrite the go directive of the go.mod file in dir to version (like "1.21.0"), the rest of the file is kept.
+func SetModuleGoVersion(dir string, version string) error {
+	modPath := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+
+	modFile, err := modfile.Parse(modPath, data, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = modFile.AddGoStmt(version); err != nil {
+		return err
+	}
+
+	if data, err = modFile.Format(); err != nil {
+		return err
+	}
+	return os.WriteFile(modPath, data, 0644)
+}
@@ -0,0 +1,48 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Webhook notified of the api added by a new Go release.
+type Webhook struct {
+	Kind     string   `yaml:"kind"` // slack, discord or generic (the default)
+	Url      string   `yaml:"url"`
+	Packages []string `yaml:"packages"` // path.Match patterns of the subscribed packages, all when empty
+}
+
+// Read the webhooks declared in the YAML file at filePath (under a "webhooks" key).
+func LoadWebhooks(filePath string) ([]Webhook, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Webhooks []Webhook `yaml:"webhooks"`
+	}
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return file.Webhooks, nil
+}
@@ -0,0 +1,87 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package export writes the whole database in formats suited to data analysis.
+package export
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	entriesFile  = "entries.parquet"
+	versionsFile = "versions.parquet"
+)
+
+// Row of entries.parquet, one for each package and symbol.
+type parquetEntry struct {
+	Package           string   `parquet:"package,dict,zstd"`
+	Name              string   `parquet:"name,zstd"` // empty for the package itself
+	Kind              string   `parquet:"kind,dict,zstd"`
+	Definition        string   `parquet:"definition,zstd"`
+	Signature         string   `parquet:"signature,zstd"`
+	AddedIn           string   `parquet:"added_in,dict,zstd"`
+	AddedInMinor      int32    `parquet:"added_in_minor"`
+	DeprecatedIn      string   `parquet:"deprecated_in,optional,dict,zstd"`
+	DeprecatedInMinor *int32   `parquet:"deprecated_in_minor,optional"`
+	Platforms         []string `parquet:"platforms,list,dict,zstd"` // empty when available on all platforms
+}
+
+// Row of versions.parquet.
+type parquetVersion struct {
+	Name  string `parquet:"name"`
+	Minor int32  `parquet:"minor"`
+}
+
+// Write the entries and the versions of the database as Parquet files in the directory dirPath (created if needed).
+func WriteParquet(vd versiondb.VersionDatas, dirPath string) error {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+
+	var versions []parquetVersion
+	for _, version := range vd.Versions() {
+		versions = append(versions, parquetVersion{Name: version, Minor: int32(versiondb.MinorVersion(version))})
+	}
+	if err := parquet.WriteFile(filepath.Join(dirPath, versionsFile), versions); err != nil {
+		return err
+	}
+
+	var entries []parquetEntry
+	err := vd.Walk(versiondb.Filter{}, func(entry versiondb.Entry) bool {
+		row := parquetEntry{
+			Package: entry.Package, Name: entry.Name, Kind: entry.Kind, Definition: entry.Definition, Signature: entry.Signature,
+			AddedIn: entry.AddedIn, AddedInMinor: int32(versiondb.MinorVersion(entry.AddedIn)),
+			DeprecatedIn: entry.DeprecatedIn, Platforms: entry.Platforms,
+		}
+		if entry.DeprecatedIn != "" {
+			minor := int32(versiondb.MinorVersion(entry.DeprecatedIn))
+			row.DeprecatedInMinor = &minor
+		}
+		entries = append(entries, row)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return parquet.WriteFile(filepath.Join(dirPath, entriesFile), entries)
+}
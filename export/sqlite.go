@@ -0,0 +1,157 @@
+//go:build !(solaris || ((freebsd || openbsd) && (386 || arm)))
+
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package export
+
+import (
+	"database/sql"
+	"os"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	_ "modernc.org/sqlite"
+)
+
+// Normalized schema, the id of a version is its minor number (0 for go1).
+const sqliteSchema = `
+CREATE TABLE versions (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE packages (
+	id INTEGER PRIMARY KEY,
+	path TEXT NOT NULL UNIQUE,
+	added_in INTEGER NOT NULL REFERENCES versions(id),
+	deprecated_in INTEGER REFERENCES versions(id)
+);
+CREATE TABLE symbols (
+	id INTEGER PRIMARY KEY,
+	package_id INTEGER NOT NULL REFERENCES packages(id),
+	name TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	definition TEXT NOT NULL,
+	signature TEXT NOT NULL,
+	added_in INTEGER NOT NULL REFERENCES versions(id),
+	deprecated_in INTEGER REFERENCES versions(id),
+	UNIQUE (package_id, name)
+);
+CREATE TABLE symbol_platforms (
+	symbol_id INTEGER NOT NULL REFERENCES symbols(id),
+	platform TEXT NOT NULL,
+	PRIMARY KEY (symbol_id, platform)
+);
+CREATE INDEX symbols_name ON symbols(name);
+CREATE VIEW entries AS
+SELECT p.path AS package, s.name, s.kind, s.signature, a.name AS added_in, d.name AS deprecated_in
+FROM symbols s JOIN packages p ON p.id = s.package_id
+JOIN versions a ON a.id = s.added_in LEFT JOIN versions d ON d.id = s.deprecated_in;
+`
+
+// Write the database into a new SQLite file at filePath (replaced when it exists).
+func WriteSqlite(vd versiondb.VersionDatas, filePath string) error {
+	tmpPath := filePath + ".tmp"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := writeSqlite(vd, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+func writeSqlite(vd versiondb.VersionDatas, filePath string) error {
+	db, err := sql.Open("sqlite", filePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no effect after the commit
+
+	for _, version := range vd.Versions() {
+		if _, err = tx.Exec("INSERT INTO versions (id, name) VALUES (?, ?)", versiondb.MinorVersion(version), version); err != nil {
+			return err
+		}
+	}
+
+	insertPackage, err := tx.Prepare("INSERT INTO packages (path, added_in, deprecated_in) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	insertSymbol, err := tx.Prepare("INSERT INTO symbols (package_id, name, kind, definition, signature, added_in, deprecated_in) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	insertPlatform, err := tx.Prepare("INSERT INTO symbol_platforms (symbol_id, platform) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+
+	var packageId int64
+	walkErr := vd.Walk(versiondb.Filter{}, func(entry versiondb.Entry) bool {
+		var result sql.Result
+		if entry.Kind == versiondb.KindPackage {
+			result, err = insertPackage.Exec(entry.Package, versionId(entry.AddedIn), versionId(entry.DeprecatedIn))
+			if err == nil {
+				packageId, err = result.LastInsertId()
+			}
+			return err == nil
+		}
+
+		result, err = insertSymbol.Exec(packageId, entry.Name, entry.Kind, entry.Definition, entry.Signature, versionId(entry.AddedIn), versionId(entry.DeprecatedIn))
+		if err != nil {
+			return false
+		}
+		var symbolId int64
+		if symbolId, err = result.LastInsertId(); err != nil {
+			return false
+		}
+		for _, platform := range entry.Platforms {
+			if _, err = insertPlatform.Exec(symbolId, platform); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Return the id of version in the versions table, nil for an empty version.
+func versionId(version string) any {
+	if version == "" {
+		return nil
+	}
+	return versiondb.MinorVersion(version)
+}
@@ -0,0 +1,34 @@
+//go:build solaris || ((freebsd || openbsd) && (386 || arm))
+
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package export
+
+import (
+	"errors"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+var errSqliteUnsupported = errors.New("export failure : SQLite is not supported on this platform")
+
+// Always fail, modernc.org/sqlite does not build on this platform.
+func WriteSqlite(vd versiondb.VersionDatas, filePath string) error {
+	return errSqliteUnsupported
+}
@@ -0,0 +1,99 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package features gives the introducing version of Go language features, from a curated dataset.
+package features
+
+import (
+	_ "embed"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownFeature = errors.New("language feature not found")
+
+	//go:embed features.yaml
+	featuresData []byte
+
+	nameReplacer = strings.NewReplacer(" ", "-", "_", "-")
+)
+
+type Feature struct {
+	Name              string   `yaml:"name"`
+	Aliases           []string `yaml:"aliases"`
+	Description       string   `yaml:"description"`
+	Version           string   `yaml:"version"`
+	Experiment        string   `yaml:"experiment"`         // GOEXPERIMENT value (or variable) of the preview, if any
+	ExperimentVersion string   `yaml:"experiment_version"` // release with the preview
+}
+
+// Return the features of the dataset, in release order.
+func All() ([]Feature, error) {
+	var res []Feature
+	if err := yaml.Unmarshal(featuresData, &res); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(res, func(a Feature, b Feature) int {
+		return versiondb.CompareVersion(a.Version, b.Version)
+	})
+	return res, nil
+}
+
+// Return the feature named (or aliased) name, case, spaces and underscores are ignored ("Range over func").
+func Find(name string) (Feature, error) {
+	all, err := All()
+	if err != nil {
+		return Feature{}, err
+	}
+
+	name = normalize(name)
+	for _, feature := range all {
+		if feature.Name == name || slices.Contains(feature.Aliases, name) {
+			return feature, nil
+		}
+	}
+	return Feature{}, ErrUnknownFeature
+}
+
+// Return the features whose name, aliases or description contains part.
+func Search(part string) ([]Feature, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, lowered := normalize(part), strings.ToLower(part)
+	var res []Feature
+	for _, feature := range all {
+		if strings.Contains(feature.Name, normalized) || strings.Contains(strings.ToLower(feature.Description), lowered) ||
+			slices.ContainsFunc(feature.Aliases, func(alias string) bool { return strings.Contains(alias, normalized) }) {
+			res = append(res, feature)
+		}
+	}
+	return res, nil
+}
+
+func normalize(name string) string {
+	return nameReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,87 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package golangci registers the gosince analyzers as a golangci-lint module plugin.
+package golangci
+
+import (
+	"strings"
+
+	"github.com/dvaumoron/gosince/analyzer"
+	"github.com/dvaumoron/gosince/config"
+	"github.com/golangci/plugin-module-register/register"
+	"golang.org/x/tools/go/analysis"
+)
+
+func init() {
+	register.Plugin("gosince", New)
+}
+
+// Settings of the plugin in the golangci-lint configuration.
+type Settings struct {
+	Go                string   `json:"go"`         // target go version, default to the go directive of the module
+	Ignore            []string `json:"ignore"`     // path.Match patterns on "pkg.Symbol"
+	CachePath         string   `json:"cache-path"` // default to GOSINCE_CACHE_PATH or ~/.gosince
+	SourceUrl         string   `json:"source-url"` // default to GOSINCE_SOURCE_URL or the golang/go repository
+	DisableDeprecated bool     `json:"disable-deprecated"`
+}
+
+type plugin struct {
+	settings Settings
+}
+
+func New(rawSettings any) (register.LinterPlugin, error) {
+	settings, err := register.DecodeSettings[Settings](rawSettings)
+	if err != nil {
+		return nil, err
+	}
+	return plugin{settings: settings}, nil
+}
+
+func (p plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	repoPath, sourceUrl, err := config.InitDefault(config.EnvCachePath, config.EnvSourceUrl)
+	if err != nil {
+		return nil, err
+	}
+	if p.settings.CachePath != "" {
+		repoPath = p.settings.CachePath
+	}
+	if p.settings.SourceUrl != "" {
+		sourceUrl = p.settings.SourceUrl
+	}
+	analyzer.SetConfig(config.Config{RepoPath: repoPath, SourceUrl: sourceUrl})
+
+	if err = analyzer.MinVersion.Flags.Set("go", p.settings.Go); err != nil {
+		return nil, err
+	}
+	ignore := strings.Join(p.settings.Ignore, ",")
+	for _, a := range analyzer.Analyzers() {
+		if err = a.Flags.Set("ignore", ignore); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.settings.DisableDeprecated {
+		return []*analysis.Analyzer{analyzer.MinVersion}, nil
+	}
+	return analyzer.Analyzers(), nil
+}
+
+func (p plugin) GetLoadMode() string {
+	return register.LoadModeTypesInfo
+}
@@ -0,0 +1,173 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lsp
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+// Standard library package or symbol found in a document.
+type reference struct {
+	pkg       string
+	symbol    string // empty for the package itself
+	textRange textRange
+}
+
+func (ref reference) name() string {
+	if ref.symbol == "" {
+		return ref.pkg
+	}
+	return ref.pkg + "." + ref.symbol
+}
+
+// Find the package or symbol under pos, the resolution is syntactic : import paths,
+// qualified identifiers (pkg.Symbol) and their members (pkg.Type.Member) are recognized.
+func resolve(text string, pos position) (reference, bool) {
+	offset := toOffset(text, pos)
+	if offset < 0 {
+		return reference{}, false
+	}
+
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, "", text, parser.SkipObjectResolution) // partial file are fine
+	if file == nil {
+		return reference{}, false
+	}
+	tokFile := fset.File(file.Pos())
+
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		pkgPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		if contains(tokFile, spec.Path, offset) {
+			return reference{pkg: pkgPath, textRange: toRange(text, tokFile, spec.Path)}, true
+		}
+
+		name := versiondb.PackageName(pkgPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = pkgPath
+	}
+
+	var res reference
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found || n == nil || !contains(tokFile, n, offset) {
+			return false
+		}
+
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		idents := flatten(selector)
+		if len(idents) < 2 || len(idents) > 3 {
+			return true
+		}
+
+		pkgPath, ok := imports[idents[0].Name]
+		if !ok {
+			return true
+		}
+
+		for index, ident := range idents[1:] {
+			if contains(tokFile, ident, offset) {
+				names := make([]string, 0, 2)
+				for _, part := range idents[1 : index+2] {
+					names = append(names, part.Name)
+				}
+				res = reference{pkg: pkgPath, symbol: strings.Join(names, "."), textRange: toRange(text, tokFile, ident)}
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return res, found
+}
+
+// Return the identifiers of a selector chain like a.b.c, nil when the chain does not start with an identifier.
+func flatten(selector *ast.SelectorExpr) []*ast.Ident {
+	switch x := selector.X.(type) {
+	case *ast.Ident:
+		return []*ast.Ident{x, selector.Sel}
+	case *ast.SelectorExpr:
+		if idents := flatten(x); idents != nil {
+			return append(idents, selector.Sel)
+		}
+	}
+	return nil
+}
+
+func contains(tokFile *token.File, n ast.Node, offset int) bool {
+	return tokFile.Offset(n.Pos()) <= offset && offset <= tokFile.Offset(n.End())
+}
+
+// Convert a position (line and UTF-16 column) to a byte offset in text, -1 when outside.
+func toOffset(text string, pos position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		index := strings.IndexByte(text[offset:], '\n')
+		if index == -1 {
+			return -1
+		}
+		offset += index + 1
+	}
+
+	for column := 0; column < pos.Character && offset < len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if r == '\n' {
+			break
+		}
+		offset += size
+		column += len(utf16.Encode([]rune{r}))
+	}
+	return offset
+}
+
+func toPosition(text string, offset int) position {
+	line, lineStart := 0, 0
+	for index := strings.IndexByte(text[:offset], '\n'); index != -1; index = strings.IndexByte(text[lineStart:offset], '\n') {
+		line++
+		lineStart += index + 1
+	}
+
+	column := 0
+	for _, r := range text[lineStart:offset] {
+		column += len(utf16.Encode([]rune{r}))
+	}
+	return position{Line: line, Character: column}
+}
+
+func toRange(text string, tokFile *token.File, n ast.Node) textRange {
+	return textRange{Start: toPosition(text, tokFile.Offset(n.Pos())), End: toPosition(text, tokFile.Offset(n.End()))}
+}
@@ -0,0 +1,52 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lsp
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	text := `package main
+
+import (
+	"math/rand/v2"
+	str "strings"
+)
+
+func main() {
+	_ = rand.IntN(10)
+	_, _, _ = str.Cut("a=b", "=")
+}
+`
+	for _, test := range []struct {
+		pos  position
+		want string
+	}{
+		{pos: position{Line: 3, Character: 3}, want: "math/rand/v2"},
+		{pos: position{Line: 8, Character: 11}, want: "math/rand/v2.IntN"}, // the name of a /vN package is the previous element
+		{pos: position{Line: 9, Character: 16}, want: "strings.Cut"},
+	} {
+		if ref, ok := resolve(text, test.pos); !ok || ref.name() != test.want {
+			t.Errorf("resolve(%+v) = %q, %t, want %q", test.pos, ref.name(), ok, test.want)
+		}
+	}
+
+	if ref, ok := resolve(text, position{Line: 8, Character: 1}); ok {
+		t.Errorf("resolve() outside of a reference = %q, want none", ref.name())
+	}
+}
@@ -0,0 +1,314 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package lsp implements a minimal language server answering hover and code action requests
+// with the introducing and deprecating versions of the standard library api under the cursor.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const (
+	codeMethodNotFound = -32601
+	codeParseError     = -32700
+	maxMessageLength   = 64 << 20 // larger than any source file sent by an editor
+)
+
+var errContentLength = errors.New("lsp protocol error : missing or invalid Content-Length header")
+
+type request struct {
+	Id     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type textDocumentItem struct {
+	Uri  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type textDocumentIdentifier struct {
+	Uri string `json:"uri"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type positionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type codeActionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        textRange              `json:"range"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    textRange     `json:"range"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type codeAction struct {
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+}
+
+type server struct {
+	vd        versiondb.VersionDatas
+	writer    io.Writer
+	writeLock sync.Mutex
+	documents map[string]string
+}
+
+// Answer the requests read from in on out until the exit notification or the end of in.
+func Serve(vd versiondb.VersionDatas, in io.Reader, out io.Writer) error {
+	s := &server{vd: vd, writer: out, documents: map[string]string{}}
+	reader := textproto.NewReader(bufio.NewReader(in))
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var req request
+		if err = json.Unmarshal(body, &req); err != nil {
+			if err = s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, respErr := s.handle(req)
+		if req.Id == nil {
+			continue // notification
+		}
+		if err = s.reply(req.Id, result, respErr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) handle(req request) (any, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1, // full document on each change
+				"hoverProvider":      true,
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "gosince"},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.documents[params.TextDocument.Uri] = params.TextDocument.Text
+		}
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if json.Unmarshal(req.Params, &params) == nil && len(params.ContentChanges) != 0 {
+			s.documents[params.TextDocument.Uri] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params didCloseParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			delete(s.documents, params.TextDocument.Uri)
+		}
+		return nil, nil
+	case "textDocument/hover":
+		var params positionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeParseError, Message: err.Error()}
+		}
+		return s.hover(params), nil
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeParseError, Message: err.Error()}
+		}
+		return s.codeActions(params), nil
+	}
+
+	if req.Id == nil || strings.HasPrefix(req.Method, "$/") {
+		return nil, nil // ignored notification
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "unsupported method " + req.Method}
+}
+
+func (s *server) hover(params positionParams) any {
+	text, ok := s.documents[params.TextDocument.Uri]
+	if !ok {
+		return nil
+	}
+
+	ref, ok := resolve(text, params.Position)
+	if !ok {
+		return nil
+	}
+
+	message, ok := s.message(ref)
+	if !ok {
+		return nil
+	}
+	return hover{Contents: markupContent{Kind: "markdown", Value: message}, Range: ref.textRange}
+}
+
+func (s *server) codeActions(params codeActionParams) []codeAction {
+	actions := []codeAction{}
+	text, ok := s.documents[params.TextDocument.Uri]
+	if !ok {
+		return actions
+	}
+
+	ref, ok := resolve(text, params.Range.Start)
+	if !ok {
+		return actions
+	}
+
+	if message, ok := s.message(ref); ok {
+		actions = append(actions, codeAction{Title: strings.ReplaceAll(message, "`", ""), Kind: "source"})
+	}
+	return actions
+}
+
+// Return the markdown message describing the versions of ref.
+func (s *server) message(ref reference) (string, bool) {
+	versions, err := s.vd.Since(ref.pkg, ref.symbol)
+	if err != nil {
+		return "", false
+	}
+
+	var builder strings.Builder
+	builder.WriteString("`")
+	builder.WriteString(ref.name())
+	builder.WriteString("` added in ")
+	builder.WriteString(versions[0])
+	if versions[1] != "" {
+		builder.WriteString(" and deprecated in ")
+		builder.WriteString(versions[1])
+		switch replacement := s.vd.Replacement(ref.pkg, ref.symbol); replacement {
+		case "":
+		case versiondb.NoReplacement:
+			builder.WriteString(", without replacement")
+		default:
+			builder.WriteString(", use `")
+			builder.WriteString(replacement)
+			builder.WriteString("` instead")
+		}
+	}
+	if platforms := s.vd.Platforms(ref.pkg, ref.symbol); len(platforms) != 0 {
+		builder.WriteString(" (")
+		builder.WriteString(strings.Join(s.vd.CompactPlatforms(platforms), ", "))
+		builder.WriteString(" only)")
+	}
+	return builder.String(), true
+}
+
+func (s *server) reply(id *json.RawMessage, result any, respErr *responseError) error {
+	body, err := json.Marshal(response{JsonRpc: "2.0", Id: id, Result: result, Error: respErr})
+	if err != nil {
+		return err
+	}
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if _, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = s.writer.Write(body)
+	return err
+}
+
+func readMessage(reader *textproto.Reader) ([]byte, error) {
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 || length > maxMessageLength {
+		return nil, errContentLength
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader.R, body)
+	return body, err
+}
@@ -0,0 +1,40 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lsp
+
+import (
+	"bufio"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestReadMessage(t *testing.T) {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader("Content-Length: 2\r\n\r\n{}")))
+	if body, err := readMessage(reader); err != nil || string(body) != "{}" {
+		t.Errorf("readMessage() = %q, %v, want {}", body, err)
+	}
+
+	for _, length := range []string{"", "abc", "-1", "1000000000000"} {
+		reader = textproto.NewReader(bufio.NewReader(strings.NewReader("Content-Length: " + length + "\r\n\r\n{}")))
+		if _, err := readMessage(reader); err != errContentLength {
+			t.Errorf("readMessage() with Content-Length %q error = %v, want %v", length, err, errContentLength)
+		}
+	}
+}
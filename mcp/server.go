@@ -0,0 +1,275 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package mcp exposes the version database as Model Context Protocol tools, over newline delimited JSON-RPC.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const (
+	protocolVersion = "2025-06-18"
+
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeParseError     = -32700
+)
+
+var errMissingArgument = errors.New("missing argument")
+
+type request struct {
+	Id     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Result  any              `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type callParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type toolArguments struct {
+	Package string   `json:"package"`
+	Symbol  string   `json:"symbol"`
+	Query   string   `json:"query"`
+	Symbols []string `json:"symbols"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError"`
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	call        func(versiondb.VersionDatas, toolArguments) (string, error)
+}
+
+var tools = []tool{
+	{
+		Name:        "since",
+		Description: "Give the Go version introducing (and deprecating) a standard library package or symbol, like package net/http with symbol Client.Do.",
+		InputSchema: objectSchema(map[string]any{
+			"package": stringSchema("Import path of the standard library package, like net/http"),
+			"symbol":  stringSchema("Optional symbol in <sym>[.<methodOrField>] form, like Client.Do"),
+		}, "package"),
+		call: since,
+	},
+	{
+		Name:        "search",
+		Description: "Search the standard library symbols named like the query (a symbol or Type.Member), with their introducing versions.",
+		InputSchema: objectSchema(map[string]any{
+			"query": stringSchema("Symbol name, like Join or Buffer.Write"),
+		}, "query"),
+		call: search,
+	},
+	{
+		Name:        "min_version",
+		Description: "Give the minimum Go version needed to use all the listed standard library packages and symbols.",
+		InputSchema: objectSchema(map[string]any{
+			"symbols": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Expressions in <pkg> or <pkg>.<sym>[.<methodOrField>] form, like slices.Sort",
+			},
+		}, "symbols"),
+		call: minVersion,
+	},
+}
+
+// Answer the requests read from in on out until the end of in.
+func Serve(vd versiondb.VersionDatas, serverVersion string, in io.Reader, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err = encoder.Encode(response{JsonRpc: "2.0", Error: &responseError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, respErr := handle(vd, serverVersion, req)
+		if req.Id == nil {
+			continue // notification
+		}
+		if err := encoder.Encode(response{JsonRpc: "2.0", Id: req.Id, Result: result, Error: respErr}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func handle(vd versiondb.VersionDatas, serverVersion string, req request) (any, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": protocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "gosince", "version": serverVersion},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var params callParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+
+		for _, t := range tools {
+			if t.Name != params.Name {
+				continue
+			}
+
+			var args toolArguments
+			if len(params.Arguments) != 0 {
+				if err := json.Unmarshal(params.Arguments, &args); err != nil {
+					return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+				}
+			}
+
+			text, err := t.call(vd, args)
+			if err != nil {
+				return callResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+			}
+			return callResult{Content: []textContent{{Type: "text", Text: text}}}, nil
+		}
+		return nil, &responseError{Code: codeInvalidParams, Message: "unknown tool " + params.Name}
+	}
+
+	if req.Id == nil {
+		return nil, nil // ignored notification
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "unsupported method " + req.Method}
+}
+
+func since(vd versiondb.VersionDatas, args toolArguments) (string, error) {
+	if args.Package == "" {
+		return "", fmt.Errorf("%w : package", errMissingArgument)
+	}
+
+	versions, err := vd.Since(args.Package, args.Symbol)
+	if err != nil {
+		return "", err
+	}
+
+	name := args.Package
+	if args.Symbol != "" {
+		name += "." + args.Symbol
+	}
+	return describe(vd, name, versions, vd.Platforms(args.Package, args.Symbol)), nil
+}
+
+func search(vd versiondb.VersionDatas, args toolArguments) (string, error) {
+	if args.Query == "" {
+		return "", fmt.Errorf("%w : query", errMissingArgument)
+	}
+
+	entries := vd.Search(args.Query)
+	if len(entries) == 0 {
+		return "", versiondb.ErrUnknownSymbol
+	}
+
+	if err := versiondb.SortEntries(entries, versiondb.SortName); err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		pkg, symbol, _ := strings.Cut(entry[0], " ")
+		name := pkg
+		if symbol != "" {
+			name += "." + symbol
+		}
+		lines = append(lines, describe(vd, name, [2]string{entry[1], entry[2]}, vd.Platforms(pkg, symbol)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func minVersion(vd versiondb.VersionDatas, args toolArguments) (string, error) {
+	if len(args.Symbols) == 0 {
+		return "", fmt.Errorf("%w : symbols", errMissingArgument)
+	}
+
+	version, responsibles, failures := vd.MinVersion(args.Symbols, "", "")
+	var lines []string
+	if version != "" {
+		lines = append(lines, version+" required by "+strings.Join(responsibles, ", "))
+	}
+	for _, expr := range args.Symbols {
+		if err, ok := failures[expr]; ok {
+			lines = append(lines, expr+" : "+err.Error())
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func describe(vd versiondb.VersionDatas, name string, versions [2]string, platforms []string) string {
+	res := name + " added in " + versions[0]
+	if versions[1] != "" {
+		res += " and deprecated in " + versions[1]
+	}
+	if len(platforms) != 0 {
+		res += " (" + strings.Join(vd.CompactPlatforms(platforms), ", ") + " only)"
+	}
+	return res
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+func stringSchema(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
@@ -0,0 +1,205 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package notify sends the api added by a new Go release to webhooks (Slack, Discord or generic JSON).
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+const (
+	KindDiscord = "discord"
+	KindGeneric = "generic"
+	KindSlack   = "slack"
+
+	discordMaxLength = 2000 // maximum length of a Discord message
+	releaseUrl       = "https://go.dev/doc/"
+)
+
+var (
+	errUnknownKind = errors.New("unknown webhook kind")
+	errStatus      = errors.New("webhook failure : unexpected status")
+)
+
+// Package or symbol of a release, as sent to generic webhooks.
+type Entry struct {
+	Package      string `json:"package"`
+	Symbol       string `json:"symbol,omitempty"`
+	AddedIn      string `json:"added_in"`
+	DeprecatedIn string `json:"deprecated_in,omitempty"`
+}
+
+type genericPayload struct {
+	Version string  `json:"version"`
+	Url     string  `json:"url"`
+	Entries []Entry `json:"entries"`
+}
+
+type Notifier struct {
+	client   *http.Client
+	webhooks []config.Webhook
+}
+
+// Return a notifier posting to webhooks with client (http.DefaultClient when nil).
+func New(webhooks []config.Webhook, client *http.Client) (Notifier, error) {
+	for _, webhook := range webhooks {
+		switch webhook.Kind {
+		case "", KindDiscord, KindGeneric, KindSlack:
+		default:
+			return Notifier{}, fmt.Errorf("%w : %s", errUnknownKind, webhook.Kind)
+		}
+		for _, pattern := range webhook.Packages {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return Notifier{}, err
+			}
+		}
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return Notifier{client: client, webhooks: webhooks}, nil
+}
+
+// Send the entries (in index form, {entry, addedIn, deprecatedIn}) added or deprecated by version
+// to the webhooks subscribed to their packages (entries must be sorted by package, see versiondb.SortPackage),
+// a webhook without matching entry is not called.
+func (n Notifier) Notify(ctx context.Context, version string, entries [][3]string) error {
+	var errs []error
+	for _, webhook := range n.webhooks {
+		selected := subscribed(webhook.Packages, entries)
+		if len(selected) == 0 {
+			continue
+		}
+
+		if err := n.post(ctx, webhook, payload(webhook.Kind, version, selected)); err != nil {
+			errs = append(errs, fmt.Errorf("%s : %w", webhook.Url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n Notifier) post(ctx context.Context, webhook config.Webhook, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%w %s", errStatus, resp.Status)
+	}
+	return nil
+}
+
+// Return the entries of the packages matching one of patterns (all when there is none).
+func subscribed(patterns []string, entries [][3]string) [][3]string {
+	if len(patterns) == 0 {
+		return entries
+	}
+
+	var res [][3]string
+	for _, entry := range entries {
+		pkg, _, _ := strings.Cut(entry[0], " ")
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, pkg); matched {
+				res = append(res, entry)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func payload(kind string, version string, entries [][3]string) any {
+	switch kind {
+	case KindSlack:
+		return map[string]string{"text": summary(version, entries, 0)}
+	case KindDiscord:
+		return map[string]string{"content": summary(version, entries, discordMaxLength)}
+	}
+
+	res := genericPayload{Version: version, Url: releaseUrl + version, Entries: make([]Entry, 0, len(entries))}
+	for _, entry := range entries {
+		pkg, symbol, _ := strings.Cut(entry[0], " ")
+		res.Entries = append(res.Entries, Entry{Package: pkg, Symbol: symbol, AddedIn: entry[1], DeprecatedIn: entry[2]})
+	}
+	return res
+}
+
+// Return a text message listing entries by package, cut before maxLength when it is positive.
+func summary(version string, entries [][3]string, maxLength int) string {
+	var builder strings.Builder
+	builder.WriteString(version)
+	builder.WriteString(" is out (")
+	builder.WriteString(releaseUrl)
+	builder.WriteString(version)
+	builder.WriteString(") :")
+
+	currentPkg := ""
+	for index, entry := range entries {
+		pkg, symbol, _ := strings.Cut(entry[0], " ")
+		var line strings.Builder
+		if pkg != currentPkg {
+			currentPkg = pkg
+			line.WriteString("\n")
+			line.WriteString(pkg)
+			if symbol == "" && entry[1] == version {
+				line.WriteString(" (new package)")
+			}
+			if symbol == "" && entry[2] == version {
+				line.WriteString(" (deprecated)")
+			}
+		}
+		if symbol != "" {
+			line.WriteString("\n  ")
+			line.WriteString(symbol)
+			if entry[2] == version {
+				line.WriteString(" (deprecated)")
+			}
+		}
+
+		if maxLength > 0 && builder.Len()+line.Len() > maxLength-20 {
+			fmt.Fprintf(&builder, "\n... and %d more", len(entries)-index)
+			break
+		}
+		builder.WriteString(line.String())
+	}
+	return builder.String()
+}
@@ -0,0 +1,124 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pkgdoc
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+var errNoExample = errors.New("documentation failure : no example found")
+
+type Examples struct {
+	examples []*doc.Example
+	fset     *token.FileSet
+}
+
+// Read the examples of the package pkg (like "net/http") from its test files in the local cache,
+// or download them from the Go source when they are not cached yet.
+func LoadExamples(conf config.Config, pkg string) (Examples, error) {
+	examples, fset, err := versiondb.LoadExamples(conf, pkg)
+	if err != nil {
+		return Examples{}, err
+	}
+	return Examples{examples: examples, fset: fset}, nil
+}
+
+// Write the examples of symbol (like "Cut" or "Client.Do", the package itself when empty), case is ignored.
+// A runnable example is written as a complete program, followed by its expected output.
+func (e Examples) Render(w io.Writer, symbol string) error {
+	name := strings.ReplaceAll(symbol, ".", "_") // ExampleClient_Do documents Client.Do
+	found := false
+	for _, example := range e.examples {
+		exampleName, suffix := splitExampleName(example.Name)
+		if !strings.EqualFold(exampleName, name) {
+			continue
+		}
+
+		if found {
+			io.WriteString(w, "\n")
+		}
+		found = true
+		if suffix == "" {
+			io.WriteString(w, "Example :\n\n")
+		} else {
+			fmt.Fprintf(w, "Example (%s) :\n\n", suffix)
+		}
+		if err := e.writeCode(w, example); err != nil {
+			return err
+		}
+		if example.Output != "" {
+			fmt.Fprintf(w, "\nOutput:\n%s", example.Output)
+		}
+	}
+
+	if !found {
+		return errNoExample
+	}
+	return nil
+}
+
+// Write the complete program when the example is runnable, its statements otherwise.
+func (e Examples) writeCode(w io.Writer, example *doc.Example) error {
+	if example.Play != nil {
+		return format.Node(w, e.fset, example.Play)
+	}
+
+	var comments []*ast.CommentGroup // without the output comment, written after the code
+	for _, comment := range example.Comments {
+		text := strings.ToLower(comment.Text())
+		if !strings.HasPrefix(text, "output:") && !strings.HasPrefix(text, "unordered output:") {
+			comments = append(comments, comment)
+		}
+	}
+
+	var codeBuilder strings.Builder
+	if err := format.Node(&codeBuilder, e.fset, &printer.CommentedNode{Node: example.Code, Comments: comments}); err != nil {
+		return err
+	}
+
+	code := codeBuilder.String()
+	if _, ok := example.Code.(*ast.BlockStmt); ok { // remove the braces and the indentation of the body
+		code = strings.TrimSuffix(strings.TrimPrefix(code, "{\n"), "}")
+		code = strings.ReplaceAll(strings.TrimPrefix(code, "\t"), "\n\t", "\n")
+	}
+	_, err := io.WriteString(w, strings.TrimRight(code, "\n")+"\n")
+	return err
+}
+
+// Split the name of an example function (without its "Example" prefix) into the exemplified
+// name and the suffix starting with a lower case letter (like "FileServer" and "stripPrefix").
+func splitExampleName(name string) (string, string) {
+	index := strings.LastIndexByte(name, '_')
+	if index == -1 || index+1 == len(name) || !unicode.IsLower(rune(name[index+1])) {
+		return name, ""
+	}
+	return name[:index], name[index+1:]
+}
@@ -0,0 +1,212 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package pkgdoc renders the documentation of standard library packages and symbols with go/doc,
+// from their source files downloaded (and cached) like the api files.
+package pkgdoc
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+var errUnknownSymbol = errors.New("documentation failure : symbol not found")
+
+type Package struct {
+	doc  *doc.Package
+	fset *token.FileSet
+}
+
+// Read the source files of the package pkg (like "net/http") from the local cache,
+// or download them from the Go source when they are not cached yet.
+func Load(conf config.Config, pkg string) (Package, error) {
+	docPkg, fset, err := versiondb.LoadDocPackage(conf, pkg)
+	if err != nil {
+		return Package{}, err
+	}
+	return Package{doc: docPkg, fset: fset}, nil
+}
+
+// Write the documentation of symbol (like "Client" or "Client.Do", the package itself when empty), case is ignored.
+func (p Package) Render(w io.Writer, symbol string) error {
+	if symbol == "" {
+		fmt.Fprintf(w, "package %s // import %q\n\n", p.doc.Name, p.doc.ImportPath)
+		_, err := w.Write(p.doc.Text(p.doc.Doc))
+		return err
+	}
+
+	node, docText, ok := p.lookup(symbol)
+	if !ok {
+		return errUnknownSymbol
+	}
+
+	if err := p.writeNode(w, node); err != nil {
+		return err
+	}
+	io.WriteString(w, "\n")
+	if docText != "" {
+		io.WriteString(w, "\n")
+		_, err := w.Write(p.doc.Text(docText))
+		return err
+	}
+	return nil
+}
+
+// Return the first paragraph of the documentation of symbol (the package itself when empty), formatted as text.
+func (p Package) FirstParagraph(symbol string) (string, error) {
+	docText := p.doc.Doc
+	if symbol != "" {
+		var ok bool
+		if _, docText, ok = p.lookup(symbol); !ok {
+			return "", errUnknownSymbol
+		}
+	}
+
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(docText), "\n\n")
+	return string(p.doc.Text(paragraph)), nil
+}
+
+// Write the declaration node, a field (or interface method) is written alone like "Name Type".
+func (p Package) writeNode(w io.Writer, node ast.Node) error {
+	field, ok := node.(*ast.Field)
+	if !ok {
+		return format.Node(w, p.fset, node)
+	}
+
+	var typeBuilder strings.Builder
+	if err := format.Node(&typeBuilder, p.fset, field.Type); err != nil {
+		return err
+	}
+
+	fieldType := " " + typeBuilder.String()
+	if _, ok = field.Type.(*ast.FuncType); ok {
+		fieldType = strings.TrimPrefix(fieldType, " func") // interface method
+	}
+	for index, name := range field.Names {
+		if index != 0 {
+			io.WriteString(w, ", ")
+		}
+		io.WriteString(w, name.Name)
+	}
+	_, err := io.WriteString(w, fieldType)
+	return err
+}
+
+// Return the declaration to print for symbol with its doc comment.
+func (p Package) lookup(symbol string) (ast.Node, string, bool) {
+	typeName, member, _ := strings.Cut(symbol, ".")
+	if member == "" {
+		if decl, docText, ok := findValue(p.doc.Consts, p.doc.Vars, symbol); ok {
+			return decl, docText, true
+		}
+		for _, fn := range p.doc.Funcs {
+			if strings.EqualFold(fn.Name, symbol) {
+				return fn.Decl, fn.Doc, true
+			}
+		}
+	}
+
+	for _, typ := range p.doc.Types {
+		if !strings.EqualFold(typ.Name, typeName) {
+			continue
+		}
+		if member == "" {
+			return typ.Decl, typ.Doc, true
+		}
+
+		for _, method := range typ.Methods {
+			if strings.EqualFold(method.Name, member) {
+				return method.Decl, method.Doc, true
+			}
+		}
+		if field, ok := findField(typ.Decl, member); ok {
+			docText := field.Doc.Text()
+			if docText == "" {
+				docText = field.Comment.Text() // line comment
+			}
+			return field, docText, true
+		}
+		return nil, "", false
+	}
+
+	if member == "" { // constants, variables and constructors grouped with their type
+		for _, typ := range p.doc.Types {
+			if decl, docText, ok := findValue(typ.Consts, typ.Vars, symbol); ok {
+				return decl, docText, true
+			}
+			for _, fn := range typ.Funcs {
+				if strings.EqualFold(fn.Name, symbol) {
+					return fn.Decl, fn.Doc, true
+				}
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// Return the declaration group containing name, like go doc does.
+func findValue(consts []*doc.Value, vars []*doc.Value, name string) (ast.Node, string, bool) {
+	for _, values := range [2][]*doc.Value{consts, vars} {
+		for _, value := range values {
+			for _, valueName := range value.Names {
+				if strings.EqualFold(valueName, name) {
+					return value.Decl, value.Doc, true
+				}
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// Return the field (or interface method) named name in the type declared by decl.
+func findField(decl *ast.GenDecl, name string) (*ast.Field, bool) {
+	for _, spec := range decl.Specs {
+		typeSpec, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+
+		var fields *ast.FieldList
+		switch typed := typeSpec.Type.(type) {
+		case *ast.StructType:
+			fields = typed.Fields
+		case *ast.InterfaceType:
+			fields = typed.Methods
+		default:
+			continue
+		}
+
+		for _, field := range fields.List {
+			for _, fieldName := range field.Names {
+				if strings.EqualFold(fieldName.Name, name) {
+					return field, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,176 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pkgdoc
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const (
+	// Words are compared on their first letters only, a crude stemming ("comparison" matches "compare").
+	stemLength = 6
+	// Each word of the name of a symbol counts as this many occurrences in its doc comment.
+	nameWeight = 3
+	// Okapi BM25 parameters.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {}, "for": {}, "from": {}, "if": {},
+	"in": {}, "is": {}, "it": {}, "its": {}, "of": {}, "on": {}, "or": {}, "that": {}, "the": {}, "this": {}, "to": {}, "with": {},
+}
+
+// Entry of a full-text search, with its relevance.
+type DocMatch struct {
+	versiondb.DocEntry
+	Score float64
+}
+
+// Full-text index of doc comments.
+type Index struct {
+	entries   []versiondb.DocEntry
+	postings  map[string][]posting // by stem
+	lengths   []int                // weighted number of words of each entry
+	avgLength float64
+}
+
+type posting struct {
+	entry int
+	count int // weighted occurrences
+}
+
+// Return the index of the doc comments of the packages (see versiondb.LoadDocs).
+func LoadIndex(conf config.Config, packages []string) (*Index, error) {
+	byPackage, err := versiondb.LoadDocs(conf, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []versiondb.DocEntry
+	for _, pkg := range packages {
+		entries = append(entries, byPackage[pkg]...)
+	}
+	return NewIndex(entries), nil
+}
+
+// Index the entries, on the words of their doc comment and of their name.
+func NewIndex(entries []versiondb.DocEntry) *Index {
+	index := &Index{entries: entries, postings: map[string][]posting{}, lengths: make([]int, len(entries))}
+	total := 0
+	for entryIndex, entry := range entries {
+		counts := map[string]int{}
+		for _, stem := range stems(entry.Text) {
+			counts[stem]++
+		}
+		name := entry.Symbol
+		if name == "" {
+			name = entry.Package
+		}
+		for _, stem := range stems(name) {
+			counts[stem] += nameWeight
+		}
+
+		length := 0
+		for stem, count := range counts {
+			index.postings[stem] = append(index.postings[stem], posting{entry: entryIndex, count: count})
+			length += count
+		}
+		index.lengths[entryIndex] = length
+		total += length
+	}
+	if len(entries) != 0 {
+		index.avgLength = float64(total) / float64(len(entries))
+	}
+	return index
+}
+
+// Return the entries containing words of query, the most relevant first (Okapi BM25 ranking).
+func (index *Index) Search(query string) []DocMatch {
+	scores := map[int]float64{}
+	entryCount := float64(len(index.entries))
+	for _, stem := range slices.Compact(slices.Sorted(slices.Values(stems(query)))) {
+		postings := index.postings[stem]
+		idf := math.Log(1 + (entryCount-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+		for _, post := range postings {
+			count := float64(post.count)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(index.lengths[post.entry])/index.avgLength)
+			scores[post.entry] += idf * count * (bm25K1 + 1) / (count + norm)
+		}
+	}
+
+	res := make([]DocMatch, 0, len(scores))
+	for entryIndex, score := range scores {
+		res = append(res, DocMatch{DocEntry: index.entries[entryIndex], Score: score})
+	}
+	slices.SortFunc(res, func(a DocMatch, b DocMatch) int {
+		if res := cmp.Compare(b.Score, a.Score); res != 0 {
+			return res
+		}
+		if res := strings.Compare(a.Package, b.Package); res != 0 {
+			return res
+		}
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	return res
+}
+
+// Split text in lowercased words (identifiers are split on case changes, like "ConstantTimeCompare"),
+// without the stop words, each one truncated to stemLength letters.
+func stems(text string) []string {
+	var res []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		for _, part := range splitCamelCase(word) {
+			part = strings.ToLower(part)
+			if _, ok := stopWords[part]; ok {
+				continue
+			}
+			if runes := []rune(part); len(runes) > stemLength {
+				part = string(runes[:stemLength])
+			}
+			res = append(res, part)
+		}
+	}
+	return res
+}
+
+// Split a word like "ConstantTimeCompare" or "ReadHTTPRequest" on its case changes.
+func splitCamelCase(word string) []string {
+	runes := []rune(word)
+	var res []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+		acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			res = append(res, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(res, string(runes[start:]))
+}
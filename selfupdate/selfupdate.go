@@ -0,0 +1,225 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package selfupdate replaces the running binary by the one of the latest GitHub release, built by goreleaser.
+package selfupdate
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	DefaultReleasesUrl = "https://api.github.com/repos/dvaumoron/gosince/releases/latest"
+
+	binaryName = "gosince"
+)
+
+var (
+	errNoAsset       = errors.New("update failure : no binary for this platform in the release")
+	errNoChecksum    = errors.New("update failure : no checksum for the binary archive")
+	errChecksum      = errors.New("update failure : checksum mismatch")
+	errNoBinary      = errors.New("update failure : no binary in the archive")
+	errReleaseFormat = errors.New("update failure : unexpected release description")
+)
+
+// Latest release and its downloadable files.
+type Release struct {
+	Tag    string `json:"tag_name"`
+	Assets []struct {
+		Name string `json:"name"`
+		Url  string `json:"browser_download_url"`
+	} `json:"assets"`
+
+	client *http.Client
+}
+
+// Return the description of the latest release, from the GitHub releases api at releasesUrl,
+// client (http.DefaultClient when nil) is also used by Install.
+func Latest(releasesUrl string, client *http.Client) (Release, error) {
+	data, err := versiondb.DownloadWith(client, releasesUrl, nil)
+	if err != nil {
+		return Release{}, err
+	}
+
+	var release Release
+	if err = json.Unmarshal(data, &release); err != nil || !semver.IsValid(canonical(release.Tag)) {
+		return Release{}, errReleaseFormat
+	}
+	release.client = client
+	return release, nil
+}
+
+// Return the version of the release, without the "v" prefix of the tag.
+func (r Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Return true when the release is newer than version (with or without "v" prefix), compared as semantic versions,
+// a release is newer than any version which is not semantic (like "dev").
+func (r Release) Newer(version string) bool {
+	return semver.Compare(canonical(r.Tag), canonical(version)) > 0
+}
+
+// Return true when version (with or without "v" prefix) is a semantic version, false for a dev build.
+func Released(version string) bool {
+	return semver.IsValid(canonical(version))
+}
+
+// Download the archive of the release for the running platform, check it against the release checksums,
+// then replace the running binary with the one in the archive.
+func (r Release) Install() error {
+	archiveName := fmt.Sprintf("%s_%s_%s_%s.zip", binaryName, r.Version(), runtime.GOOS, runtime.GOARCH)
+	archiveUrl := r.assetUrl(archiveName)
+	checksumsUrl := r.assetUrl(fmt.Sprintf("%s_%s_checksums.txt", binaryName, r.Version()))
+	if archiveUrl == "" {
+		return fmt.Errorf("%w (%s)", errNoAsset, archiveName)
+	}
+	if checksumsUrl == "" {
+		return errNoChecksum
+	}
+
+	checksums, err := versiondb.DownloadWith(r.client, checksumsUrl, nil)
+	if err != nil {
+		return err
+	}
+	expected := findChecksum(checksums, archiveName)
+	if expected == "" {
+		return errNoChecksum
+	}
+
+	archive, err := versiondb.DownloadWith(r.client, archiveUrl, nil)
+	if err != nil {
+		return err
+	}
+	if sum := sha256.Sum256(archive); hex.EncodeToString(sum[:]) != expected {
+		return errChecksum
+	}
+
+	binary, err := extractBinary(archive)
+	if err != nil {
+		return err
+	}
+	return replaceExecutable(binary)
+}
+
+func canonical(version string) string {
+	return "v" + strings.TrimPrefix(version, "v")
+}
+
+func (r Release) assetUrl(name string) string {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.Url
+		}
+	}
+	return ""
+}
+
+// Return the hash of fileName in a checksums file (in the format of sha256sum), an empty string when missing.
+func findChecksum(checksums []byte, fileName string) string {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		if hash, name, ok := strings.Cut(scanner.Text(), " "); ok && strings.TrimSpace(name) == fileName {
+			return hash
+		}
+	}
+	return ""
+}
+
+func extractBinary(archive []byte) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+
+	name := binaryName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	for _, file := range reader.File {
+		if path := file.Name; path != name && !strings.HasSuffix(path, "/"+name) {
+			continue
+		}
+
+		fileReader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer fileReader.Close()
+		return io.ReadAll(fileReader)
+	}
+	return nil, errNoBinary
+}
+
+// Write binary next to the running executable then rename it over, the previous one is moved aside first
+// (a running executable can not be replaced on Windows).
+func replaceExecutable(binary []byte) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(executable), binaryName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name()) // no effect once renamed
+
+	if _, err = tmpFile.Write(binary); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Chmod(0755); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+
+	oldExecutable := executable + ".old"
+	os.Remove(oldExecutable) // left by a previous update on Windows
+	if err = os.Rename(executable, oldExecutable); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpFile.Name(), executable); err != nil {
+		os.Rename(oldExecutable, executable) // restore
+		return err
+	}
+	os.Remove(oldExecutable) // fails on Windows while running, removed by the next update
+	return nil
+}
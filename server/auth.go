@@ -0,0 +1,214 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	analyzeGrant = "analyze"
+	apiKeyHeader = "X-Api-Key"
+)
+
+var (
+	errAnalyzeDenied  = errors.New("api key not allowed to analyze")
+	errInvalidKeyLine = errors.New("invalid api key line")
+	errRateLimited    = errors.New("rate limit exceeded")
+	errUnauthorized   = errors.New("missing or invalid api key")
+)
+
+type keyGrant struct {
+	limiter *rate.Limiter // nil means unlimited
+	analyze bool
+}
+
+// Check the api keys, their rate limits and their right to analyze.
+type Authenticator struct {
+	grants map[string]keyGrant // by key sha256 (hex)
+}
+
+// Accept the static keys (without the right to analyze) and the hashed keys listed in the file at hashedKeysPath (optional),
+// perMinute is the default rate limit of each key (0 for unlimited).
+// Each line of the file is the sha256 (hex) of a key optionally followed by its own rate limit and by "analyze"
+// (allowing the analyze queries), # starts a comment.
+func NewAuthenticator(keys []string, hashedKeysPath string, perMinute int) (*Authenticator, error) {
+	auth := &Authenticator{grants: map[string]keyGrant{}}
+	for _, key := range keys {
+		auth.grants[hashKey(key)] = keyGrant{limiter: newLimiter(perMinute)}
+	}
+
+	if hashedKeysPath == "" {
+		return auth, nil
+	}
+
+	file, err := os.Open(hashedKeysPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		analyze := false
+		if last := len(fields) - 1; last > 0 && fields[last] == analyzeGrant {
+			analyze = true
+			fields = fields[:last]
+		}
+
+		keyPerMinute := perMinute
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("%w %d", errInvalidKeyLine, lineNumber)
+		}
+		if len(fields) == 2 {
+			if keyPerMinute, err = strconv.Atoi(fields[1]); err != nil || keyPerMinute < 0 {
+				return nil, fmt.Errorf("%w %d", errInvalidKeyLine, lineNumber)
+			}
+		}
+		if _, err = hex.DecodeString(fields[0]); err != nil || len(fields[0]) != 2*sha256.Size {
+			return nil, fmt.Errorf("%w %d", errInvalidKeyLine, lineNumber)
+		}
+		auth.grants[strings.ToLower(fields[0])] = keyGrant{limiter: newLimiter(keyPerMinute), analyze: analyze}
+	}
+	return auth, scanner.Err()
+}
+
+// Return nil when key is accepted (with the right to analyze when analyze is true) and under its rate limit,
+// errUnauthorized, errAnalyzeDenied or errRateLimited otherwise.
+func (auth *Authenticator) check(key string, analyze bool) error {
+	if key == "" {
+		return errUnauthorized
+	}
+
+	grant, ok := auth.grants[hashKey(key)]
+	if !ok {
+		return errUnauthorized
+	}
+	if analyze && !grant.analyze {
+		return errAnalyzeDenied
+	}
+	if grant.limiter != nil && !grant.limiter.Allow() {
+		return errRateLimited
+	}
+	return nil
+}
+
+// Require an accepted key on the queries, authenticating is disabled when auth is nil (default).
+// Must be called before Handler.
+func (s *Server) SetAuthenticator(auth *Authenticator) {
+	s.auth = auth
+}
+
+// Check the key of the queries, analyze queries need a key with the right to analyze.
+func (s *Server) authenticate(handler http.HandlerFunc, analyze bool) http.HandlerFunc {
+	if s.auth == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(apiKeyHeader)
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = bearer
+		}
+
+		switch err := s.auth.check(key, analyze); err {
+		case nil:
+			handler(w, r)
+		case errAnalyzeDenied:
+			writeJson(w, http.StatusForbidden, errorOutput{Error: err.Error()})
+		case errRateLimited:
+			w.Header().Set("Retry-After", strconv.Itoa(s.auth.retryAfter(key)))
+			writeJson(w, http.StatusTooManyRequests, errorOutput{Error: err.Error()})
+		default:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJson(w, http.StatusUnauthorized, errorOutput{Error: err.Error()})
+		}
+	}
+}
+
+func (s *Server) authenticateGrpc(ctx context.Context, analyze bool) error {
+	if s.auth == nil {
+		return nil
+	}
+
+	key := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(apiKeyHeader)); len(values) != 0 {
+			key = values[0]
+		}
+		if values := md.Get("authorization"); len(values) != 0 {
+			if bearer, ok := strings.CutPrefix(values[0], "Bearer "); ok {
+				key = bearer
+			}
+		}
+	}
+
+	switch err := s.auth.check(key, analyze); err {
+	case nil:
+		return nil
+	case errAnalyzeDenied:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errRateLimited:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+}
+
+// Return the number of seconds before the next accepted query with key.
+func (auth *Authenticator) retryAfter(key string) int {
+	limiter := auth.grants[hashKey(key)].limiter
+	if limiter == nil || limiter.Limit() <= 0 {
+		return 1
+	}
+	return max(1, int(math.Ceil(1/float64(limiter.Limit()))))
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newLimiter(perMinute int) *rate.Limiter {
+	if perMinute == 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(float64(perMinute)/60), perMinute)
+}
@@ -0,0 +1,147 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Allow the browser queries from origins ("*" for any), cross-origin queries are refused by default.
+// Must be called before Handler.
+func (s *Server) SetAllowedOrigins(origins []string) {
+	s.allowedOrigins = origins
+}
+
+// Allow the caching of GET query answers for maxAge (0, the default, requires a revalidation with the ETag).
+// Must be called before Handler.
+func (s *Server) SetCacheMaxAge(maxAge time.Duration) {
+	s.cacheMaxAge = maxAge
+}
+
+// Add the CORS headers for the allowed origins and answer the preflight requests.
+func (s *Server) cors(handler http.Handler) http.Handler {
+	if len(s.allowedOrigins) == 0 {
+		return handler
+	}
+
+	anyOrigin := slices.Contains(s.allowedOrigins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if anyOrigin || slices.Contains(s.allowedOrigins, origin) {
+			header.Set("Access-Control-Allow-Origin", origin)
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				header.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+apiKeyHeader)
+				header.Set("Access-Control-Max-Age", "86400")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Add Cache-Control and ETag headers to successful GET answers, and answer 304 when the If-None-Match header matches.
+func (s *Server) cache(handler http.HandlerFunc) http.HandlerFunc {
+	cacheControl := "no-cache"
+	if s.cacheMaxAge > 0 {
+		cacheControl = "max-age=" + strconv.Itoa(int(s.cacheMaxAge.Seconds()))
+	}
+	if s.auth != nil {
+		cacheControl = "private, " + cacheControl
+	} else {
+		cacheControl = "public, " + cacheControl
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Cache-Control", "no-store")
+			handler(w, r)
+			return
+		}
+
+		recorder := &bufferRecorder{header: http.Header{}, status: http.StatusOK}
+		handler(recorder, r)
+
+		header := w.Header()
+		for key, values := range recorder.header {
+			header[key] = values
+		}
+
+		if recorder.status != http.StatusOK {
+			header.Set("Cache-Control", "no-store")
+			w.WriteHeader(recorder.status)
+			w.Write(recorder.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(recorder.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		header.Set("Cache-Control", cacheControl)
+		header.Set("ETag", etag)
+		if matchETag(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(recorder.body.Bytes())
+	}
+}
+
+func matchETag(ifNoneMatch string, etag string) bool {
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+type bufferRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (recorder *bufferRecorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *bufferRecorder) Write(data []byte) (int, error) {
+	return recorder.body.Write(data)
+}
+
+func (recorder *bufferRecorder) WriteHeader(status int) {
+	recorder.status = status
+}
@@ -0,0 +1,167 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const (
+	atomNamespace = "http://www.w3.org/2005/Atom"
+	feedIdPrefix  = "tag:gosince,2024:"
+	issueUrl      = "https://go.dev/issue/"
+	releaseUrl    = "https://go.dev/doc/"
+)
+
+type nextApi struct {
+	files     []versiondb.NextFile
+	withdrawn []versiondb.WithdrawnEntry
+	loadedAt  time.Time
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// List the api of the next Go release in the feed, and the api removed from api/next before the release
+// (see versiondb.RecordNext), nil slices keep only the latest release.
+func (s *Server) SetNext(files []versiondb.NextFile, withdrawn []versiondb.WithdrawnEntry) {
+	s.next.Store(&nextApi{files: files, withdrawn: withdrawn, loadedAt: time.Now()})
+}
+
+// Serve an Atom feed with an entry by file of api/next, by proposal withdrawn before release
+// and by package touched in the latest release.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	vd, loadedAt := s.current(), s.loadedAt()
+	updated := loadedAt
+	var entries []atomEntry
+	if next := s.next.Load(); next != nil {
+		if next.loadedAt.After(updated) {
+			updated = next.loadedAt
+		}
+		for _, file := range next.files {
+			entries = append(entries, atomEntry{
+				Id:      feedIdPrefix + "next/" + file.Issue(),
+				Title:   "Proposal " + file.Issue() + " for the next Go release",
+				Updated: next.loadedAt.UTC().Format(time.RFC3339),
+				Link:    atomLink{Href: issueUrl + file.Issue()},
+				Content: atomContent{Type: "text", Text: feedContent(file.Entries, "next")},
+			})
+		}
+
+		var issues []string
+		byIssue := map[string][]string{}
+		for _, withdrawn := range next.withdrawn {
+			if _, ok := byIssue[withdrawn.Issue]; !ok {
+				issues = append(issues, withdrawn.Issue)
+			}
+			byIssue[withdrawn.Issue] = append(byIssue[withdrawn.Issue], withdrawn.Entry+" (withdrawn before release)\n")
+		}
+		for _, issue := range issues {
+			entries = append(entries, atomEntry{
+				Id:      feedIdPrefix + "withdrawn/" + issue,
+				Title:   "Proposal " + issue + " withdrawn before release",
+				Updated: next.loadedAt.UTC().Format(time.RFC3339),
+				Link:    atomLink{Href: issueUrl + issue},
+				Content: atomContent{Type: "text", Text: strings.Join(byIssue[issue], "")},
+			})
+		}
+	}
+
+	if versions := vd.Versions(); len(versions) > 1 {
+		latest := versions[len(versions)-1]
+		diff, err := vd.Diff(versions[len(versions)-2], latest, "")
+		if err == nil {
+			err = versiondb.SortEntries(diff, versiondb.SortPackage)
+		}
+		if err != nil {
+			writeResult(w, nil, err)
+			return
+		}
+
+		var pkgs []string
+		byPkg := map[string][][3]string{}
+		for _, entry := range diff {
+			pkg, _, _ := strings.Cut(entry[0], " ")
+			if _, ok := byPkg[pkg]; !ok {
+				pkgs = append(pkgs, pkg)
+			}
+			byPkg[pkg] = append(byPkg[pkg], entry)
+		}
+
+		for _, pkg := range pkgs {
+			entries = append(entries, atomEntry{
+				Id:      feedIdPrefix + latest + "/" + pkg,
+				Title:   pkg + " in " + latest,
+				Updated: loadedAt.UTC().Format(time.RFC3339),
+				Link:    atomLink{Href: releaseUrl + latest},
+				Content: atomContent{Type: "text", Text: feedContent(byPkg[pkg], latest)},
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(atomFeed{
+		Xmlns: atomNamespace, Id: feedIdPrefix + "feed", Title: "New api of the Go standard library",
+		Updated: updated.UTC().Format(time.RFC3339), Links: []atomLink{{Href: r.URL.Path, Rel: "self"}}, Entries: entries,
+	})
+}
+
+// Return one line by entry, like "net/http Client.Do" or "reflect SliceHeader (deprecated)" when deprecated in version.
+func feedContent(entries [][3]string, version string) string {
+	var builder strings.Builder
+	for _, entry := range entries {
+		builder.WriteString(entry[0])
+		if entry[2] == version {
+			builder.WriteString(" (deprecated)")
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
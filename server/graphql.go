@@ -0,0 +1,446 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	maxGraphqlBody   = 1 << 16
+	maxGraphqlDepth  = 4  // nested selections, like release { added { package { path } } }
+	maxGraphqlFields = 64 // selected fields, with the fragments expanded
+)
+
+var (
+	errGraphqlDepth  = fmt.Errorf("query deeper than %d levels", maxGraphqlDepth)
+	errGraphqlFields = fmt.Errorf("query selecting more than %d fields", maxGraphqlFields)
+)
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type graphqlPackage struct {
+	path     string
+	versions [2]string
+}
+
+type graphqlSymbol struct {
+	pkg      string
+	name     string
+	versions [2]string
+}
+
+type graphqlRelease struct {
+	version string
+}
+
+// Build the GraphQL schema, with Package, Symbol and Release types, on top of the database.
+func (s *Server) graphqlSchema() (graphql.Schema, error) {
+	var packageType, symbolType, releaseType *graphql.Object
+
+	packageType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Package",
+		Description: "Standard library package.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"path": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(graphqlPackage).path, nil
+				}},
+				"addedIn": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(graphqlPackage).versions[0], nil
+				}},
+				"deprecatedIn": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return nullable(p.Source.(graphqlPackage).versions[1]), nil
+				}},
+				"symbols": &graphql.Field{Type: listOf(symbolType), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return s.graphqlSymbols(p.Source.(graphqlPackage).path, func([2]string) bool { return true })
+				}},
+				"releases": &graphql.Field{
+					Type:        listOf(releaseType),
+					Description: "Releases adding or deprecating api of the package.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return s.graphqlPackageReleases(p.Source.(graphqlPackage).path)
+					},
+				},
+			}
+		}),
+	})
+
+	symbolType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Symbol",
+		Description: "Exported symbol, method or field of a standard library package.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(graphqlSymbol).name, nil
+				}},
+				"package": &graphql.Field{Type: graphql.NewNonNull(packageType), Resolve: func(p graphql.ResolveParams) (any, error) {
+					pkg := p.Source.(graphqlSymbol).pkg
+					versions, err := s.current().Since(pkg, "")
+					return graphqlPackage{path: pkg, versions: versions}, err
+				}},
+				"addedIn": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(graphqlSymbol).versions[0], nil
+				}},
+				"deprecatedIn": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return nullable(p.Source.(graphqlSymbol).versions[1]), nil
+				}},
+				"release": &graphql.Field{Type: releaseType, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return graphqlRelease{version: p.Source.(graphqlSymbol).versions[0]}, nil
+				}},
+				"platforms": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+					Description: "Platforms providing the symbol, null when available everywhere.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						symbol := p.Source.(graphqlSymbol)
+						return s.current().Platforms(symbol.pkg, symbol.name), nil
+					},
+				},
+			}
+		}),
+	})
+
+	packageArgs := graphql.FieldConfigArgument{
+		"pattern": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "*", Description: "path.Match pattern on package paths"},
+	}
+	releaseType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Release",
+		Description: "Go release.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"version": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(graphqlRelease).version, nil
+				}},
+				"added": &graphql.Field{Type: listOf(symbolType), Args: packageArgs, Resolve: func(p graphql.ResolveParams) (any, error) {
+					version := p.Source.(graphqlRelease).version
+					return s.graphqlSymbols(p.Args["pattern"].(string), func(versions [2]string) bool { return versions[0] == version })
+				}},
+				"deprecated": &graphql.Field{Type: listOf(symbolType), Args: packageArgs, Resolve: func(p graphql.ResolveParams) (any, error) {
+					version := p.Source.(graphqlRelease).version
+					return s.graphqlSymbols(p.Args["pattern"].(string), func(versions [2]string) bool { return versions[1] == version })
+				}},
+				"packages": &graphql.Field{
+					Type:        listOf(packageType),
+					Description: "Packages with api added or deprecated in the release.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return s.graphqlReleasePackages(p.Source.(graphqlRelease).version)
+					},
+				},
+			}
+		}),
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"package": &graphql.Field{
+				Type: packageType,
+				Args: graphql.FieldConfigArgument{"path": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					path := p.Args["path"].(string)
+					versions, err := s.current().Since(path, "")
+					if err != nil {
+						return nil, err
+					}
+					return graphqlPackage{path: strings.ToLower(path), versions: versions}, nil
+				},
+			},
+			"packages": &graphql.Field{
+				Type: listOf(packageType),
+				Args: packageArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return s.graphqlPackages(p.Args["pattern"].(string))
+				},
+			},
+			"symbol": &graphql.Field{
+				Type: symbolType,
+				Args: graphql.FieldConfigArgument{
+					"package": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"name":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "<sym>[.<methodOrField>]"},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					pkg, name := p.Args["package"].(string), p.Args["name"].(string)
+					versions, err := s.current().Since(pkg, name)
+					if err != nil {
+						return nil, err
+					}
+					return graphqlSymbol{pkg: strings.ToLower(pkg), name: name, versions: versions}, nil
+				},
+			},
+			"search": &graphql.Field{
+				Type: listOf(symbolType),
+				Args: graphql.FieldConfigArgument{"query": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					entries := s.current().Search(p.Args["query"].(string))
+					if err := versiondb.SortEntries(entries, versiondb.SortName); err != nil {
+						return nil, err
+					}
+
+					symbols := make([]graphqlSymbol, 0, len(entries))
+					for _, entry := range entries {
+						pkg, name, _ := strings.Cut(entry[0], " ")
+						symbols = append(symbols, graphqlSymbol{pkg: pkg, name: name, versions: [2]string{entry[1], entry[2]}})
+					}
+					return symbols, nil
+				},
+			},
+			"release": &graphql.Field{
+				Type: releaseType,
+				Args: graphql.FieldConfigArgument{"version": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					version := versiondb.NormalizeVersion(p.Args["version"].(string))
+					if !slices.Contains(s.current().Versions(), version) {
+						return nil, versiondb.ErrUnknownVersion
+					}
+					return graphqlRelease{version: version}, nil
+				},
+			},
+			"releases": &graphql.Field{
+				Type: listOf(releaseType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					versions := s.current().Versions()
+					releases := make([]graphqlRelease, 0, len(versions))
+					for _, version := range versions {
+						releases = append(releases, graphqlRelease{version: version})
+					}
+					return releases, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// Return the symbols (package itself excluded) of the packages matching pattern whose versions satisfy keep.
+func (s *Server) graphqlSymbols(pattern string, keep func([2]string) bool) ([]graphqlSymbol, error) {
+	entries, err := s.current().List(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []graphqlSymbol
+	for _, entry := range entries {
+		pkg, name, ok := strings.Cut(entry[0], " ")
+		if versions := [2]string{entry[1], entry[2]}; ok && keep(versions) {
+			symbols = append(symbols, graphqlSymbol{pkg: pkg, name: name, versions: versions})
+		}
+	}
+	slices.SortFunc(symbols, func(a graphqlSymbol, b graphqlSymbol) int {
+		if res := strings.Compare(a.pkg, b.pkg); res != 0 {
+			return res
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return symbols, nil
+}
+
+func (s *Server) graphqlPackages(pattern string) ([]graphqlPackage, error) {
+	entries, err := s.current().List(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []graphqlPackage
+	for _, entry := range entries {
+		if !strings.Contains(entry[0], " ") {
+			pkgs = append(pkgs, graphqlPackage{path: entry[0], versions: [2]string{entry[1], entry[2]}})
+		}
+	}
+	slices.SortFunc(pkgs, func(a graphqlPackage, b graphqlPackage) int {
+		return strings.Compare(a.path, b.path)
+	})
+	return pkgs, nil
+}
+
+func (s *Server) graphqlPackageReleases(pkg string) ([]graphqlRelease, error) {
+	vd := s.current()
+	entries, err := vd.List(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	touched := map[string]struct{}{}
+	for _, entry := range entries {
+		for _, version := range entry[1:] {
+			if version != "" {
+				touched[version] = struct{}{}
+			}
+		}
+	}
+
+	var releases []graphqlRelease
+	for _, version := range vd.Versions() {
+		if _, ok := touched[version]; ok {
+			releases = append(releases, graphqlRelease{version: version})
+		}
+	}
+	return releases, nil
+}
+
+func (s *Server) graphqlReleasePackages(version string) ([]graphqlPackage, error) {
+	vd := s.current()
+	entries, err := vd.List("*")
+	if err != nil {
+		return nil, err
+	}
+
+	touched := map[string]struct{}{}
+	for _, entry := range entries {
+		if entry[1] == version || entry[2] == version {
+			pkg, _, _ := strings.Cut(entry[0], " ")
+			touched[pkg] = struct{}{}
+		}
+	}
+
+	pkgs := make([]graphqlPackage, 0, len(touched))
+	for pkg := range touched {
+		versions, _ := vd.Since(pkg, "")
+		pkgs = append(pkgs, graphqlPackage{path: pkg, versions: versions})
+	}
+	slices.SortFunc(pkgs, func(a graphqlPackage, b graphqlPackage) int {
+		return strings.Compare(a.path, b.path)
+	})
+	return pkgs, nil
+}
+
+// Return the handler of the GraphQL endpoint, queries are accepted as GET parameter or POST JSON body.
+func graphqlHandler(schema graphql.Schema) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphqlBody)).Decode(&req); err != nil {
+				status := http.StatusBadRequest
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				writeJson(w, status, errorOutput{Error: err.Error()})
+				return
+			}
+		} else {
+			query := r.URL.Query()
+			req.Query, req.OperationName = query.Get("query"), query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					writeJson(w, http.StatusBadRequest, errorOutput{Error: err.Error()})
+					return
+				}
+			}
+		}
+
+		// the relations are recursive (like Package.symbols and Symbol.package), each level can walk the whole database
+		if err := checkGraphqlLimits(req.Query); err != nil {
+			writeJson(w, http.StatusBadRequest, errorOutput{Error: err.Error()})
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema: schema, RequestString: req.Query, VariableValues: req.Variables, OperationName: req.OperationName,
+			Context: r.Context(),
+		})
+		writeJson(w, http.StatusOK, result)
+	}
+}
+
+// Fail when the query is deeper than maxGraphqlDepth or selects more than maxGraphqlFields fields, a query which
+// does not parse is left to graphql.Do (for its error message).
+func checkGraphqlLimits(query string) error {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	fragments := map[string]*ast.SelectionSet{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			fragments[fragment.Name.Value] = fragment.SelectionSet
+		}
+	}
+
+	fields := 0
+	for _, definition := range document.Definitions {
+		if operation, ok := definition.(*ast.OperationDefinition); ok {
+			if err = checkSelectionLimits(operation.SelectionSet, fragments, map[string]bool{}, 1, &fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Count the fields of the selection set (at depth) in fields, expanding the fragment spreads (skipping the ones
+// already expanded on the path, a cycle is reported by graphql.Do).
+func checkSelectionLimits(selectionSet *ast.SelectionSet, fragments map[string]*ast.SelectionSet, expanding map[string]bool, depth int, fields *int) error {
+	if selectionSet == nil {
+		return nil
+	}
+	if depth > maxGraphqlDepth {
+		return errGraphqlDepth
+	}
+
+	for _, selection := range selectionSet.Selections {
+		var err error
+		switch typed := selection.(type) {
+		case *ast.Field:
+			if *fields++; *fields > maxGraphqlFields {
+				return errGraphqlFields
+			}
+			err = checkSelectionLimits(typed.SelectionSet, fragments, expanding, depth+1, fields)
+		case *ast.InlineFragment:
+			err = checkSelectionLimits(typed.SelectionSet, fragments, expanding, depth, fields)
+		case *ast.FragmentSpread:
+			if typed.Name == nil || expanding[typed.Name.Value] {
+				continue
+			}
+			expanding[typed.Name.Value] = true
+			err = checkSelectionLimits(fragments[typed.Name.Value], fragments, expanding, depth, fields)
+			delete(expanding, typed.Name.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listOf(t graphql.Type) *graphql.List {
+	return graphql.NewList(graphql.NewNonNull(t))
+}
+
+func nullable(version string) any {
+	if version == "" {
+		return nil
+	}
+	return version
+}
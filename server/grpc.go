@@ -0,0 +1,108 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+
+	"github.com/dvaumoron/gosince/gosincepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type grpcService struct {
+	gosincepb.UnimplementedGosinceServer
+	s *Server
+}
+
+// Register the gRPC service on registrar.
+func (s *Server) RegisterGrpc(registrar grpc.ServiceRegistrar) {
+	gosincepb.RegisterGosinceServer(registrar, grpcService{s: s})
+}
+
+func (service grpcService) Since(_ context.Context, req *gosincepb.SinceRequest) (*gosincepb.Entry, error) {
+	entry, err := service.s.since(req.GetPackage(), req.GetSymbol(), req.GetGoos(), req.GetGoarch())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPbEntry(entry), nil
+}
+
+func (service grpcService) Search(_ context.Context, req *gosincepb.SearchRequest) (*gosincepb.Entries, error) {
+	entries, err := service.s.search(req.GetQuery(), req.GetSort())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPbEntries(entries), nil
+}
+
+func (service grpcService) Diff(_ context.Context, req *gosincepb.DiffRequest) (*gosincepb.Entries, error) {
+	entries, err := service.s.diff(req.GetFrom(), req.GetTo(), req.GetPackagePattern(), req.GetSort())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPbEntries(entries), nil
+}
+
+func (service grpcService) Analyze(_ context.Context, req *gosincepb.AnalyzeRequest) (*gosincepb.AnalyzeResponse, error) {
+	result, err := service.s.analyze(AnalyzeRequest{
+		Dir: req.GetDir(), Patterns: req.GetPatterns(), Go: req.GetGo(), Platforms: req.GetPlatforms(), Tags: req.GetTags(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	usages := make([]*gosincepb.Usage, 0, len(result.Usages))
+	for _, usage := range result.Usages {
+		usages = append(usages, &gosincepb.Usage{
+			File: usage.File, Line: int32(usage.Line), Column: int32(usage.Column),
+			Package: usage.Package, Symbol: usage.Symbol, AddedIn: usage.AddedIn, DeprecatedIn: usage.DeprecatedIn,
+			Configs: usage.Configs,
+		})
+	}
+	return &gosincepb.AnalyzeResponse{MinVersion: result.MinVersion, Usages: usages}, nil
+}
+
+func grpcError(err error) error {
+	switch {
+	case isNotFound(err):
+		return status.Error(codes.NotFound, err.Error())
+	case isInvalid(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case isForbidden(err):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toPbEntry(entry Entry) *gosincepb.Entry {
+	return &gosincepb.Entry{
+		Package: entry.Package, Symbol: entry.Symbol, AddedIn: entry.AddedIn, DeprecatedIn: entry.DeprecatedIn,
+		Platforms: entry.Platforms,
+	}
+}
+
+func toPbEntries(entries []Entry) *gosincepb.Entries {
+	res := make([]*gosincepb.Entry, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, toPbEntry(entry))
+	}
+	return &gosincepb.Entries{Entries: res}
+}
@@ -0,0 +1,161 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+)
+
+const maxAnalyzeBody = 1 << 20
+
+var (
+	//go:embed openapi.yaml
+	openapiSpec []byte
+
+	//go:embed ui
+	uiFiles embed.FS
+)
+
+type errorOutput struct {
+	Error string `json:"error"`
+}
+
+// Return the handler of the HTTP api (and GraphQL endpoint).
+func (s *Server) Handler() (http.Handler, error) {
+	schema, err := s.graphqlSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	handleQuery := func(pattern string, handler http.HandlerFunc) {
+		mux.Handle(pattern, s.metrics.instrument(s.authenticate(s.cache(s.whenReady(handler)), false)))
+	}
+	handleQuery("GET /api/since", s.handleSince)
+	handleQuery("GET /api/search", s.handleSearch)
+	handleQuery("GET /api/diff", s.handleDiff)
+	handleQuery("GET /api/list", s.handleList)
+	handleQuery("GET /api/versions", s.handleVersions)
+	mux.Handle("POST /api/analyze", s.metrics.instrument(s.authenticate(s.cache(s.whenReady(s.handleAnalyze)), true)))
+	handleQuery("GET /feed.atom", s.handleFeed)
+	handleQuery("GET /graphql", graphqlHandler(schema))
+	handleQuery("POST /graphql", graphqlHandler(schema))
+	mux.Handle("GET /metrics", s.metrics.handler())
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.Ready() {
+			http.Error(w, errNotReady.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openapiSpec)
+	})
+
+	uiRoot, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		return nil, err
+	}
+	mux.Handle("GET /", http.FileServerFS(uiRoot))
+	return s.cors(mux), nil
+}
+
+func (s *Server) handleSince(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	entry, err := s.since(query.Get("pkg"), query.Get("symbol"), query.Get("goos"), query.Get("goarch"))
+	writeResult(w, entry, err)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	entries, err := s.search(query.Get("q"), query.Get("sort"))
+	writeResult(w, entries, err)
+}
+
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	entries, err := s.diff(query.Get("from"), query.Get("to"), query.Get("pkg"), query.Get("sort"))
+	writeResult(w, entries, err)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	entries, err := s.list(query.Get("pkg"), query.Get("sort"))
+	writeResult(w, entries, err)
+}
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, s.current().Versions())
+}
+
+func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	var req AnalyzeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAnalyzeBody)).Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJson(w, status, errorOutput{Error: err.Error()})
+		return
+	}
+
+	result, err := s.analyze(req)
+	writeResult(w, result, err)
+}
+
+// Answer with a 503 status until the database is loaded.
+func (s *Server) whenReady(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.Ready() {
+			writeJson(w, http.StatusServiceUnavailable, errorOutput{Error: errNotReady.Error()})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func writeResult(w http.ResponseWriter, result any, err error) {
+	switch {
+	case err == nil:
+		writeJson(w, http.StatusOK, result)
+	case isNotFound(err):
+		writeJson(w, http.StatusNotFound, errorOutput{Error: err.Error()})
+	case isForbidden(err):
+		writeJson(w, http.StatusForbidden, errorOutput{Error: err.Error()})
+	case isInvalid(err):
+		writeJson(w, http.StatusBadRequest, errorOutput{Error: err.Error()})
+	default:
+		writeJson(w, http.StatusInternalServerError, errorOutput{Error: err.Error()})
+	}
+}
+
+func writeJson(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,121 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type metrics struct {
+	registry       *prometheus.Registry
+	queries        *prometheus.CounterVec
+	cacheHits      prometheus.Counter
+	cacheMisses    prometheus.Counter
+	downloadErrors prometheus.Counter
+	loadDuration   prometheus.Gauge
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gosince_queries_total",
+			Help: "Number of answered queries by protocol, endpoint and status code.",
+		}, []string{"protocol", "endpoint", "code"}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gosince_cache_hits_total",
+			Help: "Number of api files read from the local cache.",
+		}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gosince_cache_misses_total",
+			Help: "Number of api files missing from the local cache.",
+		}),
+		downloadErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gosince_download_errors_total",
+			Help: "Number of failed api file downloads.",
+		}),
+		loadDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gosince_data_load_duration_seconds",
+			Help: "Duration of the last database load.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.queries, m.cacheHits, m.cacheMisses, m.downloadErrors, m.loadDuration,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+func (m *metrics) observeLoad(stats versiondb.LoadStats) {
+	m.cacheHits.Add(float64(stats.CacheHits))
+	m.cacheMisses.Add(float64(stats.CacheMisses))
+	m.downloadErrors.Add(float64(stats.DownloadErrors))
+	m.loadDuration.Set(stats.Duration.Seconds())
+}
+
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Count the queries answered by handler, labelled with the route pattern.
+func (m *metrics) instrument(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+		_, endpoint := path.Split(r.Pattern) // pattern is like "GET /api/since"
+		m.queries.WithLabelValues("http", endpoint, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+// gRPC interceptor counting the answered queries (and rejecting them when unauthenticated or before the database is loaded).
+func (s *Server) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var resp any
+	_, method := path.Split(info.FullMethod)
+	err := s.authenticateGrpc(ctx, method == "Analyze")
+	if err == nil {
+		err = status.Error(codes.Unavailable, errNotReady.Error())
+		if s.Ready() {
+			resp, err = handler(ctx, req)
+		}
+	}
+	s.metrics.queries.WithLabelValues("grpc", method, status.Code(err).String()).Inc()
+	return resp, err
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,286 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package server answers queries on the version database over HTTP (JSON) and gRPC.
+package server
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+var (
+	errAnalyzeDisabled   = errors.New("analyze disabled : no analyze root on this server")
+	errDirNotAllowed     = errors.New("directory outside of the analyze roots")
+	errMissingParameter  = errors.New("missing parameter")
+	errNotReady          = errors.New("database not loaded yet")
+	errPatternNotAllowed = errors.New("pattern outside of the analyzed directory")
+)
+
+// Package or symbol with its versions.
+type Entry struct {
+	Package      string   `json:"package"`
+	Symbol       string   `json:"symbol,omitempty"`
+	AddedIn      string   `json:"added_in"`
+	DeprecatedIn string   `json:"deprecated_in,omitempty"`
+	Platforms    []string `json:"platforms,omitempty"`
+}
+
+type AnalyzeRequest struct {
+	Dir       string   `json:"dir"`
+	Patterns  []string `json:"patterns,omitempty"`
+	Go        string   `json:"go,omitempty"`
+	Platforms []string `json:"platforms,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+type Usage struct {
+	File         string   `json:"file"`
+	Line         int      `json:"line"`
+	Column       int      `json:"column"`
+	Package      string   `json:"package"`
+	Symbol       string   `json:"symbol,omitempty"`
+	AddedIn      string   `json:"added_in"`
+	DeprecatedIn string   `json:"deprecated_in,omitempty"`
+	Configs      []string `json:"configs,omitempty"`
+}
+
+type AnalyzeResult struct {
+	MinVersion string  `json:"min_version"`
+	Usages     []Usage `json:"usages"`
+}
+
+type Server struct {
+	datas          atomic.Pointer[versiondb.VersionDatas]
+	datasLoadedAt  atomic.Pointer[time.Time]
+	next           atomic.Pointer[nextApi]
+	auth           *Authenticator
+	allowedOrigins []string
+	analyzeRoots   []string
+	cacheMaxAge    time.Duration
+	metrics        *metrics
+}
+
+// Return a server answering the queries once SetDatas has been called.
+func New() *Server {
+	return &Server{metrics: newMetrics()}
+}
+
+// Answer the queries with vd, the server is ready after the first call.
+func (s *Server) SetDatas(vd versiondb.VersionDatas) {
+	loadedAt := time.Now()
+	s.datas.Store(&vd)
+	s.datasLoadedAt.Store(&loadedAt)
+	s.metrics.observeLoad(vd.LoadStats())
+}
+
+// Allow the analysis of the directories under roots, the analyze queries are refused when none is set (default).
+// Must be called before Handler.
+func (s *Server) SetAnalyzeRoots(roots []string) error {
+	s.analyzeRoots = make([]string, 0, len(roots))
+	for _, root := range roots {
+		resolved, err := resolveDir(root)
+		if err != nil {
+			return err
+		}
+		s.analyzeRoots = append(s.analyzeRoots, resolved)
+	}
+	return nil
+}
+
+func (s *Server) Ready() bool {
+	return s.datas.Load() != nil
+}
+
+// Return the time of the last SetDatas call.
+func (s *Server) loadedAt() time.Time {
+	if loadedAt := s.datasLoadedAt.Load(); loadedAt != nil {
+		return *loadedAt
+	}
+	return time.Time{}
+}
+
+// Return the database to use for a query (empty before SetDatas).
+func (s *Server) current() versiondb.VersionDatas {
+	if vd := s.datas.Load(); vd != nil {
+		return *vd
+	}
+	return versiondb.VersionDatas{}
+}
+
+func (s *Server) since(pkg string, symbol string, goos string, goarch string) (Entry, error) {
+	if pkg == "" {
+		return Entry{}, errMissingParameter
+	}
+
+	vd := s.current()
+	versions, err := vd.SinceOn(pkg, symbol, goos, goarch)
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{Package: pkg, Symbol: symbol, AddedIn: versions[0], DeprecatedIn: versions[1], Platforms: vd.Platforms(pkg, symbol)}, nil
+}
+
+func (s *Server) search(query string, sortOrder string) ([]Entry, error) {
+	if query == "" {
+		return nil, errMissingParameter
+	}
+	vd := s.current()
+	return toEntries(vd, vd.Search(query), sortOrder)
+}
+
+func (s *Server) diff(from string, to string, pattern string, sortOrder string) ([]Entry, error) {
+	vd := s.current()
+	entries, err := vd.Diff(from, to, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return toEntries(vd, entries, sortOrder)
+}
+
+func (s *Server) list(pattern string, sortOrder string) ([]Entry, error) {
+	if pattern == "" {
+		return nil, errMissingParameter
+	}
+
+	vd := s.current()
+	entries, err := vd.List(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, versiondb.ErrUnknownPackage
+	}
+	return toEntries(vd, entries, sortOrder)
+}
+
+func (s *Server) analyze(req AnalyzeRequest) (AnalyzeResult, error) {
+	if len(s.analyzeRoots) == 0 {
+		return AnalyzeResult{}, errAnalyzeDisabled
+	}
+	if req.Dir == "" {
+		return AnalyzeResult{}, errMissingParameter
+	}
+
+	dir, err := s.allowedDir(req.Dir)
+	if err != nil {
+		return AnalyzeResult{}, err
+	}
+
+	patterns := req.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	for _, pattern := range patterns {
+		if !localPattern(pattern) {
+			return AnalyzeResult{}, errPatternNotAllowed
+		}
+	}
+
+	analysisConf := analysis.Config{Dir: dir, Platforms: req.Platforms, Tags: req.Tags}
+	usages, err := analysis.Run(s.current(), analysisConf, patterns...)
+	if err != nil {
+		return AnalyzeResult{}, err
+	}
+
+	minVersion, _ := analysis.Minimum(usages)
+	if target := versiondb.NormalizeVersion(req.Go); target != "" {
+		usages = analysis.Newer(usages, target)
+	}
+
+	res := AnalyzeResult{MinVersion: minVersion, Usages: make([]Usage, 0, len(usages))}
+	for _, usage := range usages {
+		res.Usages = append(res.Usages, Usage{
+			File: usage.Position.Filename, Line: usage.Position.Line, Column: usage.Position.Column,
+			Package: usage.Package, Symbol: usage.Symbol, AddedIn: usage.AddedIn, DeprecatedIn: usage.DeprecatedIn,
+			Configs: usage.Configs,
+		})
+	}
+	return res, nil
+}
+
+// Return dir resolved (absolute, without symbolic link) when it is under one of the analyze roots.
+func (s *Server) allowedDir(dir string) (string, error) {
+	if !filepath.IsAbs(dir) {
+		return "", errDirNotAllowed
+	}
+
+	resolved, err := resolveDir(dir)
+	if err != nil {
+		return "", errDirNotAllowed
+	}
+	for _, root := range s.analyzeRoots {
+		if rel, err := filepath.Rel(root, resolved); err == nil && filepath.IsLocal(rel) {
+			return resolved, nil
+		}
+	}
+	return "", errDirNotAllowed
+}
+
+// Return false when pattern (an import path or a relative directory, optionally after "file=") can reach outside
+// of the analyzed directory.
+func localPattern(pattern string) bool {
+	pattern = filepath.ToSlash(strings.TrimPrefix(pattern, "file="))
+	return !filepath.IsAbs(pattern) && !strings.HasPrefix(pattern, "/") && !slices.Contains(strings.Split(pattern, "/"), "..")
+}
+
+func resolveDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+func toEntries(vd versiondb.VersionDatas, entries [][3]string, sortOrder string) ([]Entry, error) {
+	if sortOrder == "" {
+		sortOrder = versiondb.SortVersion
+	}
+	if err := versiondb.SortEntries(entries, sortOrder); err != nil {
+		return nil, err
+	}
+
+	res := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		pkg, symbol, _ := strings.Cut(entry[0], " ")
+		res = append(res, Entry{Package: pkg, Symbol: symbol, AddedIn: entry[1], DeprecatedIn: entry[2], Platforms: vd.Platforms(pkg, symbol)})
+	}
+	return res, nil
+}
+
+// Return true when err comes from a query on an unknown package, symbol or platform.
+func isNotFound(err error) bool {
+	return errors.Is(err, versiondb.ErrUnknownPackage) || errors.Is(err, versiondb.ErrUnknownSymbol) || errors.Is(err, versiondb.ErrUnavailablePlatform)
+}
+
+// Return true when err comes from an analyze query refused by the configuration of the server.
+func isForbidden(err error) bool {
+	return errors.Is(err, errAnalyzeDisabled) || errors.Is(err, errDirNotAllowed) || errors.Is(err, errPatternNotAllowed)
+}
+
+// Return true when err comes from an invalid query.
+func isInvalid(err error) bool {
+	return errors.Is(err, errMissingParameter) || errors.Is(err, versiondb.ErrUnknownVersion) || errors.Is(err, versiondb.ErrUnknownSort)
+}
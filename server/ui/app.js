@@ -0,0 +1,186 @@
+"use strict";
+
+const views = ["search-view", "package-view", "diff-view"];
+const results = document.getElementById("results");
+const message = document.getElementById("message");
+
+async function fetchJson(path, params) {
+  const query = new URLSearchParams();
+  for (const [key, value] of Object.entries(params)) {
+    if (value) {
+      query.set(key, value);
+    }
+  }
+  const headers = {};
+  const apiKey = localStorage.getItem("apiKey");
+  if (apiKey) {
+    headers["X-Api-Key"] = apiKey;
+  }
+
+  const response = await fetch(path + "?" + query, { headers: headers });
+  const body = await response.json();
+  if (response.status === 401) {
+    const newKey = prompt("This server requires an api key :");
+    if (newKey) {
+      localStorage.setItem("apiKey", newKey);
+      return fetchJson(path, params);
+    }
+  }
+  if (!response.ok) {
+    throw new Error(body.error);
+  }
+  return body;
+}
+
+function show(view) {
+  for (const id of views) {
+    document.getElementById(id).hidden = id !== view;
+  }
+  results.replaceChildren();
+  message.textContent = "";
+}
+
+function cell(row, text, href) {
+  const td = row.insertCell();
+  if (href) {
+    const link = document.createElement("a");
+    link.href = href;
+    link.textContent = text;
+    td.append(link);
+  } else {
+    td.textContent = text || "";
+  }
+  return td;
+}
+
+function entryTable(entries, withPackage) {
+  const table = document.createElement("table");
+  const header = table.createTHead().insertRow();
+  for (const title of [withPackage ? "Package" : null, "Symbol", "Added in", "Deprecated in", "Platforms"]) {
+    if (title) {
+      const th = document.createElement("th");
+      th.textContent = title;
+      header.append(th);
+    }
+  }
+
+  const body = table.createTBody();
+  for (const entry of entries) {
+    const row = body.insertRow();
+    if (withPackage) {
+      cell(row, entry.package, "#/package/" + entry.package);
+    }
+    cell(row, entry.symbol || "(package)");
+    cell(row, entry.added_in);
+    cell(row, entry.deprecated_in).className = "deprecated";
+    cell(row, (entry.platforms || []).join(", "));
+  }
+  return table;
+}
+
+async function searchView(query) {
+  show("search-view");
+  const input = document.getElementById("search-input");
+  input.value = query;
+  if (!query) {
+    return;
+  }
+
+  try {
+    const entries = query.includes("/") || !query.match(/[A-Z.]/)
+      ? await fetchJson("api/list", { pkg: query, sort: "name" }).catch(() => fetchJson("api/search", { q: query, sort: "name" }))
+      : await fetchJson("api/search", { q: query, sort: "name" });
+    if (entries.length === 0) {
+      message.textContent = "No result.";
+      return;
+    }
+    results.append(entryTable(entries, true));
+  } catch (error) {
+    message.textContent = error.message;
+  }
+}
+
+async function packageView(pkg) {
+  show("package-view");
+  document.getElementById("package-title").textContent = pkg;
+  try {
+    const entries = await fetchJson("api/list", { pkg: pkg, sort: "version" });
+    const byVersion = new Map();
+    for (const entry of entries) {
+      for (const version of [entry.added_in, entry.deprecated_in]) {
+        if (version && !byVersion.has(version)) {
+          byVersion.set(version, []);
+        }
+      }
+      byVersion.get(entry.added_in).push(entry);
+      if (entry.deprecated_in) {
+        byVersion.get(entry.deprecated_in).push(entry);
+      }
+    }
+
+    const versions = await fetchJson("api/versions", {});
+    for (const version of versions.filter((v) => byVersion.has(v)).reverse()) {
+      const title = document.createElement("h3");
+      title.textContent = version;
+      results.append(title, entryTable(byVersion.get(version), false));
+    }
+  } catch (error) {
+    message.textContent = error.message;
+  }
+}
+
+async function diffView(from, to, pkg) {
+  show("diff-view");
+  const fromSelect = document.getElementById("diff-from");
+  const toSelect = document.getElementById("diff-to");
+  try {
+    const versions = await fetchJson("api/versions", {});
+    for (const select of [fromSelect, toSelect]) {
+      select.replaceChildren(...versions.map((version) => new Option(version, version)));
+    }
+    fromSelect.value = from || versions[Math.max(0, versions.length - 2)];
+    toSelect.value = to || versions[versions.length - 1];
+    document.getElementById("diff-pkg").value = pkg;
+
+    if (from && to) {
+      const entries = await fetchJson("api/diff", { from: from, to: to, pkg: pkg, sort: "package" });
+      if (entries.length === 0) {
+        message.textContent = "No change.";
+        return;
+      }
+      results.append(entryTable(entries, true));
+    }
+  } catch (error) {
+    message.textContent = error.message;
+  }
+}
+
+function route() {
+  const [path, rawQuery] = location.hash.slice(1).split("?");
+  const params = new URLSearchParams(rawQuery);
+  if (path.startsWith("/package/")) {
+    packageView(decodeURIComponent(path.slice("/package/".length)));
+  } else if (path.startsWith("/diff")) {
+    diffView(params.get("from"), params.get("to"), params.get("pkg") || "");
+  } else {
+    searchView(params.get("q") || "");
+  }
+}
+
+document.getElementById("search-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  location.hash = "#/?q=" + encodeURIComponent(document.getElementById("search-input").value.trim());
+});
+
+document.getElementById("diff-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const params = new URLSearchParams({
+    from: document.getElementById("diff-from").value,
+    to: document.getElementById("diff-to").value,
+    pkg: document.getElementById("diff-pkg").value.trim(),
+  });
+  location.hash = "#/diff?" + params;
+});
+
+window.addEventListener("hashchange", route);
+route();
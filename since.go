@@ -22,13 +22,19 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/dvaumoron/gosince/analyzer"
 	"github.com/dvaumoron/gosince/cmd"
+	"golang.org/x/tools/go/analysis/unitchecker"
 )
 
 // can be overridden with ldflags
 var version = "dev"
 
 func main() {
+	if analyzer.IsVetToolCall(os.Args[1:]) {
+		unitchecker.Main(analyzer.Analyzers()...) // does not return
+	}
+
 	if err := cmd.Init(version).Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
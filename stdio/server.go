@@ -0,0 +1,206 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package stdio answers newline delimited JSON requests (lookup, search and resolve) with JSON responses,
+// a lighter alternative to the language server for editor plugins.
+package stdio
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/dvaumoron/gosince/analysis"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+// Stable codes of the response errors.
+const (
+	codeFailure        = "failure"
+	codeInvalidRequest = "invalid_request"
+	codeNoUsage        = "no_usage"
+	codeUnknownMethod  = "unknown_method"
+	codeUnknownPackage = "unknown_package"
+	codeUnknownSymbol  = "unknown_symbol"
+)
+
+var errMissingField = errors.New("missing field")
+
+// Request like {"id":1,"method":"lookup","expr":"net/http.Client.Do"}, {"id":2,"method":"search","query":"Join"}
+// or {"id":3,"method":"resolve","file":"main.go","line":12,"column":21}.
+type request struct {
+	Id     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method"`
+	Expr   string           `json:"expr,omitempty"`   // lookup, in <pkg> or <pkg>.<sym>[.<methodOrField>] form
+	Query  string           `json:"query,omitempty"`  // search, a symbol or Type.Member
+	Prefix bool             `json:"prefix,omitempty"` // search, names starting with the query instead of equal to it
+	Limit  int              `json:"limit,omitempty"`  // search, maximum number of results when positive
+	File   string           `json:"file,omitempty"`   // resolve
+	Line   int              `json:"line,omitempty"`
+	Column int              `json:"column,omitempty"` // in bytes, like go/token
+}
+
+type response struct {
+	Id      *json.RawMessage `json:"id"`
+	Results []result         `json:"results,omitempty"`
+	Hidden  int              `json:"hidden,omitempty"` // search results beyond the limit
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type result struct {
+	Package      string   `json:"package"`
+	Symbol       string   `json:"symbol,omitempty"`
+	AddedIn      string   `json:"added_in"`
+	DeprecatedIn string   `json:"deprecated_in,omitempty"`
+	Replacement  string   `json:"replacement,omitempty"`
+	Platforms    []string `json:"platforms,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	DocUrl       string   `json:"doc_url"`
+	File         string   `json:"file,omitempty"` // resolve only, with the position where the usage starts
+	Line         int      `json:"line,omitempty"`
+	Column       int      `json:"column,omitempty"`
+}
+
+type responseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Answer the requests read from in on out until the end of in, the database stays loaded between requests.
+// Lookups and searches are restricted to the first platform of conf (all platforms when empty),
+// resolutions type check the package of the file with conf.
+func Serve(vd versiondb.VersionDatas, conf analysis.Config, in io.Reader, out io.Writer) error {
+	goos, goarch := "", ""
+	if len(conf.Platforms) != 0 {
+		goos, goarch, _ = strings.Cut(conf.Platforms[0], "/")
+	}
+
+	encoder := json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		resp := response{}
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp.Error = &responseError{Code: codeInvalidRequest, Message: err.Error()}
+		} else {
+			resp = handle(vd, conf, goos, goarch, req)
+			resp.Id = req.Id
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func handle(vd versiondb.VersionDatas, conf analysis.Config, goos string, goarch string, req request) response {
+	switch req.Method {
+	case "lookup":
+		if req.Expr == "" {
+			return errorResponse(codeInvalidRequest, errMissingField, "expr")
+		}
+
+		pkg, symbol := vd.SplitExpr(req.Expr)
+		versions, err := vd.SinceOn(pkg, symbol, goos, goarch)
+		if err != nil {
+			return errorResponse(errorCode(err), err, "")
+		}
+		return response{Results: []result{newResult(vd, pkg, vd.SymbolName(pkg, symbol), versions)}}
+	case "search":
+		if req.Query == "" {
+			return errorResponse(codeInvalidRequest, errMissingField, "query")
+		}
+
+		search := vd.Search
+		if req.Prefix {
+			search = vd.SearchPrefix
+		}
+
+		entries := vd.FilterPlatform(search(req.Query), goos, goarch)
+		if len(entries) == 0 {
+			return errorResponse(codeUnknownSymbol, versiondb.ErrUnknownSymbol, "")
+		}
+		if err := versiondb.SortEntries(entries, versiondb.SortName); err != nil {
+			return errorResponse(codeFailure, err, "")
+		}
+
+		resp := response{}
+		if req.Limit > 0 && len(entries) > req.Limit {
+			entries, resp.Hidden = entries[:req.Limit], len(entries)-req.Limit
+		}
+		resp.Results = make([]result, 0, len(entries))
+		for _, entry := range entries {
+			pkg, symbol, _ := strings.Cut(entry[0], " ")
+			resp.Results = append(resp.Results, newResult(vd, pkg, symbol, [2]string{entry[1], entry[2]}))
+		}
+		return resp
+	case "resolve":
+		if req.File == "" || req.Line <= 0 || req.Column <= 0 {
+			return errorResponse(codeInvalidRequest, errMissingField, "file, line or column")
+		}
+
+		usage, err := analysis.At(vd, conf, req.File, req.Line, req.Column)
+		if err != nil {
+			return errorResponse(errorCode(err), err, "")
+		}
+
+		res := newResult(vd, usage.Package, usage.Symbol, [2]string{usage.AddedIn, usage.DeprecatedIn})
+		res.File, res.Line, res.Column = usage.Position.Filename, usage.Position.Line, usage.Position.Column
+		return response{Results: []result{res}}
+	}
+	return response{Error: &responseError{Code: codeUnknownMethod, Message: "unsupported method " + req.Method}}
+}
+
+func newResult(vd versiondb.VersionDatas, pkg string, symbol string, versions [2]string) result {
+	res := result{
+		Package: pkg, Symbol: symbol, AddedIn: versions[0], DeprecatedIn: versions[1],
+		Platforms: vd.Platforms(pkg, symbol), Summary: vd.Summary(pkg, symbol), DocUrl: vd.DocUrl(pkg, symbol),
+	}
+	if res.DeprecatedIn != "" {
+		res.Replacement = vd.Replacement(pkg, symbol)
+	}
+	return res
+}
+
+func errorResponse(code string, err error, detail string) response {
+	message := err.Error()
+	if detail != "" {
+		message += " : " + detail
+	}
+	return response{Error: &responseError{Code: code, Message: message}}
+}
+
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, versiondb.ErrUnknownPackage):
+		return codeUnknownPackage
+	case errors.Is(err, versiondb.ErrUnknownSymbol):
+		return codeUnknownSymbol
+	case errors.Is(err, analysis.ErrNoUsage):
+		return codeNoUsage
+	}
+	return codeFailure
+}
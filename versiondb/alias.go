@@ -0,0 +1,103 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+type aliasData struct {
+	target  string // as declared, like "fs.FileMode"
+	version string // release of the alias declaration
+	pkg     string // resolved package of target, empty when unresolved (like a generic instantiation)
+	symbol  string // resolved symbol of target
+}
+
+// Type alias declaration, like "type FileMode = fs.FileMode" in package os since go1.16.
+type Alias struct {
+	Target  string // like "io/fs.FileMode", or as declared when the aliased type is not in the database
+	Since   string // release where the type became an alias
+	Package string // package of the aliased type, empty when not in the database
+	Symbol  string
+}
+
+// Return the alias declaration of the type, false when it has never been an alias.
+func (vd VersionDatas) Alias(pkg string, symbol string) (Alias, bool) {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil || data.alias.target == "" {
+		return Alias{}, false
+	}
+
+	alias := data.alias
+	res := Alias{Target: alias.target, Since: alias.version, Package: alias.pkg, Symbol: alias.symbol}
+	if alias.pkg != "" {
+		res.Target = buildDotted(alias.pkg, alias.symbol)
+	}
+	return res, true
+}
+
+// Link the aliases to their aliased type : the package name of the target (like "fs" in "fs.FileMode")
+// is matched against the loaded packages declaring the symbol (the aliasing package excluded).
+func (dl dataLoader) resolveAliases() {
+	byName := map[string][]string{}
+	for pkg := range dl.data {
+		name := PackageName(pkg)
+		byName[name] = append(byName[name], pkg)
+	}
+	for _, pkgs := range byName {
+		slices.Sort(pkgs) // deterministic choice
+	}
+
+	for pkg, pkgSymbols := range dl.data {
+		for symbolLower, data := range pkgSymbols {
+			if data.alias.target == "" {
+				continue
+			}
+
+			pkgName, symbol, ok := strings.Cut(data.alias.target, ".")
+			if !ok || strings.ContainsAny(symbol, "[.") {
+				continue
+			}
+
+			symbolKey := strings.ToLower(symbol)
+			for _, candidate := range byName[pkgName] {
+				if candidate == pkg {
+					continue
+				}
+				if targetData, ok := dl.data[candidate][symbolKey]; ok {
+					data.alias.pkg, data.alias.symbol = candidate, targetData.name
+					pkgSymbols[symbolLower] = data
+					break
+				}
+			}
+		}
+	}
+}
+
+// Return the name of the package declared at pkg (like "json" for "encoding/json/v2"),
+// which is the default local name of its imports.
+func PackageName(pkg string) string {
+	name := path.Base(pkg)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(pkg))
+	}
+	return name
+}
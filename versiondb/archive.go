@@ -0,0 +1,188 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	archiveFile     = "api.tar.zst"
+	archiveManifest = "MANIFEST" // sha256 of the other entries, in the format of sha256sum
+)
+
+var errArchiveManifest = errors.New("archive failure : manifest is not the first entry")
+
+// Cache of the api files in a single zstd-compressed tar archive, starting with a manifest.
+type cacheArchive struct {
+	path      string
+	files     map[string][]byte // by file name (like "go1.21.txt")
+	modTime   time.Time
+	corrupted []string // entries not matching the manifest, dropped on the next write
+	dirty     bool
+}
+
+// Read the archive of the cache directory repoPath, an unreadable archive is replaced by an empty one.
+func openArchive(repoPath string, log verboseLog) *cacheArchive {
+	archive := &cacheArchive{path: filepath.Join(repoPath, archiveFile), files: map[string][]byte{}}
+	if err := archive.read(); err != nil {
+		log("Failed to read", archive.path, ":", err)
+		clear(archive.files)
+	}
+	return archive
+}
+
+func (archive *cacheArchive) read() error {
+	file, err := os.Open(archive.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	archive.modTime = info.ModTime()
+
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer decoder.Close()
+
+	tarReader := tar.NewReader(decoder)
+	header, err := tarReader.Next()
+	if err != nil {
+		return err
+	}
+	if header.Name != archiveManifest {
+		return errArchiveManifest
+	}
+
+	manifest, err := parseSnapshot(tarReader, archiveManifest)
+	if err != nil {
+		return err
+	}
+
+	for {
+		header, err = tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return err
+		}
+		if hashData(data) != manifest[header.Name] {
+			archive.corrupted = append(archive.corrupted, header.Name)
+			archive.dirty = true
+			continue
+		}
+		archive.files[header.Name] = data
+	}
+}
+
+func (archive *cacheArchive) add(fileName string, data []byte) {
+	archive.files[fileName] = data
+	archive.dirty = true
+}
+
+// Write the archive when it has changed, through a temporary file renamed to stay atomic.
+func (archive *cacheArchive) flush() error {
+	if archive == nil || !archive.dirty {
+		return nil
+	}
+
+	manifest := make(map[string]string, len(archive.files))
+	for fileName, data := range archive.files {
+		manifest[fileName] = hashData(data)
+	}
+
+	var buffer bytes.Buffer
+	encoder, err := zstd.NewWriter(&buffer)
+	if err != nil {
+		return err
+	}
+
+	tarWriter := tar.NewWriter(encoder)
+	if err = writeTarEntry(tarWriter, archiveManifest, formatSnapshot(manifest)); err != nil {
+		return err
+	}
+	for _, fileName := range slices.Sorted(maps.Keys(archive.files)) {
+		if err = writeTarEntry(tarWriter, fileName, archive.files[fileName]); err != nil {
+			return err
+		}
+	}
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	if err = encoder.Close(); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(archive.path)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(dir, archiveFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name()) // no effect once renamed
+
+	if err = tmpFile.Chmod(0644); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if _, err = tmpFile.Write(buffer.Bytes()); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpFile.Name(), archive.path); err != nil {
+		return err
+	}
+	archive.dirty = false
+	return nil
+}
+
+func writeTarEntry(tarWriter *tar.Writer, name string, data []byte) error {
+	if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+		return err
+	}
+	_, err := tarWriter.Write(data)
+	return err
+}
@@ -0,0 +1,170 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+//go:generate go run backports/genbackports.go -o backports/backports.txt -curated backports/curated.txt go1.21.13 go1.22.12 go1.23.12 go1.24.13 go1.25.14 go1.26.1 go1.27.1
+
+const backportsFile = "backports.txt"
+
+var (
+	errBackportLine    = errors.New("backport failure : expect a point release line, or an entry prefixed by +")
+	errBackportRelease = errors.New("backport failure : expect a point release (like go1.20.4)")
+
+	//go:embed backports/backports.txt
+	embeddedBackports []byte
+)
+
+// Api of the standard library backported to point releases of major releases older than the one introducing it.
+type BackportDatas struct {
+	releases map[string][]string // point releases by lowercased "pkg" or "pkg symbol" entry, in file order
+}
+
+// Read the backports from the local cache (backports.txt in the cache directory, downloaded again from
+// conf.BackportUrl when older than conf.MaxAge) or download them from conf.BackportUrl, else use the copy shipped
+// with gosince (backports/backports.txt, generated by backports/genbackports.go and completed by backports/curated.txt
+// for the point releases before go1.21). The file lists the entries backported by each point release :
+//
+//	release go1.20.3
+//	+ html/template ErrJSTemplate
+func LoadBackports(conf config.Config) (BackportDatas, error) {
+	filePath := filepath.Join(conf.RepoPath, backportsFile)
+	verboseLog := newVerboseLog(conf)
+	data, err := os.ReadFile(filePath)
+	if err == nil {
+		if info, err := os.Stat(filePath); err == nil && conf.BackportUrl != "" && expired(conf, info.ModTime()) {
+			verboseLog("Revalidate", filePath)
+			if downloaded, err := downloadBackports(conf, filePath); err != nil {
+				verboseLog("Keep the cached", filePath, ":", err)
+			} else if downloaded != nil {
+				data = downloaded
+			}
+		}
+		return parseBackports(data)
+	}
+
+	verboseLog("Failed to read", filePath, ":", err)
+	if conf.BackportUrl == "" || conf.Offline {
+		return parseBackports(embeddedBackports)
+	}
+	if data, err = downloadBackports(conf, filePath); err != nil {
+		return BackportDatas{}, err
+	}
+	if data == nil {
+		return parseBackports(embeddedBackports)
+	}
+	return parseBackports(data)
+}
+
+// Download the backports from conf.BackportUrl and cache them, nil when the file is not found there.
+func downloadBackports(conf config.Config, filePath string) ([]byte, error) {
+	fileUrl, err := url.JoinPath(conf.BackportUrl, backportsFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := DownloadWith(conf.Client, fileUrl, conf.Progress)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(data)) == notFoundBody {
+		return nil, nil
+	}
+	if _, err = parseBackports(data); err != nil {
+		return nil, fmt.Errorf("%s : %w", fileUrl, err)
+	}
+	return data, writeFile(filePath, data)
+}
+
+// Return the point releases (like "go1.20.3") of major releases older than addedIn which received the package pkg
+// (or its symbol, like "Client.Do"), in release order (case is ignored).
+func (bd BackportDatas) Releases(pkg string, symbol string, addedIn string) []string {
+	key := strings.ToLower(pkg)
+	if symbol != "" {
+		key += " " + strings.ToLower(symbol)
+	}
+
+	var res []string
+	for _, release := range bd.releases[key] {
+		if CompareVersion(release, addedIn) < 0 {
+			res = append(res, release)
+		}
+	}
+	slices.SortFunc(res, func(a string, b string) int {
+		if cmp := CompareVersion(a, b); cmp != 0 {
+			return cmp
+		}
+		return patchNumber(a) - patchNumber(b)
+	})
+	return res
+}
+
+func parseBackports(data []byte) (BackportDatas, error) {
+	bd := BackportDatas{releases: map[string][]string{}}
+	release := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if version, ok := strings.CutPrefix(line, targetReleasePrefix); ok {
+			release = strings.TrimSpace(version)
+			if MinorVersion(release) == -1 || patchNumber(release) == 0 {
+				return BackportDatas{}, fmt.Errorf("%s:%d: %w", backportsFile, lineNumber, errBackportRelease)
+			}
+			continue
+		}
+
+		if release == "" || line[0] != '+' {
+			return BackportDatas{}, fmt.Errorf("%s:%d: %w", backportsFile, lineNumber, errBackportLine)
+		}
+		pkg, symbol, _ := strings.Cut(strings.TrimSpace(line[1:]), " ")
+		key := strings.ToLower(pkg)
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			key += " " + strings.ToLower(symbol)
+		}
+		bd.releases[key] = append(bd.releases[key], release)
+	}
+	return bd, scanner.Err()
+}
+
+// Return the patch number of a point release ("go1.20.4" gives 4), 0 when there is none.
+func patchNumber(version string) int {
+	if strings.Count(version, ".") < 2 {
+		return 0
+	}
+	res, _ := strconv.Atoi(version[strings.LastIndexByte(version, '.')+1:])
+	return res
+}
@@ -0,0 +1,91 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+func TestLoadBackports(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	data := "release go1.19.9\n+ html/template ErrJSTemplate\nrelease go1.20.4\n+ html/template ErrJSTemplate\n+ crypto/tls\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/backports.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(data))
+	}))
+	defer server.Close()
+
+	// the shipped data, with the curated entries before go1.21
+	conf := config.Config{RepoPath: t.TempDir()}
+	bd, err := versiondb.LoadBackports(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if releases := bd.Releases("html/template", "ErrJSTemplate", "go1.21"); !slices.Equal(releases, []string{"go1.19.8", "go1.20.3"}) {
+		t.Errorf("Releases() = %v, want [go1.19.8 go1.20.3] from the shipped data", releases)
+	}
+	if releases := bd.Releases("slices", "Concat", "go1.22"); len(releases) != 0 {
+		t.Errorf("Releases() = %v, want none from the shipped data", releases)
+	}
+
+	// downloaded then cached
+	conf.BackportUrl = server.URL
+	if bd, err = versiondb.LoadBackports(conf); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if bd, err = versiondb.LoadBackports(conf); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		pkg     string
+		symbol  string
+		addedIn string
+		want    []string
+	}{
+		{pkg: "html/template", symbol: "ErrJSTemplate", addedIn: "go1.21", want: []string{"go1.19.9", "go1.20.4"}},
+		{pkg: "HTML/Template", symbol: "errjstemplate", addedIn: "go1.20", want: []string{"go1.19.9"}},
+		{pkg: "crypto/tls", addedIn: "go1.21", want: []string{"go1.20.4"}},
+		{pkg: "html/template", symbol: "ErrorCode", addedIn: "go1.9"},
+	} {
+		if releases := bd.Releases(test.pkg, test.symbol, test.addedIn); !slices.Equal(releases, test.want) {
+			t.Errorf("Releases(%q, %q, %q) = %v, want %v", test.pkg, test.symbol, test.addedIn, releases, test.want)
+		}
+	}
+
+	for _, malformed := range []string{"+ crypto/tls\n", "release go1.20\n+ crypto/tls\n", "release go1.20.4\ncrypto/tls\n"} {
+		if err = os.WriteFile(filepath.Join(conf.RepoPath, "backports.txt"), []byte(malformed), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = versiondb.LoadBackports(conf); err == nil {
+			t.Errorf("LoadBackports() of %q succeeded, want a failure", malformed)
+		}
+	}
+}
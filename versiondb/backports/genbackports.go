@@ -0,0 +1,268 @@
+//go:build ignore
+
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Generate backports.txt from the module zips of the Go toolchains (from proxy.golang.org) and the api files of Go :
+//
+//	go run genbackports.go -o backports.txt -curated curated.txt go1.21.13 go1.22.12
+//
+// Each argument is the last point release of a major release, all its point releases are compared to the previous
+// one : an exported declaration of the standard library sources new in a point release is a backport when the api
+// files record it in a later major release. The content of the curated file (the point releases without toolchain
+// zip) is appended as is.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const proxyBase = "https://proxy.golang.org/golang.org/toolchain/@v/v0.0.1-"
+
+var errNoRelease = errors.New("expect the last point releases of the major releases as arguments (like go1.21.13)")
+
+func main() {
+	output := flag.String("o", "backports.txt", "Output file")
+	apiDir := flag.String("api", filepath.Join(build.Default.GOROOT, "api"), "Directory of the api files of Go")
+	curated := flag.String("curated", "", "File of curated entries appended to the generated ones")
+	flag.Parse()
+
+	if err := run(*output, *apiDir, *curated, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(output string, apiDir string, curated string, lastReleases []string) error {
+	vd, err := versiondb.LoadFS(os.DirFS(apiDir))
+	if err != nil {
+		return err
+	}
+
+	if len(lastReleases) == 0 {
+		return errNoRelease
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`# Api of the standard library backported to point releases, for ` + strings.Join(lastReleases, ", ") + ` and their previous point releases.
+#
+# Generated by genbackports.go (go generate in versiondb) from the module zips of golang.org/toolchain on
+# proxy.golang.org : an exported declaration new in the sources of a point release (compared to the previous
+# one) is listed when the api files of Go record it in a later major release.
+`)
+
+	count := 0
+	for _, lastRelease := range lastReleases {
+		major, lastPoint, ok := cutPoint(lastRelease)
+		if !ok {
+			return fmt.Errorf("%w : %q", errNoRelease, lastRelease)
+		}
+
+		previous, err := loadDeclarations(vd, major+".0")
+		if err != nil {
+			return fmt.Errorf("%s.0 : %w", major, err)
+		}
+		for point := 1; point <= lastPoint; point++ {
+			release := major + "." + strconv.Itoa(point)
+			current, err := loadDeclarations(vd, release)
+			if err != nil {
+				return fmt.Errorf("%s : %w", release, err)
+			}
+
+			var backported []string
+			for entry := range current {
+				if previous[entry] {
+					continue
+				}
+				pkg, symbol, _ := strings.Cut(entry, " ")
+				if info, err := vd.SinceInfo(pkg, symbol); err == nil && versiondb.CompareVersion(info.AddedIn, major) > 0 {
+					backported = append(backported, entry)
+				}
+			}
+			count += len(backported)
+			if len(backported) != 0 {
+				fmt.Fprintln(&buffer, "release", release)
+				for _, entry := range slices.Sorted(slices.Values(backported)) {
+					fmt.Fprintln(&buffer, "+", entry)
+				}
+			}
+			previous = current
+		}
+	}
+	if count == 0 {
+		buffer.WriteString("#\n# None of these point releases declares api of a later major release.\n")
+	}
+	if curated != "" {
+		curatedData, err := os.ReadFile(curated)
+		if err != nil {
+			return err
+		}
+		buffer.WriteByte('\n')
+		buffer.Write(curatedData)
+	}
+	return os.WriteFile(output, buffer.Bytes(), 0644)
+}
+
+// Split a point release (like "go1.21.13") into its major release and point number.
+func cutPoint(release string) (string, int, bool) {
+	index := strings.LastIndexByte(release, '.')
+	if index == -1 || versiondb.MinorVersion(release[:index]) == -1 {
+		return "", 0, false
+	}
+	point, err := strconv.Atoi(release[index+1:])
+	return release[:index], point, err == nil && point > 0
+}
+
+// Return the exported declarations (like "net/http Client.Do") of the standard library packages known by the api
+// files, read from the sources of the toolchain zip of the release.
+func loadDeclarations(vd versiondb.VersionDatas, release string) (map[string]bool, error) {
+	data, err := versiondb.DownloadWith(nil, proxyBase+release+".linux-amd64.zip", nil)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	stdPackages := map[string]bool{}
+	for _, pkg := range vd.Packages() {
+		stdPackages[pkg] = true
+	}
+
+	declared := map[string]bool{}
+	fset := token.NewFileSet()
+	prefix := "golang.org/toolchain@v0.0.1-" + release + ".linux-amd64/src/"
+	for _, file := range reader.File {
+		name, ok := strings.CutPrefix(file.Name, prefix)
+		if !ok || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		pkg := path.Dir(name)
+		if !stdPackages[pkg] {
+			continue
+		}
+
+		content, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parser.ParseFile(fset, name, content, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.Name.Name == "main" { // generators
+			continue
+		}
+		for _, decl := range parsed.Decls {
+			for _, symbol := range exportedNames(decl) {
+				declared[pkg+" "+symbol] = true
+			}
+		}
+	}
+	return declared, nil
+}
+
+func readFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// Return the exported names of the declaration, methods and fields as "Type.Member".
+func exportedNames(decl ast.Decl) []string {
+	var names []string
+	switch typed := decl.(type) {
+	case *ast.FuncDecl:
+		if !typed.Name.IsExported() {
+			break
+		}
+		if typed.Recv == nil {
+			names = append(names, typed.Name.Name)
+		} else if typeName := receiverName(typed.Recv.List[0].Type); ast.IsExported(typeName) {
+			names = append(names, typeName+"."+typed.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range typed.Specs {
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				for _, name := range spec.Names {
+					if name.IsExported() {
+						names = append(names, name.Name)
+					}
+				}
+			case *ast.TypeSpec:
+				if !spec.Name.IsExported() {
+					continue
+				}
+				typeName := spec.Name.Name
+				names = append(names, typeName)
+				var members []*ast.Field
+				switch typeExpr := spec.Type.(type) {
+				case *ast.StructType:
+					members = typeExpr.Fields.List
+				case *ast.InterfaceType:
+					members = typeExpr.Methods.List
+				}
+				for _, member := range members {
+					for _, name := range member.Names {
+						if name.IsExported() {
+							names = append(names, typeName+"."+name.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// Return the name of the receiver type, without pointer nor type parameters.
+func receiverName(expr ast.Expr) string {
+	switch typed := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(typed.X)
+	case *ast.IndexExpr:
+		return receiverName(typed.X)
+	case *ast.IndexListExpr:
+		return receiverName(typed.X)
+	case *ast.Ident:
+		return typed.Name
+	}
+	return ""
+}
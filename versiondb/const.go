@@ -0,0 +1,62 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import "strings"
+
+const untypedPrefix = "ideal-"
+
+type constData struct {
+	typeName string // as declared, like "Month" or "ideal-int"
+	value    string
+	varies   bool // the value depends on the platform
+}
+
+// Record the type or the value of a constant declaration (ignored when both are empty).
+func (data *constData) add(typeName string, value string) {
+	if typeName != "" && data.typeName == "" {
+		data.typeName = typeName
+	}
+	switch {
+	case value == "" || data.varies:
+	case data.value == "":
+		data.value = value
+	case data.value != value:
+		data.value, data.varies = "", true
+	}
+}
+
+// Constant declaration, like "const O_APPEND int" and "const O_APPEND = 1024" in package os.
+type Const struct {
+	Type    string // like "Month" or "int"
+	Untyped bool
+	Value   string // empty when unknown or when it depends on the platform
+	Varies  bool   // the value depends on the platform
+}
+
+// Return the type and value of the constant, false when symbol is not a constant.
+func (vd VersionDatas) Const(pkg string, symbol string) (Const, bool) {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil || !strings.HasPrefix(data.definition, "const ") {
+		return Const{}, false
+	}
+
+	typeName, untyped := strings.CutPrefix(data.constData.typeName, untypedPrefix)
+	return Const{Type: typeName, Untyped: untyped, Value: data.constData.value, Varies: data.constData.varies}, true
+}
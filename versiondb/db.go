@@ -21,22 +21,33 @@ package versiondb
 import (
 	"bufio"
 	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dvaumoron/gosince/config"
 )
 
-const go1Dot = "go1."
+const (
+	go1Dot         = "go1."
+	listingFile    = "api.list" // source url then names of its api files, one by line
+	supportedCount = 2          // each major Go release is supported until there are two newer major releases
+)
 
 var (
+	errDownloadStatus      = errors.New("download failure : unexpected status")
+	errListingExpired      = errors.New("listing older than the max age")
+	errListingSource       = errors.New("listing of another source")
 	errParsingComma        = errors.New("parsing failure : no comma separator")
 	errParsingMethod       = errors.New("parsing failure : empty method")
 	errParsingMethodName   = errors.New("parsing failure : empty method name")
@@ -48,52 +59,379 @@ var (
 	errParsingType         = errors.New("parsing failure : unknown definition type")
 	errParsingUncomplete   = errors.New("parsing failure : not enough element in definition")
 	errUnexistingVersion   = errors.New("can not retrieve go1 information") // inner string only displayed for go1, else used as marker.
+	ErrUnavailablePlatform = errors.New("not available on the requested platform")
 	ErrUnknownPackage      = errors.New("package not found")
 	ErrUnknownSymbol       = errors.New("symbol not found")
 )
 
 type VersionDatas struct {
-	data  map[string]map[string][2]string
-	index map[string][][3]string
+	data        map[string]map[string]symbolData
+	variants    map[string]map[string]symbolData // by package then exact name, symbols differing only by case from one of data
+	advice      map[string]string                // replacement advice completing the built-in one, by "pkg" or "pkg.Symbol" name
+	summaries   *summaryLoader                   // first sentence of the doc comments, nil unless loaded with Docs
+	exactCase   bool
+	index       map[string][][3]string
+	prefixes    *prefixIndex
+	platforms   map[string]struct{} // every platform seen in qualified declarations
+	hashes      map[string]string   // sha256 of the loaded api files by file name
+	versions    []string            // in release order
+	unreleased  bool                // the tag of the latest version does not exist yet
+	stats       LoadStats
+	diagnostics []Diagnostic
+}
+
+// Malformed api file line, skipped by a lenient loading.
+type Diagnostic struct {
+	File string // like "api/go1.21.txt"
+	Line int
+	Text string
+	Err  error
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %v : %s", d.File, d.Line, d.Err, d.Text)
+}
+
+// Counters of the loading of a database.
+type LoadStats struct {
+	CacheHits      int // api files read from the local cache
+	CacheMisses    int // api files downloaded
+	DownloadErrors int
+	Duration       time.Duration
+}
+
+type symbolData struct {
+	name       string // original casing
+	definition string // as declared in api file (without the package part)
+	versions   [2]string
+	platforms  map[string]string // introducing version by platform ("" for all), nil when first declared for all platforms
+	alias      aliasData         // zero when the type has never been an alias
+	constData  constData         // zero when not a constant
+}
+
+func newSymbolData(name string, definition string, platform string, version string) symbolData {
+	data := symbolData{name: name, definition: definition, versions: [2]string{version}}
+	if platform != "" {
+		data.platforms = map[string]string{platform: version}
+	}
+	return data
+}
+
+// Return versions for the platforms matching goos and goarch, false when none support the symbol.
+func (data symbolData) versionsOn(goos string, goarch string) ([2]string, bool) {
+	if data.platforms == nil || (goos == "" && goarch == "") {
+		return data.versions, true
+	}
+
+	addedIn := ""
+	for platform, version := range data.platforms {
+		if platform == "" || matchPlatform(platform, goos, goarch) {
+			if addedIn == "" || CompareVersion(version, addedIn) < 0 {
+				addedIn = version
+			}
+		}
+	}
+
+	if addedIn == "" {
+		return [2]string{}, false
+	}
+	return [2]string{addedIn, data.versions[1]}, true
+}
+
+// Record the first version available on platform, when the symbol is not already available everywhere.
+func (data symbolData) addPlatform(platform string, version string) {
+	if data.platforms == nil {
+		return
+	}
+	if _, ok := data.platforms[platform]; !ok {
+		data.platforms[platform] = version
+	}
 }
 
 func LoadDatas(conf config.Config) (VersionDatas, error) {
-	repobase := path.Join(conf.RepoPath, go1Dot)
-	sourceBase, err := url.JoinPath(conf.SourceUrl, "api", go1Dot)
+	dl, err := newDataLoader(conf)
 	if err != nil {
 		return VersionDatas{}, err
 	}
 
-	dl := dataLoader{
-		VersionDatas: VersionDatas{data: map[string]map[string][2]string{}, index: map[string][][3]string{}},
-		repobase:     repobase, sourceBase: sourceBase, verbose: conf.Verbose,
+	vd, err := dl.loadDatas()
+	if latest := vd.LatestVersion(); err == nil && latest != "" {
+		vd.unreleased = !checkRelease(conf, releaseTag(latest))
+	}
+	if err == nil && conf.Docs {
+		conf.DocRef = cmp.Or(conf.DocRef, vd.ReleaseTag())
+		vd.summaries = newSummaryLoader(conf)
 	}
+	return vd, err
+}
 
-	return dl.VersionDatas, dl.load()
+func (dl dataLoader) loadDatas() (VersionDatas, error) {
+	start := time.Now()
+	var err error
+	dl.versions, err = dl.load()
+	if err == nil {
+		dl.deprecatePackages()
+		dl.resolveAliases()
+		err = dl.archive.flush()
+	}
+	dl.stats.Duration = time.Since(start)
+	dl.VersionDatas.stats = *dl.stats
+	dl.VersionDatas.diagnostics = *dl.diagnostics
+	return dl.VersionDatas, err
 }
 
+// Look for the api file of the release following latest, when found it is stored in the local cache
+// (so the next LoadDatas includes it) and true is returned.
+func FetchNextVersion(conf config.Config, latest string) (bool, error) {
+	minor := MinorVersion(latest)
+	if minor == -1 {
+		return false, ErrUnknownVersion
+	}
+
+	dl, err := newDataLoader(conf)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err = dl.read(strconv.Itoa(minor+1) + ".txt"); err != nil {
+		if err == errUnexistingVersion {
+			return false, nil
+		}
+		return false, err
+	}
+	// the cached listing misses the new release, it is requested again by the next loading
+	if err = os.Remove(dl.listPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	return true, dl.archive.flush()
+}
+
+// Return the malformed lines skipped while loading the database (always empty with a strict loading).
+func (vd VersionDatas) Diagnostics() []Diagnostic {
+	return vd.diagnostics
+}
+
+// Return the counters of the loading of the database.
+func (vd VersionDatas) LoadStats() LoadStats {
+	return vd.stats
+}
+
+// Return a copy of the matching index entries, each one is {entry, addedIn, deprecatedIn}.
+// With an exact case database, only the entries whose name ends with key (with the same case) are kept.
 func (vd VersionDatas) Search(key string) [][3]string {
-	return vd.index[strings.ToLower(key)]
+	entries := vd.index[strings.ToLower(key)]
+	if !vd.exactCase {
+		return slices.Clone(entries)
+	}
+
+	var res [][3]string
+	for _, entry := range entries {
+		if name := entry[0]; name == key || strings.HasSuffix(name, "/"+key) || strings.HasSuffix(name, " "+key) || strings.HasSuffix(name, "."+key) {
+			res = append(res, entry)
+		}
+	}
+	return res
 }
 
+// Return the versions {addedIn, deprecatedIn} of the symbol of the package pkg (see SinceInfo for a full description).
 func (vd VersionDatas) Since(pkg string, symbol string) ([2]string, error) {
+	data, err := vd.lookup(pkg, symbol)
+	return data.versions, err
+}
+
+// Same as Since, restricted to the platforms matching goos and goarch (an empty one match all).
+func (vd VersionDatas) SinceOn(pkg string, symbol string, goos string, goarch string) ([2]string, error) {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil {
+		return [2]string{}, err
+	}
+
+	versions, ok := data.versionsOn(goos, goarch)
+	if !ok {
+		return [2]string{}, ErrUnavailablePlatform
+	}
+	return versions, nil
+}
+
+// Keep entries (as returned by Search) available on the platforms matching goos and goarch,
+// with their introducing version adjusted for those platforms.
+func (vd VersionDatas) FilterPlatform(entries [][3]string, goos string, goarch string) [][3]string {
+	if goos == "" && goarch == "" {
+		return entries
+	}
+
+	var res [][3]string
+	for _, entry := range entries {
+		pkg, symbol, _ := strings.Cut(entry[0], " ")
+		if data, err := vd.lookup(pkg, symbol); err == nil {
+			if versions, ok := data.versionsOn(goos, goarch); ok {
+				res = append(res, [3]string{entry[0], versions[0], versions[1]})
+			}
+		}
+	}
+	return res
+}
+
+func (vd VersionDatas) lookup(pkg string, symbol string) (symbolData, error) {
+	pkg = strings.ToLower(pkg)
+	pkgSymbols, ok := vd.data[pkg]
+	if !ok {
+		return symbolData{}, ErrUnknownPackage
+	}
+
+	data, ok := pkgSymbols[strings.ToLower(symbol)] // pkgSymbols must contains ""
+	// an exact match is preferred
+	if ok && data.name != symbol {
+		if variant, found := vd.variants[pkg][symbol]; found {
+			data = variant
+		} else if vd.exactCase {
+			ok = false
+		}
+	}
+	if !ok {
+		return symbolData{}, ErrUnknownSymbol
+	}
+	return data, nil
+}
+
+// Return the sorted list of platforms (like "linux-amd64") supporting the package or symbol,
+// or nil when it is available on all platforms.
+func (vd VersionDatas) Platforms(pkg string, symbol string) []string {
 	pkgSymbols, ok := vd.data[strings.ToLower(pkg)]
 	if !ok {
-		return [2]string{}, ErrUnknownPackage
+		return nil
 	}
 
-	symbolData, ok := pkgSymbols[strings.ToLower(symbol)] // pkgSymbols must contains ""
+	data, ok := pkgSymbols[strings.ToLower(symbol)]
 	if !ok {
-		return [2]string{}, ErrUnknownSymbol
+		return nil
+	}
+	return data.platformList()
+}
+
+func (data symbolData) platformList() []string {
+	if data.platforms == nil {
+		return nil
+	}
+	if _, ok := data.platforms[""]; ok {
+		return nil
+	}
+
+	res := make([]string, 0, len(data.platforms))
+	for platform := range data.platforms {
+		res = append(res, platform)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Return a shorter equivalent of platforms : "goos-goarch-cgo" is dropped when "goos-goarch" is present,
+// and "goos" replaces its "goos-goarch" when all the known architectures of goos are present.
+func (vd VersionDatas) CompactPlatforms(platforms []string) []string {
+	present := map[string]struct{}{}
+	for _, platform := range platforms {
+		present[platform] = struct{}{}
+	}
+
+	knownArchs := map[string]int{}
+	for platform := range vd.platforms {
+		if !strings.HasSuffix(platform, "-cgo") {
+			goos, _, _ := strings.Cut(platform, "-")
+			knownArchs[goos]++
+		}
+	}
+
+	presentArchs := map[string]int{}
+	for _, platform := range platforms {
+		if !strings.HasSuffix(platform, "-cgo") {
+			goos, _, _ := strings.Cut(platform, "-")
+			presentArchs[goos]++
+		}
+	}
+
+	var res []string
+	for _, platform := range platforms {
+		if withoutCgo, ok := strings.CutSuffix(platform, "-cgo"); ok {
+			if _, ok = present[withoutCgo]; ok {
+				continue
+			}
+		}
+
+		goos, _, _ := strings.Cut(platform, "-")
+		if presentArchs[goos] == knownArchs[goos] {
+			platform = goos
+		}
+		if len(res) == 0 || res[len(res)-1] != platform {
+			res = append(res, platform)
+		}
 	}
-	return symbolData, nil
+	return res
 }
 
 type dataLoader struct {
 	VersionDatas
-	repobase   string
-	sourceBase string
-	verbose    bool
+	archive     *cacheArchive // nil when the api files are cached one by one
+	fsys        fs.FS         // when set, the api files are only read from it
+	git         *gitSource    // nil when the api files are downloaded
+	repobase    string
+	sourceUrl   string
+	sourceBase  string
+	sumsPath    string // sha256 of the api files cached one by one
+	listPath    string // names of the api files of the source, listed at most once by max age
+	snapshot    map[string]string
+	maxAge      time.Duration
+	offline     bool
+	progress    config.ProgressFunc
+	client      *http.Client
+	log         verboseLog
+	strict      bool
+	stats       *LoadStats
+	diagnostics *[]Diagnostic
+}
+
+// Print a verbose message (does nothing when not verbose).
+type verboseLog func(args ...any)
+
+func newVerboseLog(conf config.Config) verboseLog {
+	switch {
+	case conf.Logger != nil:
+		return func(args ...any) {
+			conf.Logger.Debug(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+		}
+	case conf.Verbose:
+		return func(args ...any) {
+			fmt.Println(args...)
+		}
+	}
+	return func(args ...any) {}
+}
+
+func newDataLoader(conf config.Config) (dataLoader, error) {
+	sourceBase, err := url.JoinPath(conf.SourceUrl, "api", go1Dot)
+	if err != nil {
+		return dataLoader{}, err
+	}
+
+	var archive *cacheArchive
+	if conf.Archive {
+		archive = openArchive(conf.RepoPath, newVerboseLog(conf))
+	}
+
+	var git *gitSource
+	if conf.GitUrl != "" {
+		git = newGitSource(conf.GitUrl, conf.GitRef, conf.RepoPath, conf.MaxAge, newVerboseLog(conf))
+	}
+
+	return dataLoader{
+		archive: archive, git: git,
+		VersionDatas: VersionDatas{
+			data: map[string]map[string]symbolData{}, variants: map[string]map[string]symbolData{},
+			index: map[string][][3]string{}, prefixes: &prefixIndex{}, platforms: map[string]struct{}{}, hashes: map[string]string{},
+		},
+		repobase: path.Join(conf.RepoPath, go1Dot), sourceUrl: conf.SourceUrl, sourceBase: sourceBase, sumsPath: path.Join(conf.RepoPath, checksumsFile), listPath: path.Join(conf.RepoPath, listingFile), snapshot: conf.Snapshot, maxAge: conf.MaxAge, offline: conf.Offline, progress: conf.Progress,
+		client: conf.Client, log: newVerboseLog(conf),
+		strict: conf.Strict, stats: &LoadStats{}, diagnostics: new([]Diagnostic),
+	}, nil
 }
 
 func (dl dataLoader) addIndexEntry(key string, entry string, version string, deprecated bool) {
@@ -121,181 +459,461 @@ func (dl dataLoader) addIndexSymbolEntry(pkg string, symbol string, version stri
 	entryBuilder.WriteByte(' ')
 	entryBuilder.WriteString(symbol)
 
-	indexDot := strings.LastIndexByte(symbol, '.')
-	dl.addIndexEntry(strings.ToLower(symbol[indexDot+1:]), entryBuilder.String(), version, deprecated) // no error when indexDot is -1
+	entry := entryBuilder.String()
+	symbolLower := strings.ToLower(symbol)
+	indexDot := strings.LastIndexByte(symbolLower, '.')
+	dl.addIndexEntry(symbolLower[indexDot+1:], entry, version, deprecated) // no error when indexDot is -1
+	if indexDot != -1 {
+		dl.addIndexEntry(symbolLower, entry, version, deprecated) // allows receiver-scoped search
+	}
 }
 
-func (dl dataLoader) load() error {
+// Return the loaded versions in release order.
+func (dl dataLoader) load() ([]string, error) {
 	versionData, err := dl.read("txt")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = dl.parseVersionData("go1", versionData)
+	err = dl.parseVersionData("go1", versionFile("go1"), versionData)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	available := dl.listVersionFiles()
+	versions := []string{"go1"}
 	for minorVersion := 1; true; minorVersion++ {
 		minorVersionStr := strconv.Itoa(minorVersion)
+		if _, ok := dl.snapshot[go1Dot+minorVersionStr+".txt"]; dl.snapshot != nil && !ok {
+			return versions, nil // releases published after the snapshot are ignored
+		}
+		if _, ok := available[go1Dot+minorVersionStr+".txt"]; available != nil && !ok && !dl.probeUnlisted(minorVersionStr+".txt") {
+			return versions, nil
+		}
+
 		versionData, err = dl.read(minorVersionStr + ".txt")
 		if err != nil {
 			if err == errUnexistingVersion {
-				return nil
+				return versions, nil
 			}
-			return err
+			return nil, err
 		}
 
-		err = dl.parseVersionData(go1Dot+minorVersionStr, versionData)
+		version := go1Dot + minorVersionStr
+		err = dl.parseVersionData(version, versionFile(version), versionData)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		versions = append(versions, version)
 	}
-	return nil
+	return versions, nil
 }
 
-func (dl dataLoader) parseVersionData(version string, versionData []byte) error {
+// Return the api files of the source (like "go1.21.txt") from a single listing request, nil when they are unknown
+// (offline, git clone, snapshot or listing failure) and must be probed until a missing one. The listing is cached
+// and only requested again when older than the max age.
+func (dl dataLoader) listVersionFiles() map[string]struct{} {
+	if dl.offline || dl.git != nil || dl.snapshot != nil {
+		return nil
+	}
+
+	names, err := dl.readListing()
+	if err != nil {
+		dl.log("List the api files of", dl.sourceUrl, ":", err)
+		if names, err = ListSourceFilesWith(dl.client, dl.sourceUrl, "api", ".txt"); err == nil && slices.Contains(names, "go1.txt") {
+			data := strings.Join(append([]string{dl.sourceUrl}, names...), "\n") + "\n"
+			if writeErr := writeFile(dl.listPath, []byte(data)); writeErr != nil {
+				dl.log("Failed to cache the listing in", dl.listPath, ":", writeErr)
+			}
+		}
+	}
+	if err == nil && !slices.Contains(names, "go1.txt") {
+		err = errListing
+	}
+	if err != nil {
+		dl.log("Failed to list the api files, probe them instead :", err)
+		return nil
+	}
+
+	res := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		res[name] = struct{}{}
+	}
+	return res
+}
+
+// Tell if the api file missing from the listing must be probed : a release published after the listing is
+// found in the cache (stored by FetchNextVersion) while the listing is fresh, and downloaded otherwise
+// (a listing without max age is never requested again).
+func (dl dataLoader) probeUnlisted(fileEnd string) bool {
+	if dl.maxAge <= 0 {
+		return true
+	}
+	if dl.archive != nil {
+		if _, ok := dl.archive.files[go1Dot+fileEnd]; ok {
+			return true
+		}
+	}
+	_, err := os.Stat(dl.repobase + fileEnd)
+	return err == nil
+}
+
+// Return the cached listing of the api files, it fails when the listing is older than the max age or comes from another source.
+func (dl dataLoader) readListing() ([]string, error) {
+	info, err := os.Stat(dl.listPath)
+	if err != nil {
+		return nil, err
+	}
+	if dl.maxAge > 0 && time.Since(info.ModTime()) > dl.maxAge {
+		return nil, errListingExpired
+	}
+
+	data, err := os.ReadFile(dl.listPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Fields(string(data))
+	if len(lines) == 0 || lines[0] != dl.sourceUrl {
+		return nil, errListingSource
+	}
+	return lines[1:], nil
+}
+
+// Register the declarations of the api file fileName, a malformed line fails a strict loading
+// and is skipped with a diagnostic otherwise.
+func (dl dataLoader) parseVersionData(version string, fileName string, versionData []byte) error {
+	lineNumber := 0
 	versionDataScanner := bufio.NewScanner(bytes.NewReader(versionData))
 	for versionDataScanner.Scan() {
+		lineNumber++
 		line := versionDataScanner.Text()
-		if indexSharp := strings.IndexByte(line, '#'); indexSharp != -1 {
-			// cut comment
-			if indexSharp == 0 {
-				continue
+		parsed, ok, err := parseLine(line)
+		if err != nil {
+			if dl.strict {
+				return fmt.Errorf("%s:%d: %w", fileName, lineNumber, err)
 			}
-			line = line[:indexSharp]
-		}
 
-		trimmedLine := strings.TrimSpace(line)
-		if trimmedLine == "" {
+			*dl.diagnostics = append(*dl.diagnostics, Diagnostic{File: fileName, Line: lineNumber, Text: line, Err: err})
 			continue
 		}
-
-		lenMinus12 := len(trimmedLine) - 12
-		deprecated := trimmedLine[lenMinus12:] == "//deprecated"
-		if deprecated {
-			trimmedLine = trimmedLine[:lenMinus12]
-		}
-
-		lineWithoutPrefix, ok := strings.CutPrefix(trimmedLine, "pkg ")
 		if !ok {
-			return errParsingStart
+			continue
 		}
 
-		indexComma := strings.IndexByte(lineWithoutPrefix, ',')
-		if indexComma == -1 {
-			return errParsingComma
+		pkg, platform := parsed.pkg, parsed.platform
+		if platform != "" {
+			dl.platforms[platform] = struct{}{}
 		}
 
-		pkg := lineWithoutPrefix[:indexComma]
 		pkgSymbols, ok := dl.data[pkg]
-		if !ok {
-			pkgSymbols = map[string][2]string{"": {version}} // allows search of package version with ""
+		if ok {
+			pkgSymbols[""].addPlatform(platform, version)
+		} else {
+			pkgSymbols = map[string]symbolData{"": newSymbolData("", "", platform, version)} // allows search of package version with ""
 			dl.data[pkg] = pkgSymbols
 			dl.addIndexPackageEntry(pkg, version)
 		}
 
-		symbolDesc := lineWithoutPrefix[indexComma+2:] // ignore comma and space
-		firstPart, secondPart := smartSplit(symbolDesc)
-		if len(firstPart) < 2 {
-			return errParsingUncomplete
+		dl.register(pkgSymbols, pkg, parsed, version)
+	}
+	return versionDataScanner.Err()
+}
+
+// Declaration of an api file line.
+type apiLine struct {
+	pkg        string
+	platform   string // like "linux-amd64", empty for all
+	symbol     string // like "Client.Do"
+	definition string // as declared (without the package part)
+	alias      string // aliased type of an alias declaration, like "fs.FileMode"
+	constType  string // type of a constant declaration, like "int" or "ideal-int"
+	constValue string // value of a constant declaration (declared on a separate line)
+	deprecated bool
+}
+
+// Parse a line of api file, false is returned for empty or comment lines.
+func parseLine(line string) (apiLine, bool, error) {
+	if indexSharp := strings.IndexByte(line, '#'); indexSharp != -1 {
+		// cut comment
+		if indexSharp == 0 {
+			return apiLine{}, false, nil
 		}
+		line = line[:indexSharp]
+	}
 
-		symbol := ""
-		switch symbolType, _ := firstPart[0].cast(); symbolType {
-		case "const", "func", "var":
-			symbol, _ = firstPart[1].cast()
-			if symbol == "" {
-				return errParsingName
-			}
-		case "method":
-			if len(firstPart) < 3 {
-				return errParsingMethod
-			}
+	trimmedLine := strings.TrimSpace(line)
+	if trimmedLine == "" {
+		return apiLine{}, false, nil
+	}
 
-			_, receiver := firstPart[1].cast()
-			if len(receiver) == 0 {
-				return errParsingReceiver
-			}
+	lenMinus12 := len(trimmedLine) - 12
+	deprecated := lenMinus12 >= 0 && trimmedLine[lenMinus12:] == "//deprecated"
+	if deprecated {
+		trimmedLine = trimmedLine[:lenMinus12]
+	}
 
-			typeName, _ := receiver[0].cast()
-			if typeName == "" {
-				return errParsingReceiverName
-			}
-			if typeName[0] == '*' {
-				typeName = typeName[1:]
-			}
+	lineWithoutPrefix, ok := strings.CutPrefix(trimmedLine, "pkg ")
+	if !ok {
+		return apiLine{}, false, errParsingStart
+	}
 
-			methodName, _ := firstPart[2].cast()
-			if methodName == "" {
-				return errParsingMethodName
-			}
+	indexComma := strings.IndexByte(lineWithoutPrefix, ',')
+	if indexComma == -1 {
+		return apiLine{}, false, errParsingComma
+	}
 
-			symbol = buildDotted(typeName, methodName)
-		case "type":
-			symbol, _ = firstPart[1].cast()
-			if symbol == "" {
-				return errParsingName
-			}
+	pkg, platform := lineWithoutPrefix[:indexComma], ""
+	if indexParen := strings.IndexByte(pkg, '('); indexParen != -1 {
+		// build constraint qualifier like "syscall (linux-amd64)"
+		platform = strings.TrimSuffix(pkg[indexParen+1:], ")")
+		pkg = strings.TrimSpace(pkg[:indexParen])
+	}
 
-			if len(secondPart) == 0 {
-				break
+	symbolDesc := lineWithoutPrefix[min(indexComma+2, len(lineWithoutPrefix)):] // ignore comma and space
+	firstPart, secondPart, err := safeSplit(symbolDesc)
+	if err != nil {
+		return apiLine{}, false, err
+	}
+	if len(firstPart) < 2 {
+		return apiLine{}, false, errParsingUncomplete
+	}
+
+	symbol, alias, constType, constValue := "", "", "", ""
+	switch symbolType, _ := firstPart[0].cast(); symbolType {
+	case "const", "func", "var":
+		symbol, _ = firstPart[1].cast()
+		if symbol == "" {
+			return apiLine{}, false, errParsingName
+		}
+
+		if symbolType == "const" {
+			// like "const O_APPEND int" or "const O_APPEND = 1024"
+			constDesc := strings.TrimSpace(strings.TrimPrefix(symbolDesc, "const "+symbol))
+			if value, ok := strings.CutPrefix(constDesc, "= "); ok {
+				constValue, _, _ = strings.Cut(value, " //") // floats have their exact value in comment
+			} else {
+				constType = constDesc
 			}
+		}
+	case "method":
+		if len(firstPart) < 3 {
+			return apiLine{}, false, errParsingMethod
+		}
+
+		_, receiver := firstPart[1].cast()
+		if len(receiver) == 0 {
+			return apiLine{}, false, errParsingReceiver
+		}
+
+		typeName, _ := receiver[0].cast()
+		if typeName == "" {
+			return apiLine{}, false, errParsingReceiverName
+		}
+		if typeName[0] == '*' {
+			typeName = typeName[1:]
+		}
+
+		methodName, _ := firstPart[2].cast()
+		if methodName == "" {
+			return apiLine{}, false, errParsingMethodName
+		}
+
+		symbol = buildDotted(typeName, methodName)
+	case "type":
+		symbol, _ = firstPart[1].cast()
+		if symbol == "" {
+			return apiLine{}, false, errParsingName
+		}
 
-			subName, _ := secondPart[0].cast()
-			if subName == "" {
-				return errParsingSubName
+		if len(secondPart) == 0 {
+			if len(firstPart) > 3 {
+				if equal, _ := firstPart[2].cast(); equal == "=" {
+					_, alias, _ = strings.Cut(symbolDesc, " = ")
+				}
 			}
+			break
+		}
 
-			symbol = buildDotted(symbol, subName)
-		default:
-			return errParsingType
+		subName, _ := secondPart[0].cast()
+		if subName == "unexported" && len(secondPart) > 1 {
+			if methods, _ := secondPart[1].cast(); methods == "methods" {
+				// like "type Type interface, unexported methods", not a member
+				return apiLine{}, false, nil
+			}
+		}
+		if subName == "embedded" && len(secondPart) > 1 {
+			// the field is named after the embedded type, like "*Reader" or "io.Reader"
+			subName, _ = secondPart[1].cast()
+			subName = strings.TrimPrefix(subName, "*")
+			subName = subName[strings.LastIndexByte(subName, '.')+1:] // no error when there is no dot
+		}
+		if subName == "" {
+			return apiLine{}, false, errParsingSubName
 		}
 
-		dl.register(pkgSymbols, pkg, symbol, version, deprecated)
+		symbol = buildDotted(symbol, subName)
+	default:
+		return apiLine{}, false, errParsingType
 	}
-	return versionDataScanner.Err()
+
+	return apiLine{
+		pkg: pkg, platform: platform, symbol: symbol, definition: strings.TrimSpace(symbolDesc), alias: strings.TrimSpace(alias),
+		constType: constType, constValue: strings.TrimSpace(constValue), deprecated: deprecated,
+	}, true, nil
 }
 
 func (dl dataLoader) read(fileEnd string) ([]byte, error) {
+	if dl.fsys != nil {
+		return dl.readFS(fileEnd)
+	}
+
 	filePath := dl.repobase + fileEnd
+	fileName := go1Dot + fileEnd
+	if dl.archive != nil {
+		if data, ok := dl.archive.files[fileName]; ok && dl.record(fileName, data) == nil {
+			return dl.revalidate(fileEnd, data, dl.archive.modTime)
+		}
+	}
+
 	data, err := os.ReadFile(filePath)
 	if err == nil {
+		if err = dl.record(fileName, data); err == nil {
+			if dl.archive != nil {
+				dl.archive.add(fileName, data) // migration of the files cached one by one
+			}
+
+			var modTime time.Time
+			if info, err := os.Stat(filePath); err == nil {
+				modTime = info.ModTime()
+			}
+			return dl.revalidate(fileEnd, data, modTime)
+		}
+	}
+	dl.stats.CacheMisses++
+
+	dl.log("Failed to read", filePath, ":", err)
+	if dl.offline {
+		return nil, errUnexistingVersion
+	}
+	return dl.download(fileEnd)
+}
+
+// Return the cached data, or the downloaded one when the cached file is older than the max age
+// (the cached data is kept when the download fails).
+func (dl dataLoader) revalidate(fileEnd string, data []byte, modTime time.Time) ([]byte, error) {
+	_, pinned := dl.snapshot[go1Dot+fileEnd]
+	if dl.offline || pinned || dl.maxAge <= 0 || modTime.IsZero() || time.Since(modTime) <= dl.maxAge {
+		dl.stats.CacheHits++
 		return data, nil
 	}
 
-	if dl.verbose {
-		fmt.Println("Failed to read", filePath, ":", err)
+	dl.log("Revalidate", dl.repobase+fileEnd, "cached on", modTime.Format(time.DateTime))
+	dl.stats.CacheMisses++
+	downloaded, err := dl.download(fileEnd)
+	if err != nil {
+		dl.log("Keep the cached", dl.repobase+fileEnd, ":", err)
+		return data, nil
 	}
+	return downloaded, nil
+}
 
+// Download the api file and store it in the cache.
+func (dl dataLoader) download(fileEnd string) ([]byte, error) {
+	filePath := dl.repobase + fileEnd
+	fileName := go1Dot + fileEnd
 	fileURL := dl.sourceBase + fileEnd
-	if data, err = download(fileURL); err != nil {
+	if dl.git != nil {
+		fileURL = dl.git.url + " (api/" + fileName + ")"
+	}
+	data, found, err := dl.fetchFile(fileURL, fileEnd)
+	if err != nil {
+		dl.stats.DownloadErrors++
 		return nil, err
 	}
 
-	if strings.TrimSpace(string(data)) == "404: Not Found" {
-		if dl.verbose {
-			fmt.Println("Failed to download", fileURL, ": Not Found")
+	if !found {
+		dl.log("Failed to download", fileURL, ": Not Found")
+		if _, ok := dl.snapshot[fileName]; ok {
+			return nil, fmt.Errorf("%s : %w", fileName, errSnapshotMissing)
 		}
 		return nil, errUnexistingVersion
 	}
-	return data, writeFile(filePath, data)
+
+	if err = dl.record(fileName, data); err != nil {
+		return nil, err
+	}
+	if dl.archive != nil {
+		dl.archive.add(fileName, data)
+		return data, nil
+	}
+	if err = writeFile(filePath, data); err != nil {
+		return nil, err
+	}
+	return data, dl.recordChecksum(fileName)
 }
 
-func (dl dataLoader) register(pkgSymbols map[string][2]string, pkg string, symbol string, version string, deprecated bool) {
-	symbolLower := strings.ToLower(symbol)
-	if deprecated {
-		symbolData := pkgSymbols[symbolLower]
-		symbolData[1] = version
-		pkgSymbols[symbolLower] = symbolData
+// Return the api file from the git clone when there is one, downloaded from fileURL otherwise,
+// false when it does not exist.
+func (dl dataLoader) fetchFile(fileURL string, fileEnd string) ([]byte, bool, error) {
+	if dl.git != nil {
+		return dl.git.read(fileEnd)
+	}
+
+	data, err := DownloadWith(dl.client, fileURL, dl.progress)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, strings.TrimSpace(string(data)) != notFoundBody, nil
+}
+
+func (dl dataLoader) register(pkgSymbols map[string]symbolData, pkg string, parsed apiLine, version string) {
+	symbolLower := strings.ToLower(parsed.symbol)
+	if data, ok := pkgSymbols[symbolLower]; ok && data.name != parsed.symbol {
+		// differs only by case, like TokenUser and Tokenuser in syscall
+		pkgSymbols = dl.variants[pkg]
+		if pkgSymbols == nil {
+			pkgSymbols = map[string]symbolData{}
+			dl.variants[pkg] = pkgSymbols
+		}
+		symbolLower = parsed.symbol
+	}
+
+	if parsed.deprecated {
+		data := pkgSymbols[symbolLower]
+		data.versions[1] = version
+		pkgSymbols[symbolLower] = data
 	} else {
-		if _, ok := pkgSymbols[symbolLower]; ok { // no override
+		if data, ok := pkgSymbols[symbolLower]; ok { // no override
+			data.addPlatform(parsed.platform, version)
+			if parsed.alias != "" && data.alias.target == "" { // the type became an alias
+				data.alias = aliasData{target: parsed.alias, version: version}
+			}
+			data.constData.add(parsed.constType, parsed.constValue)
+			pkgSymbols[symbolLower] = data
 			return
 		}
 
-		pkgSymbols[symbolLower] = [2]string{version}
+		data := newSymbolData(parsed.symbol, parsed.definition, parsed.platform, version)
+		if parsed.alias != "" {
+			data.alias = aliasData{target: parsed.alias, version: version}
+		}
+		data.constData.add(parsed.constType, parsed.constValue)
+		pkgSymbols[symbolLower] = data
 	}
-	dl.addIndexSymbolEntry(pkg, symbol, version, deprecated)
+	dl.addIndexSymbolEntry(pkg, parsed.symbol, version, parsed.deprecated)
+}
+
+// Return the path of the api file of version, like "api/go1.21.txt".
+func versionFile(version string) string {
+	return "api/" + version + ".txt"
+}
+
+// platform is like "linux-amd64" or "linux-amd64-cgo", empty goos or goarch match all.
+func matchPlatform(platform string, goos string, goarch string) bool {
+	platformOs, platformArch, _ := strings.Cut(platform, "-")
+	platformArch, _, _ = strings.Cut(platformArch, "-")
+	return (goos == "" || goos == platformOs) && (goarch == "" || goarch == platformArch)
 }
 
 func buildDotted(typeName string, subName string) string {
@@ -306,15 +924,49 @@ func buildDotted(typeName string, subName string) string {
 	return builder.String()
 }
 
-func download(dURL string) ([]byte, error) {
-	resp, err := http.Get(dURL)
+// Return the body retrieved at dURL with client (http.DefaultClient when nil, custom transports allow
+// authentication, tracing or stubs), a not found answer gives the body of raw.githubusercontent.com
+// ("404: Not Found") and the other statuses than 200 an error. progress (when not nil) is called while
+// reading a successful response.
+func DownloadWith(client *http.Client, dURL string, progress config.ProgressFunc) ([]byte, error) {
+	resp, err := fetch(client, dURL)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// supposing file will not be "too big"
-	return io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return []byte(notFoundBody), nil
+	default:
+		return nil, fmt.Errorf("%w %s for %s", errDownloadStatus, resp.Status, dURL)
+	}
+
+	if progress == nil {
+		// supposing file will not be "too big"
+		return io.ReadAll(resp.Body)
+	}
+
+	reader := &progressReader{reader: resp.Body, name: path.Base(resp.Request.URL.Path), total: resp.ContentLength, progress: progress}
+	data, err := io.ReadAll(reader)
+	progress(reader.name, reader.read, reader.total, true)
+	return data, err
+}
+
+type progressReader struct {
+	reader   io.Reader
+	name     string
+	read     int64
+	total    int64
+	progress config.ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	r.progress(r.name, r.read, r.total, false)
+	return n, err
 }
 
 // Create the parents directories if needed and write the file
@@ -0,0 +1,307 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/dvaumoron/gosince/versiondb/versiondbtest"
+)
+
+func TestLoadFS(t *testing.T) {
+	vd := versiondbtest.New(t)
+
+	if latest := vd.LatestVersion(); latest != versiondbtest.Latest {
+		t.Errorf("LatestVersion() = %q, want %q", latest, versiondbtest.Latest)
+	}
+	if diagnostics := vd.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Diagnostics() = %v, want none", diagnostics)
+	}
+	if stats := vd.LoadStats(); stats.CacheHits != len(versiondbtest.Files()) || stats.CacheMisses != 0 {
+		t.Errorf("LoadStats() = %+v, want %d cache hits and no miss", stats, len(versiondbtest.Files()))
+	}
+}
+
+func TestNew(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	server := versiondbtest.NewServer(t)
+	cacheDir := t.TempDir()
+
+	vd, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := vd.LatestVersion(); latest != versiondbtest.Latest {
+		t.Errorf("LatestVersion() = %q, want %q", latest, versiondbtest.Latest)
+	}
+	if stats := vd.LoadStats(); stats.CacheMisses != len(versiondbtest.Files()) {
+		t.Errorf("LoadStats() = %+v, want %d cache misses", stats, len(versiondbtest.Files()))
+	}
+
+	server.Close() // the second loading only reads the cache
+	vd, err = versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir), versiondb.WithOffline())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions, err := vd.Since("strings", "Cut"); err != nil || versions[0] != "go1.18" {
+		t.Errorf(`Since("strings", "Cut") = %v, %v, want go1.18`, versions, err)
+	}
+	if stats := vd.LoadStats(); stats.CacheHits != len(versiondbtest.Files()) {
+		t.Errorf("LoadStats() = %+v, want %d cache hits", stats, len(versiondbtest.Files()))
+	}
+}
+
+func TestNewMaxAge(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	t.Setenv(config.EnvCacheMaxAge, "")
+	server := versiondbtest.NewServer(t)
+	cacheDir := t.TempDir()
+
+	if _, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the default max age keeps the fresh cache
+	vd, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := vd.LoadStats(); stats.CacheHits != len(versiondbtest.Files()) || stats.CacheMisses != 0 {
+		t.Errorf("LoadStats() = %+v, want %d cache hits and no miss", stats, len(versiondbtest.Files()))
+	}
+
+	time.Sleep(time.Millisecond)
+	vd, err = versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir), versiondb.WithMaxAge(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := vd.LoadStats(); stats.CacheMisses != len(versiondbtest.Files()) {
+		t.Errorf("LoadStats() = %+v, want %d cache misses", stats, len(versiondbtest.Files()))
+	}
+}
+
+func TestNewCachedListing(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	server := versiondbtest.NewServer(t)
+	var requests atomic.Int32
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(w, r)
+	})
+	cacheDir := t.TempDir()
+
+	if _, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir), versiondb.WithMaxAge(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if count := int(requests.Load()); count != len(versiondbtest.Files())+1 {
+		t.Errorf("%d requests, want a listing and %d downloads", count, len(versiondbtest.Files()))
+	}
+
+	// a fresh cache is loaded without any request, even for the listing
+	requests.Store(0)
+	vd, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir), versiondb.WithMaxAge(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := requests.Load(); count != 0 {
+		t.Errorf("%d requests, want none", count)
+	}
+	if latest := vd.LatestVersion(); latest != versiondbtest.Latest {
+		t.Errorf("LatestVersion() = %q, want %q", latest, versiondbtest.Latest)
+	}
+}
+
+func TestNewUnlistedRelease(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	server := versiondbtest.NewServer(t)
+	var released atomic.Bool
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if released.Load() && r.URL.Path == "/api/go1.23.txt" {
+			w.Write([]byte("pkg iter, func Pull[$0 interface{}](Seq[$0]) (func() ($0, bool), func())\n"))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	for _, maxAge := range []time.Duration{0, time.Hour} {
+		released.Store(false)
+		cacheDir := t.TempDir()
+		if _, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir), versiondb.WithMaxAge(maxAge)); err != nil {
+			t.Fatal(err)
+		}
+
+		// the release is published after the listing was cached
+		released.Store(true)
+		if maxAge > 0 {
+			// the fresh listing is only completed by the cache, where the refresh of serve stores the release
+			conf := config.Config{SourceUrl: server.URL, RepoPath: cacheDir, MaxAge: maxAge}
+			if found, err := versiondb.FetchNextVersion(conf, versiondbtest.Latest); err != nil || !found {
+				t.Fatalf("FetchNextVersion() = %v, %v, want true", found, err)
+			}
+		}
+
+		vd, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir), versiondb.WithMaxAge(maxAge))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latest := vd.LatestVersion(); latest != "go1.23" {
+			t.Errorf("max age %v : LatestVersion() = %q, want go1.23", maxAge, latest)
+		}
+	}
+}
+
+func TestNewUnreleased(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	files := versiondbtest.Files()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/go1.21.0/VERSION" {
+			w.Write([]byte("go1.21.0\ntime 2023-08-04T20:14:06Z\n"))
+			return
+		}
+		if file, ok := files[strings.TrimPrefix(r.URL.Path, "/master/api/")]; ok {
+			w.Write(file.Data)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("404: Not Found"))
+	}))
+	defer server.Close()
+
+	// go1.22.txt is on master but the go1.22.0 tag does not exist yet
+	vd, err := versiondb.New(versiondb.WithSource(server.URL+"/master"), versiondb.WithCacheDir(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := vd.LatestVersion(); latest != versiondbtest.Latest {
+		t.Errorf("LatestVersion() = %q, want %q", latest, versiondbtest.Latest)
+	}
+	if release := vd.LatestRelease(); release != "go1.21" {
+		t.Errorf("LatestRelease() = %q, want go1.21", release)
+	}
+	if tag := vd.ReleaseTag(); tag != "go1.21.0" {
+		t.Errorf("ReleaseTag() = %q, want go1.21.0", tag)
+	}
+	if supported := vd.SupportedVersions(); !slices.Equal(supported, []string{"go1.20", "go1.21"}) {
+		t.Errorf("SupportedVersions() = %v, want [go1.20 go1.21]", supported)
+	}
+}
+
+func TestNewSummaries(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	server := versiondbtest.NewServer(t)
+	cacheDir := t.TempDir()
+	docs := `{"strings":[{"package":"strings","symbol":"Cut","text":"Cut slices s around the first instance of sep.\nMore details."}]}`
+	if err := os.WriteFile(filepath.Join(cacheDir, "docs.json"), []byte(docs), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the sources are not downloaded while loading, the failures to extract the doc comments are not fatal
+	vd, err := versiondb.New(versiondb.WithSource(server.URL), versiondb.WithCacheDir(cacheDir), versiondb.WithDocs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := vd.LoadStats(); stats.CacheMisses != len(versiondbtest.Files()) {
+		t.Errorf("LoadStats() = %+v, want %d cache misses", stats, len(versiondbtest.Files()))
+	}
+	if summary := vd.Summary("strings", "Cut"); summary != "Cut slices s around the first instance of sep." {
+		t.Errorf(`Summary("strings", "Cut") = %q, want the first sentence`, summary)
+	}
+	if summary := vd.Summary("fmt", "Println"); summary != "" {
+		t.Errorf(`Summary("fmt", "Println") = %q, want none without source`, summary)
+	}
+	if info, err := vd.SinceInfo("fmt", "Println"); err != nil || info.AddedIn != "go1" {
+		t.Errorf(`SinceInfo("fmt", "Println") = %+v, %v, want go1`, info, err)
+	}
+}
+
+func TestSince(t *testing.T) {
+	vd := versiondbtest.New(t)
+
+	for _, test := range []struct {
+		pkg    string
+		symbol string
+		want   [2]string
+		err    error
+	}{
+		{pkg: "fmt", want: [2]string{"go1", ""}},
+		{pkg: "fmt", symbol: "Println", want: [2]string{"go1", ""}},
+		{pkg: "strings", symbol: "Builder", want: [2]string{"go1.10", ""}},
+		{pkg: "strings", symbol: "Builder.WriteString", want: [2]string{"go1.10", ""}},
+		{pkg: "net/http", symbol: "Transport.ForceAttemptHTTP2", want: [2]string{"go1.13", ""}},
+		{pkg: "io", symbol: "ReadAll", want: [2]string{"go1.16", ""}},
+		{pkg: "io/ioutil", symbol: "ReadAll", want: [2]string{"go1", "go1.19"}},
+		{pkg: "slices", symbol: "Sort", want: [2]string{"go1.21", ""}},
+		{pkg: "strings", symbol: "cut", want: [2]string{"go1.18", ""}},
+		{pkg: "strings", symbol: "Unknown", err: versiondb.ErrUnknownSymbol},
+		{pkg: "unknown", err: versiondb.ErrUnknownPackage},
+	} {
+		versions, err := vd.Since(test.pkg, test.symbol)
+		if !errors.Is(err, test.err) || versions != test.want {
+			t.Errorf("Since(%q, %q) = %v, %v, want %v, %v", test.pkg, test.symbol, versions, err, test.want, test.err)
+		}
+	}
+}
+
+func TestSinceOn(t *testing.T) {
+	vd := versiondbtest.New(t)
+
+	if versions, err := vd.SinceOn("syscall", "O_DIRECT", "linux", ""); err != nil || versions[0] != "go1.13" {
+		t.Errorf(`SinceOn("syscall", "O_DIRECT", "linux", "") = %v, %v, want go1.13`, versions, err)
+	}
+	if _, err := vd.SinceOn("syscall", "O_DIRECT", "windows", ""); !errors.Is(err, versiondb.ErrUnavailablePlatform) {
+		t.Errorf(`SinceOn("syscall", "O_DIRECT", "windows", "") error = %v, want %v`, err, versiondb.ErrUnavailablePlatform)
+	}
+	if platforms := vd.Platforms("syscall", "O_DIRECT"); !slices.Equal(platforms, []string{"linux-amd64"}) {
+		t.Errorf(`Platforms("syscall", "O_DIRECT") = %v, want [linux-amd64]`, platforms)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	vd := versiondbtest.New(t)
+
+	for _, test := range []struct {
+		key  string
+		want [][3]string
+	}{
+		{key: "Cut", want: [][3]string{{"strings Cut", "go1.18", ""}}},
+		{key: "ReadAll", want: [][3]string{{"io ReadAll", "go1.16", ""}, {"io/ioutil ReadAll", "go1", "go1.19"}}},
+		{key: "Builder.String", want: [][3]string{{"strings Builder.String", "go1.10", ""}}},
+		{key: "ioutil", want: [][3]string{{"io/ioutil", "go1", "go1.19"}}}, // deprecated with all its api
+		{key: "Unknown"},
+	} {
+		entries := vd.Search(test.key)
+		slices.SortFunc(entries, func(a, b [3]string) int { return strings.Compare(a[0], b[0]) })
+		if !slices.Equal(entries, test.want) {
+			t.Errorf("Search(%q) = %v, want %v", test.key, entries, test.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"maps"
+	"strings"
+)
+
+// Replacement advice of the deprecated api which has none.
+const NoReplacement = "(none)"
+
+// Replacement advice of the deprecated api (from their documentation) by "pkg" or "pkg.Symbol" name.
+var replacements = map[string]string{
+	"archive/tar.TypeRegA":                       "archive/tar.TypeReg",
+	"bytes.Title":                                "golang.org/x/text/cases",
+	"crypto/elliptic.GenerateKey":                "crypto/ecdh",
+	"crypto/elliptic.Marshal":                    "crypto/ecdh",
+	"crypto/elliptic.Unmarshal":                  "crypto/ecdh",
+	"crypto/rsa.GenerateMultiPrimeKey":           "crypto/rsa.GenerateKey",
+	"crypto/tls.Config.BuildNameToCertificate":   NoReplacement,
+	"crypto/tls.Config.NameToCertificate":        NoReplacement,
+	"crypto/tls.Config.PreferServerCipherSuites": NoReplacement,
+	"crypto/x509.DecryptPEMBlock":                NoReplacement,
+	"crypto/x509.EncryptPEMBlock":                NoReplacement,
+	"crypto/x509.IsEncryptedPEMBlock":            NoReplacement,
+	"io/ioutil":                                  "os and io",
+	"io/ioutil.Discard":                          "io.Discard",
+	"io/ioutil.NopCloser":                        "io.NopCloser",
+	"io/ioutil.ReadAll":                          "io.ReadAll",
+	"io/ioutil.ReadDir":                          "os.ReadDir",
+	"io/ioutil.ReadFile":                         "os.ReadFile",
+	"io/ioutil.TempDir":                          "os.MkdirTemp",
+	"io/ioutil.TempFile":                         "os.CreateTemp",
+	"io/ioutil.WriteFile":                        "os.WriteFile",
+	"math/rand.Read":                             "crypto/rand.Read",
+	"math/rand.Seed":                             "math/rand.New",
+	"net/http.CloseNotifier":                     "net/http.Request.Context",
+	"net/http.Request.Cancel":                    "net/http.NewRequestWithContext",
+	"net/http.Transport.Dial":                    "net/http.Transport.DialContext",
+	"net/http.Transport.DialTLS":                 "net/http.Transport.DialTLSContext",
+	"os.SEEK_CUR":                                "io.SeekCurrent",
+	"os.SEEK_END":                                "io.SeekEnd",
+	"os.SEEK_SET":                                "io.SeekStart",
+	"reflect.Ptr":                                "reflect.Pointer",
+	"reflect.PtrTo":                              "reflect.PointerTo",
+	"reflect.SliceHeader":                        "unsafe.Slice or unsafe.SliceData",
+	"reflect.StringHeader":                       "unsafe.String or unsafe.StringData",
+	"strings.Title":                              "golang.org/x/text/cases",
+}
+
+// Mark as deprecated the packages whose symbols are all deprecated, in the release of the last deprecation.
+func (dl dataLoader) deprecatePackages() {
+	for pkg, pkgSymbols := range dl.data {
+		deprecatedIn := ""
+		for symbol, data := range pkgSymbols {
+			if symbol == "" {
+				continue
+			}
+			if data.versions[1] == "" {
+				deprecatedIn = ""
+				break
+			}
+			if deprecatedIn == "" || CompareVersion(data.versions[1], deprecatedIn) > 0 {
+				deprecatedIn = data.versions[1]
+			}
+		}
+		if deprecatedIn == "" {
+			continue
+		}
+
+		data := pkgSymbols[""]
+		data.versions[1] = deprecatedIn
+		pkgSymbols[""] = data
+		indexSlash := strings.LastIndexByte(pkg, '/')
+		dl.addIndexEntry(pkg[indexSlash+1:], pkg, deprecatedIn, true) // no error when indexSlash is -1
+	}
+}
+
+// Return the packages to use instead of the deprecated package pkg (like "os and io" for "io/ioutil"),
+// or an empty string when there is no advice.
+func (vd VersionDatas) PackageReplacement(pkg string) string {
+	return vd.Replacement(pkg, "")
+}
+
+// Return the api to use instead of the deprecated package or symbol (like "io.ReadAll" for "io/ioutil.ReadAll"),
+// NoReplacement when there is none or an empty string when there is no advice.
+func (vd VersionDatas) Replacement(pkg string, symbol string) string {
+	name := strings.ToLower(pkg)
+	if symbol != "" {
+		name += "." + vd.SymbolName(pkg, symbol)
+	}
+
+	if replacement, ok := vd.advice[name]; ok {
+		return replacement
+	}
+	return replacements[name]
+}
+
+// Return a copy where the advice of replacements (by "pkg" or "pkg.Symbol" name, like "io/ioutil.ReadAll",
+// empty or NoReplacement when there is none) completes and overrides the built-in one.
+func (vd VersionDatas) WithReplacements(replacements map[string]string) VersionDatas {
+	if len(replacements) == 0 {
+		return vd
+	}
+
+	advice := make(map[string]string, len(vd.advice)+len(replacements))
+	maps.Copy(advice, vd.advice)
+	for name, replacement := range replacements {
+		if replacement == "" {
+			replacement = NoReplacement
+		}
+		advice[name] = replacement
+	}
+	vd.advice = advice
+	return vd
+}
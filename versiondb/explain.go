@@ -0,0 +1,171 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+// Line of an api file.
+type SourceLine struct {
+	File string // like "api/go1.21.txt"
+	Line int
+	Text string
+}
+
+// Return the api file lines which produced the versions of the package or symbol : the first declaration
+// (and the first one of each other platform), the alias declaration and the deprecation, as the loading registers them.
+func (vd VersionDatas) Explain(conf config.Config, pkg string, symbol string) ([]SourceLine, error) {
+	if _, err := vd.lookup(pkg, symbol); err != nil {
+		return nil, err
+	}
+
+	pkg, symbol = strings.ToLower(pkg), strings.ToLower(symbol)
+	seenPlatforms, seenAlias := map[string]struct{}{}, false
+	var res []SourceLine
+	err := vd.scanFiles(conf, func(sourceLine SourceLine) {
+		parsed, ok, err := parseLine(sourceLine.Text)
+		if err != nil || !ok || strings.ToLower(parsed.pkg) != pkg {
+			return
+		}
+		if symbol != "" && strings.ToLower(parsed.symbol) != symbol {
+			return
+		}
+
+		_, seen := seenPlatforms[parsed.platform]
+		_, seenAll := seenPlatforms[""] // no more platform recorded, like symbolData.addPlatform
+		switch {
+		case parsed.alias != "" && !seenAlias:
+			seenAlias = true
+			seenPlatforms[parsed.platform] = struct{}{} // no effect when already seen
+		case parsed.deprecated:
+			if symbol == "" { // deprecation lines are about symbols
+				return
+			}
+		case seen || seenAll:
+			return
+		default:
+			seenPlatforms[parsed.platform] = struct{}{}
+		}
+		res = append(res, sourceLine)
+	})
+	return res, err
+}
+
+// Return the parsing trace of the api file lines declaring the package or symbol : the line,
+// the extracted package and symbol (or the parsing failure), then the tree of the definition parts.
+// Malformed lines starting with the package and containing the symbol name are also traced.
+func (vd VersionDatas) DebugParse(conf config.Config, pkg string, symbol string) (string, error) {
+	pkg, symbol = strings.ToLower(pkg), strings.ToLower(symbol)
+	symbolName := symbol[strings.LastIndexByte(symbol, '.')+1:] // no error when there is no dot
+
+	var builder strings.Builder
+	err := vd.scanFiles(conf, func(sourceLine SourceLine) {
+		parsed, ok, err := parseLine(sourceLine.Text)
+		switch {
+		case err != nil:
+			lowerText := strings.ToLower(sourceLine.Text)
+			if !strings.HasPrefix(lowerText, "pkg "+pkg+",") && !strings.HasPrefix(lowerText, "pkg "+pkg+" (") {
+				return
+			}
+			if !strings.Contains(lowerText, symbolName) {
+				return
+			}
+		case !ok || strings.ToLower(parsed.pkg) != pkg:
+			return
+		case symbol != "" && strings.ToLower(parsed.symbol) != symbol:
+			return
+		}
+
+		fmt.Fprintf(&builder, "%s:%d: %s\n", sourceLine.File, sourceLine.Line, sourceLine.Text)
+		if err != nil {
+			fmt.Fprintf(&builder, "  error : %v\n", err)
+		} else {
+			fmt.Fprintf(&builder, "  package %q, platform %q, symbol %q, deprecated %t\n", parsed.pkg, parsed.platform, parsed.symbol, parsed.deprecated)
+		}
+
+		_, symbolDesc, _ := strings.Cut(sourceLine.Text, ", ")
+		if indexSharp := strings.IndexByte(symbolDesc, '#'); indexSharp != -1 {
+			symbolDesc = symbolDesc[:indexSharp]
+		}
+		firstPart, secondPart, err := safeSplit(strings.TrimSuffix(strings.TrimSpace(symbolDesc), "//deprecated"))
+		if err != nil {
+			return
+		}
+
+		builder.WriteString("  first part\n")
+		writeNodes(&builder, firstPart, "    ")
+		if len(secondPart) != 0 {
+			builder.WriteString("  second part\n")
+			writeNodes(&builder, secondPart, "    ")
+		}
+	})
+	return builder.String(), err
+}
+
+// Call fn on each line of the api files of the loaded versions, in release order.
+func (vd VersionDatas) scanFiles(conf config.Config, fn func(SourceLine)) error {
+	dl, err := newDataLoader(conf)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range vd.versions {
+		fileEnd := "txt"
+		if version != "go1" {
+			fileEnd = strings.TrimPrefix(version, go1Dot) + ".txt"
+		}
+
+		versionData, err := dl.read(fileEnd)
+		if err != nil {
+			return err
+		}
+
+		lineNumber := 0
+		versionDataScanner := bufio.NewScanner(bytes.NewReader(versionData))
+		for versionDataScanner.Scan() {
+			lineNumber++
+			fn(SourceLine{File: versionFile(version), Line: lineNumber, Text: versionDataScanner.Text()})
+		}
+		if err = versionDataScanner.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write one node by line, strings are quoted and lists open an indented level.
+func writeNodes(builder *strings.Builder, nodes []node, indent string) {
+	for _, n := range nodes {
+		str, list := n.cast()
+		if _, ok := n.(listNode); !ok {
+			fmt.Fprintf(builder, "%s%q\n", indent, str)
+			continue
+		}
+
+		builder.WriteString(indent)
+		builder.WriteString("list\n")
+		writeNodes(builder, list, indent+"  ")
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultDownloadRate = 10 // requests per second
+
+	maxRetries    = 3
+	maxRetryDelay = time.Minute
+	notFoundBody  = "404: Not Found" // answer of raw.githubusercontent.com for a missing file
+	projectUrl    = "https://github.com/dvaumoron/gosince"
+)
+
+var (
+	downloadLimiter = rate.NewLimiter(DefaultDownloadRate, DefaultDownloadRate)
+	userAgent       = "gosince (+" + projectUrl + ")"
+
+	rawBlocked atomic.Bool // set after a proxy refused an access to raw.githubusercontent.com, the contents api is used from then on
+)
+
+// Set the version announced in the User-Agent header of the downloads.
+func SetUserAgent(version string) {
+	userAgent = "gosince/" + version + " (+" + projectUrl + ")"
+}
+
+// Set the maximum number of download requests per second, 0 (or less) for no limit.
+func SetDownloadRate(perSecond float64) {
+	if perSecond <= 0 {
+		downloadLimiter.SetLimit(rate.Inf)
+		return
+	}
+	downloadLimiter.SetLimit(rate.Limit(perSecond))
+	downloadLimiter.SetBurst(max(1, int(perSecond)))
+}
+
+// Send a GET request, within the rate limit, retrying throttled ones (429 or 503) after the delay
+// of their Retry-After header (or an exponential backoff without it).
+func get(client *http.Client, dURL string, accept string) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	request, err := http.NewRequest(http.MethodGet, dURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", userAgent)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if token := os.Getenv(config.EnvGithubToken); token != "" && request.URL.Host == githubApiHost {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	backoff := time.Second
+	for retry := 0; ; retry++ {
+		if err = downloadLimiter.Wait(context.Background()); err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		throttled := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if !throttled || retry == maxRetries {
+			return resp, nil
+		}
+
+		delay, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			delay = backoff
+			backoff *= 2
+		}
+		resp.Body.Close()
+		time.Sleep(min(delay, maxRetryDelay))
+	}
+}
+
+// Send a GET request, through the GitHub contents api when raw.githubusercontent.com is not reachable
+// (some networks block it but allow api.github.com). Only a refusal status switches the following requests
+// to the contents api (limited to 60 requests per hour without token), a transport error only this one.
+func fetch(client *http.Client, dURL string) (*http.Response, error) {
+	contentsUrl := githubContentsUrl(dURL)
+	if contentsUrl != "" && rawBlocked.Load() {
+		return getContents(client, contentsUrl)
+	}
+
+	resp, err := get(client, dURL, "")
+	if contentsUrl == "" || (err == nil && !blockedStatus(resp.StatusCode)) {
+		return resp, err
+	}
+
+	if err == nil {
+		resp.Body.Close()
+		rawBlocked.Store(true)
+	}
+	return getContents(client, contentsUrl)
+}
+
+// Get a raw file from the contents api, a missing file is answered like raw.githubusercontent.com does.
+func getContents(client *http.Client, contentsUrl string) (*http.Response, error) {
+	resp, err := get(client, contentsUrl, "application/vnd.github.raw")
+	if err == nil && resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		resp.Body = io.NopCloser(strings.NewReader(notFoundBody))
+	}
+	return resp, err
+}
+
+// Return the contents api url of a raw.githubusercontent.com one (like "https://raw.githubusercontent.com/golang/go/master/api/go1.txt"),
+// an empty string for other urls.
+func githubContentsUrl(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.Host != githubRawHost {
+		return ""
+	}
+
+	// path is like "/golang/go/master/api/go1.txt"
+	parts := strings.SplitN(strings.TrimPrefix(parsed.Path, "/"), "/", 4)
+	if len(parts) != 4 {
+		return ""
+	}
+	return githubApiBase + parts[0] + "/" + parts[1] + "/contents/" + parts[3] + "?ref=" + url.QueryEscape(parts[2])
+}
+
+// Proxies blocking a host answer with one of those.
+func blockedStatus(statusCode int) bool {
+	return statusCode == http.StatusForbidden || statusCode == http.StatusProxyAuthRequired || statusCode == http.StatusUnavailableForLegalReasons
+}
+
+// Parse a Retry-After value, in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(0, seconds)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, time.Until(date)), true
+	}
+	return 0, false
+}
@@ -0,0 +1,53 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+// Load the api data from the api files at the root of fsys (named like "go1.21.txt", as in the api directory
+// of the Go source or in the cache directory), without any download.
+// It allows to use a go:embed copy of the files or a fstest.MapFS.
+func LoadFS(fsys fs.FS) (VersionDatas, error) {
+	dl, err := newDataLoader(config.Config{Offline: true})
+	if err != nil {
+		return VersionDatas{}, err
+	}
+
+	dl.fsys = fsys
+	return dl.loadDatas()
+}
+
+func (dl dataLoader) readFS(fileEnd string) ([]byte, error) {
+	fileName := go1Dot + fileEnd
+	data, err := fs.ReadFile(dl.fsys, fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errUnexistingVersion
+		}
+		return nil, err
+	}
+
+	dl.stats.CacheHits++
+	return data, dl.record(fileName, data)
+}
@@ -0,0 +1,164 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	defaultGitRef = "master"
+	gitDir        = "git"
+	gitLocalRef   = plumbing.ReferenceName("refs/gosince/api") // last fetched commit of the ref
+	gitRemote     = "origin"
+	gitSyncedFile = "synced" // in the git directory, its modification time is the one of the last fetch
+)
+
+var (
+	errGit = errors.New("git failure")
+
+	commitShaRegexp = regexp.MustCompile("^[0-9a-f]{40}$")
+)
+
+// Shallow clone (bare, only the last commit of the ref) of a Go repository, fetched again when older than maxAge.
+type gitSource struct {
+	url    string
+	ref    string // branch, tag or commit sha
+	dir    string
+	maxAge time.Duration
+	repo   *git.Repository // nil until the first read
+	log    verboseLog
+}
+
+func newGitSource(url string, ref string, repoPath string, maxAge time.Duration, log verboseLog) *gitSource {
+	if ref == "" {
+		ref = defaultGitRef
+	}
+	return &gitSource{url: url, ref: ref, dir: filepath.Join(repoPath, gitDir), maxAge: maxAge, log: log}
+}
+
+// Return the content of the api file, false when the fetched commit does not have it.
+func (source *gitSource) read(fileEnd string) ([]byte, bool, error) {
+	if source.repo == nil {
+		repo, err := source.sync()
+		if err != nil {
+			return nil, false, err
+		}
+		source.repo = repo
+	}
+
+	ref, err := source.repo.Reference(gitLocalRef, true)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w : %w", errGit, err)
+	}
+	commit, err := source.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, false, fmt.Errorf("%w : %w", errGit, err)
+	}
+	file, err := commit.File("api/" + go1Dot + fileEnd)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%w : %w", errGit, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, false, fmt.Errorf("%w : %w", errGit, err)
+	}
+	return []byte(content), true, nil
+}
+
+// Open the clone, created when missing, and fetch the last commit of the ref when the previous fetch is older
+// than the max age (the fetched commit is kept when the fetch fails).
+func (source *gitSource) sync() (*git.Repository, error) {
+	repo, err := git.PlainOpen(source.dir)
+	if err != nil {
+		if err = os.RemoveAll(source.dir); err != nil { // remains of an interrupted clone
+			return nil, err
+		}
+		if repo, err = git.PlainInit(source.dir, true); err != nil {
+			return nil, fmt.Errorf("%w : %w", errGit, err)
+		}
+	}
+
+	_, refErr := repo.Reference(gitLocalRef, true)
+	syncedPath := filepath.Join(source.dir, gitSyncedFile)
+	if info, err := os.Stat(syncedPath); refErr == nil && err == nil && (source.maxAge <= 0 || time.Since(info.ModTime()) <= source.maxAge) {
+		return repo, nil
+	}
+
+	if err = source.fetch(repo); err != nil {
+		if refErr == nil {
+			source.log("Keep the commit fetched from", source.url, ":", err)
+			return repo, nil
+		}
+		return nil, err
+	}
+	return repo, os.WriteFile(syncedPath, nil, 0644)
+}
+
+// Fetch the last commit of the ref (a branch, a tag or a commit sha) from the url, which may have changed since the clone.
+func (source *gitSource) fetch(repo *git.Repository) error {
+	source.log("Fetch", source.ref, "from", source.url)
+
+	remote, err := repo.Remote(gitRemote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		remote, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: gitRemote, URLs: []string{source.url}})
+	}
+	if err != nil {
+		return fmt.Errorf("%w : %w", errGit, err)
+	}
+	if remote.Config().URLs[0] != source.url {
+		if err = repo.DeleteRemote(gitRemote); err != nil {
+			return fmt.Errorf("%w : %w", errGit, err)
+		}
+		if remote, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: gitRemote, URLs: []string{source.url}}); err != nil {
+			return fmt.Errorf("%w : %w", errGit, err)
+		}
+	}
+
+	sources := []string{"refs/heads/" + source.ref, "refs/tags/" + source.ref}
+	if commitShaRegexp.MatchString(source.ref) {
+		sources = []string{source.ref}
+	}
+	for _, refSource := range sources {
+		refSpec := gitconfig.RefSpec("+" + refSource + ":" + gitLocalRef.String())
+		err = remote.Fetch(&git.FetchOptions{RefSpecs: []gitconfig.RefSpec{refSpec}, Depth: 1, Tags: git.NoTags, Force: true})
+		if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil
+		}
+		var noMatch git.NoMatchingRefSpecError
+		if !errors.As(err, &noMatch) {
+			break
+		}
+	}
+	return fmt.Errorf("%w : %s : %w", errGit, source.ref, err)
+}
@@ -0,0 +1,103 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/dvaumoron/gosince/versiondb/versiondbtest"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestLoadDatasGit(t *testing.T) {
+	if _, err := exec.LookPath("git-upload-pack"); err != nil {
+		t.Skip("the file transport of go-git runs git-upload-pack :", err)
+	}
+
+	sourceDir := t.TempDir()
+	repo, err := git.PlainInit(sourceDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for name, file := range versiondbtest.Files() {
+		files[name] = file.Data
+	}
+	commitFiles(t, repo, sourceDir, files)
+
+	conf := config.Config{RepoPath: t.TempDir(), GitUrl: sourceDir, MaxAge: time.Hour}
+	vd, err := versiondb.LoadDatas(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := vd.LatestVersion(); latest != versiondbtest.Latest {
+		t.Errorf("LatestVersion() = %q, want %q", latest, versiondbtest.Latest)
+	}
+
+	// the clone is not fetched again before the max age, even to probe the next release
+	commitFiles(t, repo, sourceDir, map[string][]byte{"go1.23.txt": []byte("pkg strings, func Lines(string) iter.Seq[string]\n")})
+	if vd, err = versiondb.LoadDatas(conf); err != nil {
+		t.Fatal(err)
+	}
+	if latest := vd.LatestVersion(); latest != versiondbtest.Latest {
+		t.Errorf("LatestVersion() = %q, want %q before the max age", latest, versiondbtest.Latest)
+	}
+
+	time.Sleep(time.Millisecond)
+	conf.MaxAge = time.Millisecond
+	if vd, err = versiondb.LoadDatas(conf); err != nil {
+		t.Fatal(err)
+	}
+	if versions, err := vd.Since("strings", "Lines"); err != nil || versions[0] != "go1.23" {
+		t.Errorf(`Since("strings", "Lines") = %v, %v, want go1.23 after the max age`, versions, err)
+	}
+}
+
+// Write the api files in the work tree of repo and commit them.
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string][]byte) {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Join(dir, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err = os.WriteFile(filepath.Join(dir, "api", name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = worktree.Add("api/" + name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	signature := &object.Signature{Name: "gopher", Email: "gopher@example.com", When: time.Now()}
+	if _, err = worktree.Commit("api files", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+const (
+	godebugFile          = "godebug.md"
+	godebugHistoryPrefix = "## GODEBUG History"
+	godebugVersionPrefix = "### Go "
+)
+
+var (
+	ErrNoGodebug      = errors.New("godebug failure : doc/godebug.md not found in the Go source")
+	ErrUnknownSetting = errors.New("godebug setting not found")
+
+	markdownLinkRegexp  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	settingNameRegexp   = regexp.MustCompile("`([a-z0-9_]+)` setting")
+	settingValueRegexp  = regexp.MustCompile("`([a-z0-9_]+)=[^`]*`")
+	whitespaceRegexp    = regexp.MustCompile(`\s+`)
+	markdownCodeReplace = strings.NewReplacer("`", "")
+)
+
+// History of a GODEBUG setting, from doc/godebug.md.
+type GodebugSetting struct {
+	Name    string
+	Changes []GodebugChange // in release order, the first one introduces the setting
+}
+
+// Paragraph of the GODEBUG history mentioning a setting.
+type GodebugChange struct {
+	Version string
+	Text    string
+}
+
+// GODEBUG settings by name.
+type GodebugDatas map[string]GodebugSetting
+
+// Read doc/godebug.md from the local cache, or download it from the Go source when missing or older than
+// conf.MaxAge (the cached copy is kept when the download fails), and parse its history section.
+func LoadGodebug(conf config.Config) (GodebugDatas, error) {
+	verboseLog := newVerboseLog(conf)
+	filePath := path.Join(conf.RepoPath, godebugFile)
+	data, err := os.ReadFile(filePath)
+	if err == nil {
+		info, statErr := os.Stat(filePath)
+		if statErr != nil || !expired(conf, info.ModTime()) {
+			return parseGodebug(data), nil
+		}
+		verboseLog("Revalidate", filePath, "cached on", info.ModTime().Format(time.DateTime))
+	} else {
+		verboseLog("Failed to read", filePath, ":", err)
+	}
+
+	downloaded, downloadErr := downloadGodebug(conf)
+	if downloadErr != nil {
+		if err == nil {
+			verboseLog("Keep", filePath, ":", downloadErr)
+			return parseGodebug(data), nil
+		}
+		return nil, downloadErr
+	}
+	if err = writeFile(filePath, downloaded); err != nil {
+		return nil, err
+	}
+	return parseGodebug(downloaded), nil
+}
+
+func downloadGodebug(conf config.Config) ([]byte, error) {
+	fileUrl, err := url.JoinPath(conf.SourceUrl, "doc", godebugFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := DownloadWith(conf.Client, fileUrl, conf.Progress)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(data)) == notFoundBody {
+		return nil, ErrNoGodebug
+	}
+	return data, nil
+}
+
+// Return the history of the setting (case is ignored).
+func (gd GodebugDatas) Since(name string) (GodebugSetting, error) {
+	setting, ok := gd[strings.ToLower(name)]
+	if !ok {
+		return GodebugSetting{}, ErrUnknownSetting
+	}
+	return setting, nil
+}
+
+// Return the sorted names of the settings containing part (case is ignored).
+func (gd GodebugDatas) Search(part string) []string {
+	part = strings.ToLower(part)
+	var res []string
+	for name := range gd {
+		if strings.Contains(name, part) {
+			res = append(res, name)
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Each "### Go 1.N" section of the history is split in paragraphs, a paragraph mentioning a setting
+// (like "the `panicnil` setting" or "`panicnil=1`") is recorded in its history.
+func parseGodebug(data []byte) GodebugDatas {
+	var versions []string
+	paragraphs := map[string][]string{}
+	inHistory := false
+	version := ""
+	var paragraph []string
+	endParagraph := func() {
+		if version != "" && len(paragraph) != 0 {
+			paragraphs[version] = append(paragraphs[version], strings.Join(paragraph, " "))
+		}
+		paragraph = paragraph[:0]
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, godebugHistoryPrefix):
+			inHistory = true
+		case !inHistory:
+		case strings.HasPrefix(line, godebugVersionPrefix):
+			endParagraph()
+			version = NormalizeVersion(strings.TrimPrefix(line, godebugVersionPrefix))
+			if version != "" {
+				versions = append(versions, version)
+			}
+		case strings.HasPrefix(line, "#"): // end of history
+			endParagraph()
+			inHistory, version = false, ""
+		case line == "":
+			endParagraph()
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	endParagraph()
+
+	slices.SortFunc(versions, CompareVersion) // the document lists the newest first
+	res := GodebugDatas{}
+	for _, version := range slices.Compact(versions) {
+		for _, text := range paragraphs[version] {
+			text = whitespaceRegexp.ReplaceAllString(text, " ")
+			var names []string
+			for _, match := range settingNameRegexp.FindAllStringSubmatch(text, -1) {
+				names = append(names, match[1])
+			}
+			for _, match := range settingValueRegexp.FindAllStringSubmatch(text, -1) {
+				names = append(names, match[1])
+			}
+			slices.Sort(names)
+
+			text = markdownCodeReplace.Replace(markdownLinkRegexp.ReplaceAllString(text, "$1"))
+			for _, name := range slices.Compact(names) {
+				setting := res[name]
+				setting.Name = name
+				setting.Changes = append(setting.Changes, GodebugChange{Version: version, Text: text})
+				res[name] = setting
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/dvaumoron/gosince/versiondb/versiondbtest"
+)
+
+func TestLoadGodebug(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	conf := config.Config{RepoPath: t.TempDir(), SourceUrl: versiondbtest.NewServer(t).URL, MaxAge: time.Hour}
+	filePath := filepath.Join(conf.RepoPath, "godebug.md")
+
+	// the not found answer is not cached
+	if _, err := versiondb.LoadGodebug(conf); !errors.Is(err, versiondb.ErrNoGodebug) {
+		t.Fatalf("LoadGodebug() = %v, want %v", err, versiondb.ErrNoGodebug)
+	}
+	if _, err := os.Stat(filePath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("godebug.md is cached (%v)", err)
+	}
+
+	// an expired copy is kept when the download fails
+	data := "## GODEBUG History\n\n### Go 1.21\n\nGo 1.21 added the `panicnil` setting.\n"
+	if err := os.WriteFile(filePath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filePath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	gd, err := versiondb.LoadGodebug(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setting, err := gd.Since("panicnil"); err != nil || len(setting.Changes) != 1 || setting.Changes[0].Version != "go1.21" {
+		t.Errorf(`Since("panicnil") = %+v, %v, want a change in go1.21`, setting, err)
+	}
+}
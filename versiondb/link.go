@@ -0,0 +1,61 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import "strings"
+
+const docBaseUrl = "https://pkg.go.dev/"
+
+// Return the pkg.go.dev url of the package, with an anchor on the symbol when not empty (like "#Client.Do").
+func (vd VersionDatas) DocUrl(pkg string, symbol string) string {
+	symbol = vd.SymbolName(pkg, symbol)
+	if symbol == "" {
+		return docBaseUrl + pkg
+	}
+	return docBaseUrl + pkg + "#" + symbol
+}
+
+// Return the symbol with its original casing when it is known, else unchanged.
+func (vd VersionDatas) SymbolName(pkg string, symbol string) string {
+	if data, err := vd.lookup(pkg, symbol); err == nil {
+		return data.name
+	}
+	return symbol
+}
+
+// Return the symbol for display : methods show their receiver form, like "(*Buffer).ReadFrom" or "(Time).Add",
+// to tell whether a pointer is required, other symbols are returned like SymbolName does.
+func (vd VersionDatas) DisplayName(pkg string, symbol string) string {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil {
+		return symbol
+	}
+
+	// receivers are kept in definitions, like "method (*Buffer) ReadFrom(io.Reader) (int64, error)"
+	receiverDesc, ok := strings.CutPrefix(data.definition, "method (")
+	if !ok {
+		return data.name
+	}
+
+	typeName, methodName, _ := strings.Cut(data.name, ".")
+	if receiverDesc[0] == '*' {
+		typeName = "*" + typeName
+	}
+	return "(" + typeName + ")." + methodName
+}
@@ -0,0 +1,205 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"errors"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Kinds of SymbolInfo.
+const (
+	KindConst   = "const"
+	KindField   = "field" // of a struct
+	KindFunc    = "func"
+	KindMethod  = "method" // of a type or an interface
+	KindPackage = "package"
+	KindType    = "type"
+	KindVar     = "var"
+
+	interfaceMarker = " interface, "
+)
+
+var ErrUnknownVersion = errors.New("unknown go version")
+
+// Description of a symbol of a package, as returned by SinceInfo and Symbols.
+type SymbolInfo struct {
+	Name         string // declared name, like "Buffer.ReadFrom" for a method (empty for the package itself)
+	Kind         string // one of the Kind constants
+	Definition   string // as declared in the api files, like "method (*Buffer) ReadFrom(io.Reader) (int64, error)"
+	Signature    string // readable definition, see Declaration
+	AddedIn      string
+	DeprecatedIn string   // empty when not deprecated
+	Platforms    []string // sorted, nil when available on all platforms
+	Summary      string   // first sentence of the doc comment, empty unless loaded with Docs
+}
+
+// Return the description of the symbol of the package pkg (with an empty symbol, the package itself).
+func (vd VersionDatas) SinceInfo(pkg string, symbol string) (SymbolInfo, error) {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil {
+		return SymbolInfo{}, err
+	}
+	return vd.symbolInfo(strings.ToLower(pkg), data), nil
+}
+
+func (vd VersionDatas) symbolInfo(pkg string, data symbolData) SymbolInfo {
+	info := data.info()
+	info.Summary = vd.summaries.get(pkg)[data.name]
+	return info
+}
+
+func (data symbolData) info() SymbolInfo {
+	return SymbolInfo{
+		Name: data.name, Kind: definitionKind(data.definition), Definition: data.definition,
+		Signature: readableDefinition(data.definition), AddedIn: data.versions[0], DeprecatedIn: data.versions[1],
+		Platforms: data.platformList(),
+	}
+}
+
+// Return the kind of a definition of api file.
+func definitionKind(definition string) string {
+	switch {
+	case definition == "":
+		return KindPackage
+	case strings.Contains(definition, structMarker):
+		return KindField
+	case strings.Contains(definition, interfaceMarker):
+		return KindMethod
+	}
+	kind, _, _ := strings.Cut(definition, " ")
+	return kind
+}
+
+// Return the paths of the known packages, in lexical order.
+func (vd VersionDatas) Packages() []string {
+	return vd.PackagesWithPrefix("")
+}
+
+// Return the platforms seen in qualified declarations (like "linux-amd64" or "linux-386-cgo"), sorted.
+func (vd VersionDatas) KnownPlatforms() []string {
+	return slices.Sorted(maps.Keys(vd.platforms))
+}
+
+// Return the symbols of the package pkg (a path, or a name like "http"), sorted by name.
+func (vd VersionDatas) Symbols(pkg string) ([]SymbolInfo, error) {
+	pkg = vd.resolvePackage(strings.ToLower(pkg), "")
+	pkgSymbols, ok := vd.data[pkg]
+	if !ok {
+		return nil, ErrUnknownPackage
+	}
+
+	res := make([]SymbolInfo, 0, len(pkgSymbols)+len(vd.variants[pkg]))
+	addSymbols := func(symbols map[string]symbolData) {
+		for _, data := range symbols {
+			if data.name != "" { // the package itself
+				res = append(res, vd.symbolInfo(pkg, data))
+			}
+		}
+	}
+	addSymbols(pkgSymbols)
+	addSymbols(vd.variants[pkg])
+
+	slices.SortFunc(res, func(a SymbolInfo, b SymbolInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return res, nil
+}
+
+// Return entries (package itself and its symbols) of the packages whose path matches pattern (see path.Match).
+func (vd VersionDatas) List(pattern string) ([][3]string, error) {
+	var res [][3]string
+	err := vd.walkPackages(pattern, func(pkg string, pkgSymbols map[string]symbolData) {
+		for _, data := range pkgSymbols {
+			res = append(res, data.entry(pkg))
+		}
+	})
+	return res, err
+}
+
+// Return entries of the packages matching pattern (all when empty) added or deprecated after from, up to to (included).
+func (vd VersionDatas) Diff(from string, to string, pattern string) ([][3]string, error) {
+	if from = NormalizeVersion(from); from == "" {
+		return nil, ErrUnknownVersion
+	}
+	if to = NormalizeVersion(to); to == "" {
+		return nil, ErrUnknownVersion
+	}
+
+	inRange := func(version string) bool {
+		return version != "" && CompareVersion(from, version) < 0 && CompareVersion(version, to) <= 0
+	}
+
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	var res [][3]string
+	err := vd.walkPackages(pattern, func(pkg string, pkgSymbols map[string]symbolData) {
+		for _, data := range pkgSymbols {
+			if inRange(data.versions[0]) || inRange(data.versions[1]) {
+				res = append(res, data.entry(pkg))
+			}
+		}
+	})
+	return res, err
+}
+
+// Call fn on each package matching pattern, in lexical order (then on its symbols differing only by case, if any).
+func (vd VersionDatas) walkPackages(pattern string, fn func(string, map[string]symbolData)) error {
+	pattern = strings.ToLower(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	pkgs := make([]string, 0, len(vd.data))
+	for pkg := range vd.data {
+		if matchPackage(pattern, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	slices.Sort(pkgs)
+
+	for _, pkg := range pkgs {
+		fn(pkg, vd.data[pkg])
+		if variants, ok := vd.variants[pkg]; ok {
+			fn(pkg, variants)
+		}
+	}
+	return nil
+}
+
+func (data symbolData) entry(pkg string) [3]string {
+	if data.name == "" {
+		return [3]string{pkg, data.versions[0], data.versions[1]}
+	}
+	return [3]string{pkg + " " + data.name, data.versions[0], data.versions[1]}
+}
+
+// "*" match every package, even with slashes in their path.
+func matchPackage(pattern string, pkg string) bool {
+	if pattern == "*" {
+		return true
+	}
+	matched, _ := path.Match(pattern, pkg) // pattern already checked
+	return matched
+}
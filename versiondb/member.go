@@ -0,0 +1,93 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"slices"
+	"strings"
+)
+
+const structMarker = " struct, "
+
+// Struct field, as declared in api files (like "type Transport struct, ForceAttemptHTTP2 bool").
+type Field struct {
+	Type     string // like "bool" or "*Reader"
+	Embedded bool
+}
+
+// Return the field described by symbol (like "Transport.ForceAttemptHTTP2"), false when symbol is not a struct field.
+func (vd VersionDatas) Field(pkg string, symbol string) (Field, bool) {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil {
+		return Field{}, false
+	}
+
+	_, fieldDesc, ok := strings.Cut(data.definition, structMarker)
+	if !ok {
+		return Field{}, false
+	}
+
+	name, fieldType, _ := strings.Cut(fieldDesc, " ")
+	return Field{Type: fieldType, Embedded: name == "embedded"}, true
+}
+
+// Return the entries of the members of the type (methods, struct fields or interface methods, each one
+// with the version it was added to the type) and of the constants of that type (like time.January for time.Month).
+// pkg can also be a package name (like "net" or "http").
+func (vd VersionDatas) Members(pkg string, typeName string) ([][3]string, error) {
+	pkg, typeName = vd.resolvePackage(strings.ToLower(pkg), strings.ToLower(typeName)), strings.ToLower(typeName)
+	pkgSymbols, ok := vd.data[pkg]
+	if !ok {
+		return nil, ErrUnknownPackage
+	}
+
+	prefix := typeName + "."
+	var res [][3]string
+	for symbol, data := range pkgSymbols {
+		if strings.HasPrefix(symbol, prefix) || strings.ToLower(data.constData.typeName) == typeName {
+			res = append(res, data.entry(pkg))
+		}
+	}
+	if len(res) == 0 {
+		// some types are only declared by their members (like reflect.Type in go1)
+		if _, ok = pkgSymbols[typeName]; !ok {
+			return nil, ErrUnknownSymbol
+		}
+	}
+	return res, nil
+}
+
+// Return pkg when it is a known package path, else the first package (in lexical order) named pkg
+// and declaring symbol, or pkg unchanged when there is none.
+func (vd VersionDatas) resolvePackage(pkg string, symbol string) string {
+	if _, ok := vd.data[pkg]; ok {
+		return pkg
+	}
+
+	var candidates []string
+	for candidate, pkgSymbols := range vd.data {
+		if _, ok := pkgSymbols[symbol]; ok && PackageName(candidate) == pkg {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return pkg
+	}
+	return slices.Min(candidates)
+}
@@ -0,0 +1,52 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import "strings"
+
+// Split an expression in <pkg> or <pkg>.<sym>[.<methodOrField>] form (see NormalizeExpr) at the first dot
+// after the last slash (see VersionDatas.SplitExpr to check against known packages), the package and symbol are lowercased.
+func SplitExpr(expr string) (string, string) {
+	expr = NormalizeExpr(expr)
+	indexSlash := strings.LastIndexByte(expr, '/')
+	pkg, symbol, _ := strings.Cut(expr[indexSlash+1:], ".") // no error when indexSlash is -1
+	return strings.ToLower(expr[:indexSlash+1] + pkg), strings.ToLower(symbol)
+}
+
+// Return the minimum version needed by exprs (see SplitExpr) on the platforms matching goos and goarch,
+// the expressions requiring it, and the error encountered for each expression not found.
+func (vd VersionDatas) MinVersion(exprs []string, goos string, goarch string) (string, []string, map[string]error) {
+	minVersion, responsibles, failures := "", []string(nil), map[string]error{}
+	for _, expr := range exprs {
+		pkg, symbol := vd.SplitExpr(expr)
+		symbolData, err := vd.SinceOn(pkg, symbol, goos, goarch)
+		if err != nil {
+			failures[expr] = err
+			continue
+		}
+
+		switch cmp := CompareVersion(symbolData[0], minVersion); {
+		case cmp > 0:
+			minVersion, responsibles = symbolData[0], []string{expr}
+		case cmp == 0:
+			responsibles = append(responsibles, expr)
+		}
+	}
+	return minVersion, responsibles, failures
+}
@@ -0,0 +1,253 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+const (
+	githubApiBase = "https://" + githubApiHost + "/repos/"
+	githubApiHost = "api.github.com"
+	githubRawHost = "raw.githubusercontent.com"
+	nextDir       = "next"
+	nextSeenFile  = "next.json"
+	nextVersion   = "next"
+
+	withdrawnRetention = 30 * 24 * time.Hour
+)
+
+var (
+	errListing   = errors.New("listing failure : unexpected answer")
+	errNoListing = errors.New("listing failure : directory not found")
+
+	hrefRegexp = regexp.MustCompile(`href="([^"/?#]+)"`)
+)
+
+// Api of the next Go release declared in a file of api/next (named after its proposal issue).
+type NextFile struct {
+	Name    string      // like "67890.txt"
+	Entries [][3]string // symbols in index entry form, their version is "next"
+}
+
+// Return the issue number of the proposal, from the file name.
+func (file NextFile) Issue() string {
+	return strings.TrimSuffix(file.Name, ".txt")
+}
+
+// Entry seen in api/next then removed from it without appearing in a release.
+type WithdrawnEntry struct {
+	Entry string // index entry form, like "net/http Client.Do"
+	Issue string // proposal which declared it
+}
+
+// Download and parse the files of api/next (they are not cached, they change until the release).
+func LoadNext(conf config.Config) ([]NextFile, error) {
+	names, err := ListSourceFilesWith(conf.Client, conf.SourceUrl, "api/"+nextDir, ".txt")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]NextFile, 0, len(names))
+	for _, name := range names {
+		fileUrl, err := url.JoinPath(conf.SourceUrl, "api", nextDir, name)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := DownloadWith(conf.Client, fileUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		dl, err := newDataLoader(conf)
+		if err != nil {
+			return nil, err
+		}
+		if err = dl.parseVersionData(nextVersion, "api/"+nextDir+"/"+name, data); err != nil {
+			return nil, err
+		}
+
+		var entries [][3]string
+		dl.walkPackages("*", func(pkg string, pkgSymbols map[string]symbolData) {
+			for _, data := range pkgSymbols {
+				if data.name != "" {
+					entries = append(entries, data.entry(pkg))
+				}
+			}
+		})
+		slices.SortFunc(entries, func(a [3]string, b [3]string) int {
+			return strings.Compare(a[0], b[0])
+		})
+		res = append(res, NextFile{Name: name, Entries: entries})
+	}
+	return res, nil
+}
+
+// Return the names of the files ending with suffix in the directory dir of the Go source, in lexical order,
+// sending the requests with client (http.DefaultClient when nil).
+// The GitHub contents api is used for a raw.githubusercontent.com source, else the HTML listing of the directory.
+func ListSourceFilesWith(client *http.Client, sourceUrl string, dir string, suffix string) ([]string, error) {
+	parsed, err := url.Parse(sourceUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if parsed.Host == githubRawHost {
+		// path is like "/golang/go/master"
+		owner, rest, _ := strings.Cut(strings.Trim(parsed.Path, "/"), "/")
+		repo, ref, _ := strings.Cut(rest, "/")
+		data, err := DownloadWith(client, githubApiBase+owner+"/"+repo+"/contents/"+dir+"?ref="+url.QueryEscape(ref), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var contents []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		}
+		if err = json.Unmarshal(data, &contents); err != nil {
+			return nil, errListing
+		}
+		for _, content := range contents {
+			if content.Type == "file" && strings.HasSuffix(content.Name, suffix) {
+				names = append(names, content.Name)
+			}
+		}
+	} else {
+		listingUrl, err := url.JoinPath(sourceUrl, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := DownloadWith(client, listingUrl+"/", nil)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(string(data)) == notFoundBody {
+			return nil, fmt.Errorf("%w : %s", errNoListing, listingUrl)
+		}
+		for _, match := range hrefRegexp.FindAllSubmatch(data, -1) {
+			if name := string(match[1]); strings.HasSuffix(name, suffix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// Entry recorded in next.json.
+type seenEntry struct {
+	Issue     string    `json:"issue"`
+	Withdrawn time.Time `json:"withdrawn,omitzero"` // when it was found withdrawn
+}
+
+// Record the entries of files in the cache (next.json in the cache directory) and return, sorted,
+// the previously recorded ones which are neither in files nor in the releases loaded in vd :
+// they were withdrawn before release. The entries found in vd are forgotten, the withdrawn ones
+// are reported during withdrawnRetention then forgotten too. Missing entries are only labeled
+// withdrawn when vd is confirmed to hold the latest release (api/next is emptied by a release).
+func RecordNext(conf config.Config, files []NextFile, vd VersionDatas) ([]WithdrawnEntry, error) {
+	verboseLog := newVerboseLog(conf)
+	filePath := filepath.Join(conf.RepoPath, nextSeenFile)
+	seen := readSeenNext(filePath, verboseLog)
+
+	current := map[string]string{} // issue by entry
+	for _, file := range files {
+		for _, entry := range file.Entries {
+			current[entry[0]] = file.Issue()
+		}
+	}
+
+	now := time.Now()
+	released := sync.OnceValue(func() bool { // checked only when needed
+		found, err := FetchNextVersion(conf, vd.LatestVersion())
+		if err != nil {
+			verboseLog("Withdrawal check delayed :", err)
+		}
+		return err == nil && !found
+	})
+	var res []WithdrawnEntry
+	for entry, recorded := range seen {
+		if _, ok := current[entry]; ok {
+			continue
+		}
+
+		pkg, symbol, _ := strings.Cut(entry, " ")
+		if _, err := vd.Since(pkg, symbol); err == nil {
+			delete(seen, entry) // released
+			continue
+		}
+
+		switch {
+		case recorded.Withdrawn.IsZero():
+			if !released() {
+				continue // keep it until the release state is known
+			}
+			recorded.Withdrawn = now
+			seen[entry] = recorded
+		case now.Sub(recorded.Withdrawn) > withdrawnRetention:
+			delete(seen, entry)
+			continue
+		}
+		res = append(res, WithdrawnEntry{Entry: entry, Issue: recorded.Issue})
+	}
+	for entry, issue := range current {
+		seen[entry] = seenEntry{Issue: issue}
+	}
+	slices.SortFunc(res, func(a WithdrawnEntry, b WithdrawnEntry) int {
+		return strings.Compare(a.Entry, b.Entry)
+	})
+
+	data, err := json.Marshal(seen)
+	if err != nil {
+		return nil, err
+	}
+	return res, writeFile(filePath, data)
+}
+
+// Read next.json, a failure gives an empty record.
+func readSeenNext(filePath string, verboseLog verboseLog) map[string]seenEntry {
+	seen := map[string]seenEntry{}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return seen
+	}
+	if err = json.Unmarshal(data, &seen); err != nil {
+		verboseLog("Ignore", filePath, ":", err)
+		return map[string]seenEntry{}
+	}
+	return seen
+}
@@ -0,0 +1,70 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/dvaumoron/gosince/versiondb/versiondbtest"
+)
+
+func TestRecordNext(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	server := versiondbtest.NewServer(t)
+	conf := config.Config{RepoPath: t.TempDir(), SourceUrl: server.URL}
+	vd := versiondbtest.New(t)
+
+	files := []versiondb.NextFile{{Name: "12345.txt", Entries: [][3]string{{"bytes Foo"}, {"strings Cut"}}}}
+	if withdrawn, err := versiondb.RecordNext(conf, files, vd); err != nil || len(withdrawn) != 0 {
+		t.Fatalf("RecordNext() = %v, %v, want none", withdrawn, err)
+	}
+
+	// api/next is emptied but the release check fails : nothing is labeled withdrawn yet
+	server.Close()
+	if withdrawn, err := versiondb.RecordNext(conf, nil, vd); err != nil || len(withdrawn) != 0 {
+		t.Fatalf("RecordNext() without release check = %v, %v, want none", withdrawn, err)
+	}
+
+	// the release check succeeds without a new release : strings.Cut is released, bytes.Foo is withdrawn
+	conf.SourceUrl = versiondbtest.NewServer(t).URL
+	want := []versiondb.WithdrawnEntry{{Entry: "bytes Foo", Issue: "12345"}}
+	for range 2 { // still reported on the next call
+		if withdrawn, err := versiondb.RecordNext(conf, nil, vd); err != nil || !slices.Equal(withdrawn, want) {
+			t.Fatalf("RecordNext() = %v, %v, want %v", withdrawn, err, want)
+		}
+	}
+
+	// back in api/next, it is no longer withdrawn
+	if withdrawn, err := versiondb.RecordNext(conf, files, vd); err != nil || len(withdrawn) != 0 {
+		t.Fatalf("RecordNext() = %v, %v, want none", withdrawn, err)
+	}
+
+	// an unreadable next.json is an empty record
+	if err := os.WriteFile(filepath.Join(conf.RepoPath, "next.json"), []byte(`{"bytes Foo":"12345"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if withdrawn, err := versiondb.RecordNext(conf, nil, vd); err != nil || len(withdrawn) != 0 {
+		t.Fatalf("RecordNext() with unreadable record = %v, %v, want none", withdrawn, err)
+	}
+}
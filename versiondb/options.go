@@ -0,0 +1,104 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+// Option customizes the configuration used by New.
+type Option func(*config.Config)
+
+// Load the api data, configured by the options over the defaults (the cache directory, the source and the max age
+// read from the GOSINCE_CACHE_PATH, GOSINCE_SOURCE_URL and GOSINCE_CACHE_MAX_AGE environment variables,
+// else ~/.gosince, the Go repository and a week).
+func New(opts ...Option) (VersionDatas, error) {
+	conf := config.Config{MaxAge: config.InitMaxAge(config.EnvCacheMaxAge)}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if conf.RepoPath == "" || conf.SourceUrl == "" {
+		repoPath, sourceUrl, err := config.InitDefault(config.EnvCachePath, config.EnvSourceUrl)
+		if err != nil {
+			return VersionDatas{}, err
+		}
+		if conf.RepoPath == "" {
+			conf.RepoPath = repoPath
+		}
+		if conf.SourceUrl == "" {
+			conf.SourceUrl = sourceUrl
+		}
+	}
+	return LoadDatas(conf)
+}
+
+// Cache the api files in dir.
+func WithCacheDir(dir string) Option {
+	return func(conf *config.Config) {
+		conf.RepoPath = dir
+	}
+}
+
+// Download the api files from sourceUrl (the root of a Go source tree, like "https://raw.githubusercontent.com/golang/go/master").
+func WithSource(sourceUrl string) Option {
+	return func(conf *config.Config) {
+		conf.SourceUrl = sourceUrl
+	}
+}
+
+// Download again the cached files older than maxAge (0 keeps them forever).
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(conf *config.Config) {
+		conf.MaxAge = maxAge
+	}
+}
+
+// Send the download requests with client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(conf *config.Config) {
+		conf.Client = client
+	}
+}
+
+// Never download, only use the cached api files.
+func WithOffline() Option {
+	return func(conf *config.Config) {
+		conf.Offline = true
+	}
+}
+
+// Attach the first sentence of the doc comments to the symbols (see SymbolInfo.Summary), the sources
+// of a package are downloaded when not cached, on the first call needing its summaries.
+func WithDocs() Option {
+	return func(conf *config.Config) {
+		conf.Docs = true
+	}
+}
+
+// Send the verbose messages to logger (at debug level).
+func WithLogger(logger *slog.Logger) Option {
+	return func(conf *config.Config) {
+		conf.Logger = logger
+	}
+}
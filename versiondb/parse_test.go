@@ -0,0 +1,244 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+	"github.com/dvaumoron/gosince/versiondb/versiondbtest"
+)
+
+// Return the canned api files with lines appended, by file name.
+func filesWith(lines map[string]string) fstest.MapFS {
+	files := versiondbtest.Files()
+	for fileName, added := range lines {
+		files[fileName] = &fstest.MapFile{Data: append(slices.Clone(files[fileName].Data), added...)}
+	}
+	return files
+}
+
+func loadWith(t *testing.T, lines map[string]string) versiondb.VersionDatas {
+	t.Helper()
+
+	vd, err := versiondb.LoadFS(filesWith(lines))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vd
+}
+
+func TestEmbeddedField(t *testing.T) {
+	vd := loadWith(t, map[string]string{"go1.txt": `pkg bufio, type ReadWriter struct
+pkg bufio, type ReadWriter struct, embedded *Reader
+pkg bufio, type Reader struct
+`})
+
+	if versions, err := vd.Since("bufio", "ReadWriter.Reader"); err != nil || versions[0] != "go1" {
+		t.Errorf(`Since("bufio", "ReadWriter.Reader") = %v, %v, want go1`, versions, err)
+	}
+	if field, ok := vd.Field("bufio", "ReadWriter.Reader"); !ok || field != (versiondb.Field{Type: "*Reader", Embedded: true}) {
+		t.Errorf(`Field("bufio", "ReadWriter.Reader") = %+v, %t, want an embedded *Reader`, field, ok)
+	}
+}
+
+func TestUnexportedMethods(t *testing.T) {
+	vd := loadWith(t, map[string]string{"go1.txt": `pkg go/types, type Type interface { String, Underlying }
+pkg go/types, type Type interface, String() string
+pkg go/types, type Type interface, unexported methods
+`})
+
+	if _, err := vd.Since("go/types", "Type.unexported"); !errors.Is(err, versiondb.ErrUnknownSymbol) {
+		t.Errorf(`Since("go/types", "Type.unexported") = %v, want %v`, err, versiondb.ErrUnknownSymbol)
+	}
+	if versions, err := vd.Since("go/types", "Type.String"); err != nil || versions[0] != "go1" {
+		t.Errorf(`Since("go/types", "Type.String") = %v, %v, want go1`, versions, err)
+	}
+	if diagnostics := vd.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Diagnostics() = %v, want none", diagnostics)
+	}
+}
+
+func TestLenientLoading(t *testing.T) {
+	lines := map[string]string{"go1.18.txt": "pkg strings func Broken()\n"}
+	vd := loadWith(t, lines)
+
+	diagnostics := vd.Diagnostics()
+	if len(diagnostics) != 1 || diagnostics[0].Line != 2 || !strings.HasSuffix(diagnostics[0].File, "go1.18.txt") {
+		t.Fatalf("Diagnostics() = %v, want one on line 2 of go1.18.txt", diagnostics)
+	}
+	if versions, err := vd.Since("strings", "Cut"); err != nil || versions[0] != "go1.18" {
+		t.Errorf(`Since("strings", "Cut") = %v, %v, want go1.18 (the other lines are kept)`, versions, err)
+	}
+
+	cacheDir := t.TempDir()
+	for fileName, file := range filesWith(lines) {
+		if err := os.WriteFile(filepath.Join(cacheDir, fileName), file.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := versiondb.LoadDatas(config.Config{RepoPath: cacheDir, Offline: true}); err != nil {
+		t.Errorf("lenient loading of a malformed line failed : %v", err)
+	}
+	if _, err := versiondb.LoadDatas(config.Config{RepoPath: cacheDir, Offline: true, Strict: true}); err == nil {
+		t.Error("strict loading of a malformed line succeeded, want an error")
+	}
+}
+
+func TestAlias(t *testing.T) {
+	vd := loadWith(t, map[string]string{
+		"go1.txt": "pkg os, type FileMode uint32\n",
+		"go1.16.txt": `pkg io/fs, type FileMode uint32
+pkg os, type FileMode = fs.FileMode
+`,
+	})
+
+	alias, ok := vd.Alias("os", "FileMode")
+	want := versiondb.Alias{Target: "io/fs.FileMode", Since: "go1.16", Package: "io/fs", Symbol: "FileMode"}
+	if !ok || alias != want {
+		t.Errorf(`Alias("os", "FileMode") = %+v, %t, want %+v`, alias, ok, want)
+	}
+	if versions, err := vd.Since("os", "FileMode"); err != nil || versions[0] != "go1" {
+		t.Errorf(`Since("os", "FileMode") = %v, %v, want go1`, versions, err)
+	}
+	if _, ok = vd.Alias("io/fs", "FileMode"); ok {
+		t.Error(`Alias("io/fs", "FileMode") found, want none`)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	vd := loadWith(t, map[string]string{"go1.txt": `pkg time, type Duration int64
+pkg time, type Time struct
+pkg time, method (Time) Add(Duration) Time
+`})
+
+	for _, test := range [][3]string{
+		{"bytes", "Buffer.String", "(*Buffer).String"},
+		{"time", "time.add", "(Time).Add"},
+		{"fmt", "println", "Println"},
+	} {
+		if name := vd.DisplayName(test[0], test[1]); name != test[2] {
+			t.Errorf("DisplayName(%q, %q) = %q, want %q", test[0], test[1], name, test[2])
+		}
+	}
+}
+
+func TestField(t *testing.T) {
+	vd := versiondbtest.New(t)
+
+	if field, ok := vd.Field("net/http", "Transport.ForceAttemptHTTP2"); !ok || field != (versiondb.Field{Type: "bool"}) {
+		t.Errorf(`Field("net/http", "Transport.ForceAttemptHTTP2") = %+v, %t, want a bool field`, field, ok)
+	}
+	if _, ok := vd.Field("strings", "Builder.String"); ok {
+		t.Error(`Field("strings", "Builder.String") found, want none for a method`)
+	}
+}
+
+func TestMembers(t *testing.T) {
+	vd := loadWith(t, map[string]string{
+		"go1.txt": `pkg net, type Conn interface { Close, Read }
+pkg net, type Conn interface, Close() error
+pkg net, type Conn interface, Read([]uint8) (int, error)
+pkg net/http, type Transport struct
+pkg time, type Month int
+pkg time, const January Month
+pkg time, const January = 1
+`,
+		"go1.10.txt": "pkg net, type Conn interface, SetDeadline(time.Time) error\n",
+	})
+
+	for _, test := range []struct {
+		pkg      string
+		typeName string
+		want     [][3]string
+	}{
+		{pkg: "net", typeName: "Conn", want: [][3]string{
+			{"net Conn.Close", "go1", ""}, {"net Conn.Read", "go1", ""}, {"net Conn.SetDeadline", "go1.10", ""},
+		}},
+		{pkg: "time", typeName: "Month", want: [][3]string{{"time January", "go1", ""}}},
+		{pkg: "http", typeName: "Transport", want: [][3]string{{"net/http Transport.ForceAttemptHTTP2", "go1.13", ""}}},
+	} {
+		members, err := vd.Members(test.pkg, test.typeName)
+		slices.SortFunc(members, func(a, b [3]string) int { return strings.Compare(a[0], b[0]) })
+		if err != nil || !slices.Equal(members, test.want) {
+			t.Errorf("Members(%q, %q) = %v, %v, want %v", test.pkg, test.typeName, members, err, test.want)
+		}
+	}
+	if _, err := vd.Members("net", "Unknown"); !errors.Is(err, versiondb.ErrUnknownSymbol) {
+		t.Errorf(`Members("net", "Unknown") error = %v, want %v`, err, versiondb.ErrUnknownSymbol)
+	}
+}
+
+func TestConst(t *testing.T) {
+	vd := loadWith(t, map[string]string{"go1.13.txt": `pkg syscall (windows-amd64), const O_DIRECT = 0
+pkg syscall (windows-amd64), const O_DIRECT ideal-int
+`})
+
+	for _, test := range []struct {
+		pkg    string
+		symbol string
+		want   versiondb.Const
+	}{
+		{pkg: "io", symbol: "SeekStart", want: versiondb.Const{Type: "int", Untyped: true, Value: "0"}},
+		{pkg: "syscall", symbol: "O_DIRECT", want: versiondb.Const{Type: "int", Untyped: true, Varies: true}},
+	} {
+		if constant, ok := vd.Const(test.pkg, test.symbol); !ok || constant != test.want {
+			t.Errorf("Const(%q, %q) = %+v, %t, want %+v", test.pkg, test.symbol, constant, ok, test.want)
+		}
+	}
+	if _, ok := vd.Const("fmt", "Println"); ok {
+		t.Error(`Const("fmt", "Println") found, want none for a function`)
+	}
+}
+
+func TestCase(t *testing.T) {
+	vd := loadWith(t, map[string]string{"go1.txt": `pkg html/template, type JS string
+pkg syscall (windows-386), const TokenUser = 1
+pkg syscall (windows-386), const TokenUser ideal-int
+`, "go1.10.txt": `pkg syscall (windows-386), const Tokenuser = 1
+pkg syscall (windows-386), const Tokenuser ideal-int
+`})
+
+	if name := vd.SymbolName("html/template", "js"); name != "JS" {
+		t.Errorf(`SymbolName("html/template", "js") = %q, want "JS"`, name)
+	}
+	if versions, err := vd.Since("html/template", "js"); err != nil || versions[0] != "go1" {
+		t.Errorf(`Since("html/template", "js") = %v, %v, want go1`, versions, err)
+	}
+	if _, err := vd.ExactCase().Since("html/template", "js"); !errors.Is(err, versiondb.ErrUnknownSymbol) {
+		t.Errorf(`ExactCase().Since("html/template", "js") error = %v, want %v`, err, versiondb.ErrUnknownSymbol)
+	}
+
+	// symbols differing only by case are both kept
+	for symbol, want := range map[string]string{"TokenUser": "go1", "Tokenuser": "go1.10"} {
+		if versions, err := vd.Since("syscall", symbol); err != nil || versions[0] != want {
+			t.Errorf(`Since("syscall", %q) = %v, %v, want %s`, symbol, versions, err, want)
+		}
+	}
+	if pkg, symbol := vd.SplitExpr("HTML/template.js"); pkg != "html/template" || symbol != "js" {
+		t.Errorf(`SplitExpr("HTML/template.js") = %q, %q, want "html/template", "js"`, pkg, symbol)
+	}
+}
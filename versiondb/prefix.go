@@ -0,0 +1,82 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Sorted index keys and package paths, built on the first prefix query (the loading is over by then),
+// a prefix query is then a binary search instead of a scan of the index.
+type prefixIndex struct {
+	once     sync.Once
+	keys     []string
+	packages []string
+}
+
+func (vd VersionDatas) sortedKeys() ([]string, []string) {
+	if vd.prefixes == nil {
+		return nil, nil // empty database
+	}
+
+	vd.prefixes.once.Do(func() {
+		vd.prefixes.keys = slices.Sorted(maps.Keys(vd.index))
+		vd.prefixes.packages = slices.Sorted(maps.Keys(vd.data))
+	})
+	return vd.prefixes.keys, vd.prefixes.packages
+}
+
+// Return the index entries whose name (or receiver-scoped name like "Buffer.Write") starts with prefix,
+// sorted by entry. The case is ignored.
+func (vd VersionDatas) SearchPrefix(prefix string) [][3]string {
+	keys, _ := vd.sortedKeys()
+	seen := map[string]struct{}{}
+	var res [][3]string
+	for _, key := range withPrefix(keys, strings.ToLower(prefix)) {
+		for _, entry := range vd.index[key] {
+			if _, ok := seen[entry[0]]; !ok {
+				seen[entry[0]] = struct{}{}
+				res = append(res, entry)
+			}
+		}
+	}
+	slices.SortFunc(res, func(a [3]string, b [3]string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	return res
+}
+
+// Return the package paths starting with prefix, sorted. The case is ignored.
+func (vd VersionDatas) PackagesWithPrefix(prefix string) []string {
+	_, packages := vd.sortedKeys()
+	return slices.Clone(withPrefix(packages, strings.ToLower(prefix)))
+}
+
+// Return the part of sorted starting with prefix.
+func withPrefix(sorted []string, prefix string) []string {
+	start, _ := slices.BinarySearch(sorted, prefix)
+	end := start
+	for end < len(sorted) && strings.HasPrefix(sorted[end], prefix) {
+		end++
+	}
+	return sorted[start:end]
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import "strings"
+
+// Return expr in <pkg>.<sym>[.<methodOrField>] form, so copy-pasted expressions work : method expressions as written
+// by godoc and compiler errors (like "(*bytes.Buffer).WriteTo" or "(time.Time).Add") are rewritten ("bytes.Buffer.WriteTo"),
+// quoted import paths ("\"net/http\".Client"), trailing call parentheses ("io.ReadAll()") and "std/" prefix are removed.
+func NormalizeExpr(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if indexParen := strings.IndexByte(expr[min(1, len(expr)):], '('); indexParen != -1 && strings.HasSuffix(expr, ")") {
+		expr = expr[:indexParen+1] // call, like "io.ReadAll(r)" (a method expression starts with a parenthesis)
+	}
+	if rest, ok := strings.CutPrefix(expr, `"`); ok {
+		if path, symbol, ok := strings.Cut(rest, `"`); ok {
+			expr = path + symbol
+		}
+	}
+	expr = strings.TrimPrefix(expr, "std/")
+	if rest, ok := strings.CutPrefix(expr, "("); ok {
+		if receiver, method, ok := strings.Cut(rest, ")"); ok {
+			receiver = strings.TrimPrefix(receiver, "*")
+			receiver, _, _ = strings.Cut(receiver, "[") // type arguments, like "(*atomic.Pointer[T]).Load"
+			expr = receiver + method
+		}
+	}
+	return expr
+}
+
+// Split an expression in <pkg> or <pkg>.<sym>[.<methodOrField>] form (see NormalizeExpr) : the longest prefix ending
+// before a dot and naming a loaded package is the package (like "net/http" in "net/http.Client.Do"), else it is split
+// like SplitExpr does. The package is lowercased, the symbol keeps its case (lookups ignore it unless ExactCase is used).
+func (vd VersionDatas) SplitExpr(expr string) (string, string) {
+	expr = NormalizeExpr(expr)
+	exprLower := strings.ToLower(expr)
+	if _, ok := vd.data[exprLower]; ok {
+		return exprLower, ""
+	}
+
+	indexDot := strings.LastIndexByte(exprLower, '.')
+	for ; indexDot != -1; indexDot = strings.LastIndexByte(exprLower[:indexDot], '.') {
+		if _, ok := vd.data[exprLower[:indexDot]]; ok {
+			return exprLower[:indexDot], expr[indexDot+1:]
+		}
+	}
+
+	indexSlash := strings.LastIndexByte(expr, '/')
+	if indexDot = strings.IndexByte(expr[indexSlash+1:], '.'); indexDot == -1 { // no error when indexSlash is -1
+		return exprLower, ""
+	}
+	indexDot += indexSlash + 1
+	return exprLower[:indexDot], expr[indexDot+1:]
+}
+
+// Return a copy of the database where lookups and searches respect the case of symbols
+// (like template.JS, not matched by template.js).
+func (vd VersionDatas) ExactCase() VersionDatas {
+	vd.exactCase = true
+	return vd
+}
@@ -0,0 +1,119 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+const (
+	releaseFile    = "release.tag" // last checked release tag, followed by unreleasedMark when it does not exist yet
+	unreleasedMark = "unreleased"
+)
+
+// Return the Go source tag of version (like "go1.22.0", or "go1.20" before go1.21).
+func releaseTag(version string) string {
+	if MinorVersion(version) < 21 {
+		return version
+	}
+	return version + ".0"
+}
+
+// Tell if the release tag exists in the Go source of conf, the api file of a release lands on master at the API
+// freeze, months before the release. A found tag is cached for good, a missing one until the max age (it is checked
+// again by each loading without max age). A release which can not be checked (offline without cache, source which
+// does not follow master or check failure) is supposed published.
+func checkRelease(conf config.Config, tag string) bool {
+	log := newVerboseLog(conf)
+	cachePath := path.Join(conf.RepoPath, releaseFile)
+	cachedUnreleased := false
+	if data, err := os.ReadFile(cachePath); err == nil {
+		cachedTag, unreleased := strings.CutSuffix(strings.TrimSpace(string(data)), " "+unreleasedMark)
+		if cachedTag == tag {
+			if !unreleased {
+				return true
+			}
+			if info, err := os.Stat(cachePath); conf.Offline || (err == nil && conf.MaxAge > 0 && time.Since(info.ModTime()) <= conf.MaxAge) {
+				return false
+			}
+			cachedUnreleased = true
+		}
+	}
+	if conf.Offline {
+		return true
+	}
+
+	released, err := releaseExists(conf, tag)
+	if err != nil {
+		log("Failed to check the release tag", tag, ":", err)
+		return !cachedUnreleased
+	}
+
+	data := tag
+	if !released {
+		log("The tag", tag, "does not exist yet, the previous release is the latest one")
+		data += " " + unreleasedMark
+	}
+	if err = writeFile(cachePath, []byte(data+"\n")); err != nil {
+		log("Failed to cache the release tag in", cachePath, ":", err)
+	}
+	return released
+}
+
+// Tell if the tag exists, in the refs of the git repository or as the VERSION file of the tagged source tree.
+func releaseExists(conf config.Config, tag string) (bool, error) {
+	if conf.GitUrl != "" {
+		remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: gitRemote, URLs: []string{conf.GitUrl}})
+		refs, err := remote.List(&git.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, ref := range refs {
+			if ref.Name() == plumbing.NewTagReferenceName(tag) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	tagConf, err := conf.WithRef(tag)
+	if err != nil {
+		return true, nil // not a source following master, like the one of a tag
+	}
+
+	versionUrl, err := url.JoinPath(tagConf.SourceUrl, "VERSION")
+	if err != nil {
+		return false, err
+	}
+	data, err := DownloadWith(conf.Client, versionUrl, nil)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(data)) != notFoundBody, nil
+}
@@ -0,0 +1,289 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"go/doc"
+	"html"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+const (
+	defaultNotesUrl = "https://raw.githubusercontent.com/golang/website/master/_content/doc"
+	notesDir        = "notes"
+	notesPageUrl    = "https://go.dev/doc/"
+)
+
+var (
+	ErrNoNotes = errors.New("release notes failure : no release notes found")
+
+	htmlHeadingRegexp     = regexp.MustCompile(`^<h[1-6](?:\s+id="([^"]*)")?[^>]*>(.*?)</h[1-6]>`)
+	htmlPackageRegexp     = regexp.MustCompile(`<dl id="([^"]+)"[^>]*>(?:\s*<dt>.*?</dt>)?`)
+	htmlParagraphRegexp   = regexp.MustCompile(`</?(?:p|dd|dl|dt|li|ul|ol|blockquote)(?:\s[^>]*)?>`)
+	htmlTagRegexp         = regexp.MustCompile(`<[^>]*>`)
+	markdownHeadingRegexp = regexp.MustCompile(`^#{1,6}\s+(.*?)(?:\s*\{#([^}]*)\})?$`)
+	markdownItemRegexp    = regexp.MustCompile(`^(?:[-*+]|\d+\.)\s+`)
+	notesLinkRegexp       = regexp.MustCompile(`\[((?:[^\[\]]|\[[^\]]*\])*)\]\([^)]*\)`) // the text can contain brackets, like "[Null[T]](/pkg/database/sql#Null)"
+	markdownPackageRegexp = regexp.MustCompile(`^\[([^\]]*)\]\(/pkg/([^)#]+?)/?\)$`)
+)
+
+// Release notes of a major release, split in sections.
+type ReleaseNotes struct {
+	Version  string
+	Url      string // of the published page, like "https://go.dev/doc/go1.21"
+	Sections []NotesSection
+}
+
+// Paragraph of the release notes, with the url of its section.
+type NotesParagraph struct {
+	Text string
+	Url  string
+}
+
+// Section of the release notes, under a heading.
+type NotesSection struct {
+	Title      string
+	Anchor     string   // id of the heading on the published page, if any
+	Package    string   // import path for the section of a package in the library changes, like "net/http"
+	Paragraphs []string // as plain text
+}
+
+// Read the release notes of version (like "go1.21") from the local cache, or download them from the website
+// repository (go1.N.md, or go1.N.html for old releases) then from the doc directory of the Go source.
+func LoadReleaseNotes(conf config.Config, version string) (ReleaseNotes, error) {
+	normalized := NormalizeVersion(version) // the notes of a point release are the ones of its major release
+	if normalized == "" {
+		return ReleaseNotes{}, ErrUnknownVersion
+	}
+
+	notesUrl := conf.NotesUrl
+	if notesUrl == "" {
+		notesUrl = defaultNotesUrl
+	}
+	var candidates [][2]string // file name and base url
+	for _, ext := range []string{".md", ".html"} {
+		candidates = append(candidates, [2]string{normalized + ext, notesUrl})
+	}
+	candidates = append(candidates, [2]string{normalized + ".html", conf.SourceUrl + "/doc"})
+
+	for _, candidate := range candidates {
+		if data, err := os.ReadFile(path.Join(conf.RepoPath, notesDir, candidate[0])); err == nil {
+			return parseReleaseNotes(normalized, data), nil
+		}
+	}
+
+	for _, candidate := range candidates {
+		fileUrl, err := url.JoinPath(candidate[1], candidate[0])
+		if err != nil {
+			return ReleaseNotes{}, err
+		}
+
+		newVerboseLog(conf)("Download the release notes from", fileUrl)
+		data, err := DownloadWith(conf.Client, fileUrl, conf.Progress)
+		if err != nil {
+			return ReleaseNotes{}, err
+		}
+		if strings.TrimSpace(string(data)) == notFoundBody {
+			continue
+		}
+
+		if err = writeFile(path.Join(conf.RepoPath, notesDir, candidate[0]), data); err != nil {
+			return ReleaseNotes{}, err
+		}
+		return parseReleaseNotes(normalized, data), nil
+	}
+	return ReleaseNotes{}, ErrNoNotes
+}
+
+// Return the url of the published release notes of version (like "https://go.dev/doc/go1.21").
+func NotesUrl(version string) string {
+	return notesPageUrl + NormalizeVersion(version)
+}
+
+// Return the sections of the library changes about the package pkg.
+func (rn ReleaseNotes) Package(pkg string) []NotesSection {
+	var res []NotesSection
+	for _, section := range rn.Sections {
+		if strings.EqualFold(section.Package, pkg) {
+			res = append(res, section)
+		}
+	}
+	return res
+}
+
+// Return the paragraphs about the package pkg, or about its symbol (like "Client.Do") when not empty : the ones of
+// the package section mentioning the symbol (all of them when none does), or the ones of other sections mentioning
+// the package path when it has no section (like a new package).
+func (rn ReleaseNotes) About(pkg string, symbol string) []NotesParagraph {
+	var res []NotesParagraph
+	sections := rn.Package(pkg)
+	if symbol != "" {
+		_, member, _ := strings.Cut(symbol, ".")
+		symbolRegexp := wordRegexp(symbol)
+		memberRegexp := wordRegexp(member) // "Do" mentioned in the section of net/http likely describes Client.Do
+		for _, section := range sections {
+			for _, paragraph := range section.Paragraphs {
+				if symbolRegexp.MatchString(paragraph) || (member != "" && memberRegexp.MatchString(paragraph)) {
+					res = append(res, NotesParagraph{Text: paragraph, Url: rn.SectionUrl(section)})
+				}
+			}
+		}
+		if len(res) != 0 {
+			return res
+		}
+	}
+
+	for _, section := range sections {
+		for _, paragraph := range section.Paragraphs {
+			res = append(res, NotesParagraph{Text: paragraph, Url: rn.SectionUrl(section)})
+		}
+	}
+	if len(sections) != 0 {
+		return res
+	}
+
+	pkgRegexp := wordRegexp(pkg)
+	for _, section := range rn.Sections {
+		for _, paragraph := range section.Paragraphs {
+			if pkgRegexp.MatchString(paragraph) {
+				res = append(res, NotesParagraph{Text: paragraph, Url: rn.SectionUrl(section)})
+			}
+		}
+	}
+	return res
+}
+
+// Return the url of the section on the published page.
+func (rn ReleaseNotes) SectionUrl(section NotesSection) string {
+	if section.Anchor == "" {
+		return rn.Url
+	}
+	return rn.Url + "#" + section.Anchor
+}
+
+// Return the first sentence of the section.
+func (ns NotesSection) Summary() string {
+	if len(ns.Paragraphs) == 0 {
+		return ""
+	}
+
+	var docPkg doc.Package // only used for its Synopsis method
+	return docPkg.Synopsis(ns.Paragraphs[0])
+}
+
+// Split markdown (with inline html) or html release notes in sections : a heading (markdown or html) starts
+// a section, like a <dl id="net/http"> for a package in old releases, the paragraphs end with a blank line
+// or a block tag, the front matter, comments, styles and code blocks are skipped.
+func parseReleaseNotes(version string, data []byte) ReleaseNotes {
+	res := ReleaseNotes{Version: version, Url: NotesUrl(version)}
+	var section *NotesSection
+	var paragraph []string
+	endParagraph := func() {
+		if text := cleanNotesText(strings.Join(paragraph, " ")); text != "" && section != nil {
+			section.Paragraphs = append(section.Paragraphs, text)
+		}
+		paragraph = paragraph[:0]
+	}
+	startSection := func(newSection NotesSection) {
+		endParagraph()
+		res.Sections = append(res.Sections, newSection)
+		section = &res.Sections[len(res.Sections)-1]
+	}
+
+	skipUntil := "" // end marker of the skipped block
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if skipUntil != "" {
+			if strings.Contains(line, skipUntil) {
+				skipUntil = ""
+			}
+			continue
+		}
+
+		switch {
+		case lineNumber == 0 && line == "---":
+			skipUntil = "---"
+		case strings.HasPrefix(line, "```"):
+			endParagraph()
+			skipUntil = "```"
+		case strings.HasPrefix(line, "<!--") && !strings.Contains(line, "-->"):
+			skipUntil = "-->"
+		case strings.HasPrefix(line, "<style"), strings.HasPrefix(line, "<pre"):
+			endParagraph()
+			if tag := line[1:4]; !strings.Contains(line, "</"+tag) {
+				skipUntil = "</" + tag
+			}
+		case strings.HasPrefix(line, "#"):
+			if match := markdownHeadingRegexp.FindStringSubmatch(line); match != nil {
+				newSection := NotesSection{Title: match[1], Anchor: match[2]}
+				if pkgMatch := markdownPackageRegexp.FindStringSubmatch(match[1]); pkgMatch != nil {
+					newSection.Title, newSection.Package = markdownCodeReplace.Replace(pkgMatch[1]), pkgMatch[2]
+				}
+				newSection.Title = cleanNotesText(newSection.Title)
+				startSection(newSection)
+			}
+		case htmlHeadingRegexp.MatchString(line):
+			match := htmlHeadingRegexp.FindStringSubmatch(line)
+			startSection(NotesSection{Title: cleanNotesText(match[2]), Anchor: match[1]})
+		case line == "":
+			endParagraph()
+		case markdownItemRegexp.MatchString(line):
+			endParagraph()
+			paragraph = append(paragraph, markdownItemRegexp.ReplaceAllString(line, ""))
+		default:
+			if match := htmlPackageRegexp.FindStringSubmatchIndex(line); match != nil {
+				pkg := line[match[2]:match[3]]
+				startSection(NotesSection{Title: pkg, Anchor: pkg, Package: pkg})
+				line = line[match[1]:]
+			}
+			for index, part := range htmlParagraphRegexp.Split(line, -1) {
+				if index != 0 {
+					endParagraph()
+				}
+				if part = strings.TrimSpace(part); part != "" {
+					paragraph = append(paragraph, part)
+				}
+			}
+		}
+	}
+	endParagraph()
+	return res
+}
+
+// Return the text of a markdown or html fragment (without links, code marks and tags).
+func cleanNotesText(text string) string {
+	text = markdownCodeReplace.Replace(notesLinkRegexp.ReplaceAllString(text, "$1"))
+	text = html.UnescapeString(htmlTagRegexp.ReplaceAllString(text, ""))
+	return strings.TrimSpace(whitespaceRegexp.ReplaceAllString(text, " "))
+}
+
+// Return a regexp matching name as a whole word (not inside a longer name or package path).
+func wordRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^\w/.])` + regexp.QuoteMeta(name) + `(?:$|[^\w/])`)
+}
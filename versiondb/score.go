@@ -0,0 +1,96 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Match kinds of ScoredEntry.
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+	MatchFuzzy  = "fuzzy" // the letters of the query appear in order in the name
+)
+
+// Index entry matching a query, as returned by SearchScored.
+type ScoredEntry struct {
+	Entry [3]string // {entry, addedIn, deprecatedIn}, like the ones returned by Search
+	Match string    // one of the Match constants
+	Score float64   // 1 for an exact match, in [0.5, 1) for a prefix one and in (0, 0.5) for a fuzzy one
+}
+
+// Return the entries whose name (or receiver-scoped name like "Buffer.Write") matches query, the best scores first.
+// The case is ignored, ties are sorted by entry.
+func (vd VersionDatas) SearchScored(query string) []ScoredEntry {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+
+	best := map[string]ScoredEntry{}
+	for key, entries := range vd.index {
+		match, score, ok := scoreKey(key, query)
+		if !ok {
+			continue
+		}
+		for _, entry := range entries {
+			if previous, seen := best[entry[0]]; !seen || previous.Score < score {
+				best[entry[0]] = ScoredEntry{Entry: entry, Match: match, Score: score}
+			}
+		}
+	}
+
+	res := make([]ScoredEntry, 0, len(best))
+	for _, scored := range best {
+		res = append(res, scored)
+	}
+	slices.SortFunc(res, func(a ScoredEntry, b ScoredEntry) int {
+		if res := cmp.Compare(b.Score, a.Score); res != 0 {
+			return res
+		}
+		return strings.Compare(a.Entry[0], b.Entry[0])
+	})
+	return res
+}
+
+// Score key (an index key) against query, shorter keys and contiguous letters score higher.
+func scoreKey(key string, query string) (string, float64, bool) {
+	switch {
+	case key == query:
+		return MatchExact, 1, true
+	case strings.HasPrefix(key, query):
+		return MatchPrefix, 0.5 + 0.5*float64(len(query))/float64(len(key)+1), true
+	}
+
+	gaps, index := 0, 0
+	for i := 0; i < len(query); i++ {
+		found := strings.IndexByte(key[index:], query[i])
+		if found == -1 {
+			return "", 0, false
+		}
+		if found != 0 && i != 0 {
+			gaps++
+		}
+		index += found + 1
+	}
+	return MatchFuzzy, 0.5 * float64(len(query)) / float64(len(key)+1) / float64(gaps+1), true
+}
@@ -0,0 +1,191 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+var (
+	// aliases used in queries but never in api files
+	typeAliases = map[string]string{"any": "interface{}", "byte": "uint8", "rune": "int32"}
+
+	typeParamRegexp = regexp.MustCompile(`\$(\d+)`)
+	typeSetRegexp   = regexp.MustCompile(`interface\{ ([^{}]*[~|][^{}]*) \}`) // only usable as constraint
+)
+
+// Return the declaration of the symbol as written in api files (without the package part), type parameters
+// are made readable : "func Clip[$0 interface{ ~[]$1 }, $1 interface{}]($0) $0" gives "func Clip[T0 ~[]T1, T1 any](T0) T0".
+func (vd VersionDatas) Declaration(pkg string, symbol string) (string, error) {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil {
+		return "", err
+	}
+	return readableDefinition(data.definition), nil
+}
+
+func readableDefinition(definition string) string {
+	if !strings.Contains(definition, "$") {
+		return definition
+	}
+
+	// generic declarations need go1.18, so any can be used everywhere
+	definition = typeSetRegexp.ReplaceAllString(definition, "$1")
+	definition = strings.ReplaceAll(definition, "interface{}", "any")
+	return typeParamRegexp.ReplaceAllString(definition, "T$1")
+}
+
+// Return func and method entries (restricted to those matching name when not empty)
+// whose parameters contain all of params and results contain all of results.
+// Types are written as in Go code (like "context.Context" or "[]byte").
+func (vd VersionDatas) SearchSignature(name string, params []string, results []string) ([][3]string, error) {
+	var err error
+	if params, err = normalizeTypes(params); err != nil {
+		return nil, err
+	}
+	if results, err = normalizeTypes(results); err != nil {
+		return nil, err
+	}
+
+	nameLower := strings.ToLower(name)
+	var res [][3]string
+	for pkg, pkgSymbols := range vd.data {
+		for symbolLower, data := range pkgSymbols {
+			if nameLower != "" && !matchName(nameLower, symbolLower) {
+				continue
+			}
+
+			funcType := parseFuncType(data.definition)
+			if funcType == nil {
+				continue
+			}
+
+			pkgName := pkg[strings.LastIndexByte(pkg, '/')+1:] // no error when there is no slash
+			if containsTypes(funcType.Params, pkgName, params) && containsTypes(funcType.Results, pkgName, results) {
+				res = append(res, data.entry(pkg))
+			}
+		}
+	}
+	return res, nil
+}
+
+func containsTypes(fields *ast.FieldList, pkgName string, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	if fields == nil {
+		return false
+	}
+
+	available := map[string]struct{}{}
+	for _, field := range fields.List {
+		available[types.ExprString(field.Type)] = struct{}{}
+		available[types.ExprString(qualify(field.Type, pkgName))] = struct{}{}
+	}
+
+	for _, typeStr := range wanted {
+		if _, ok := available[typeStr]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Check name against the complete symbol or its last part.
+func matchName(nameLower string, symbolLower string) bool {
+	if nameLower == symbolLower {
+		return true
+	}
+	indexDot := strings.LastIndexByte(symbolLower, '.')
+	return indexDot != -1 && nameLower == symbolLower[indexDot+1:]
+}
+
+func normalizeTypes(typeStrs []string) ([]string, error) {
+	res := make([]string, 0, len(typeStrs))
+	for _, typeStr := range typeStrs {
+		expr, err := parser.ParseExpr(typeStr)
+		if err != nil {
+			return nil, err
+		}
+
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				if replacement, ok := typeAliases[ident.Name]; ok {
+					ident.Name = replacement
+				}
+			}
+			return true
+		})
+		res = append(res, types.ExprString(expr))
+	}
+	return res, nil
+}
+
+// Parse the signature of a "func" or "method" definition, return nil for other kinds.
+func parseFuncType(definition string) *ast.FuncType {
+	funcDef, ok := strings.CutPrefix(definition, "func ")
+	if !ok {
+		if funcDef, ok = strings.CutPrefix(definition, "method "); !ok {
+			return nil
+		}
+	}
+
+	var srcBuilder strings.Builder
+	srcBuilder.WriteString("package p\nfunc ")
+	srcBuilder.WriteString(strings.ReplaceAll(funcDef, "$", "_")) // type parameters are named $0, $1...
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", srcBuilder.String(), parser.SkipObjectResolution)
+	if err != nil || len(file.Decls) == 0 {
+		return nil
+	}
+
+	funcDecl, ok := file.Decls[0].(*ast.FuncDecl)
+	if !ok {
+		return nil
+	}
+	return funcDecl.Type
+}
+
+// Return a copy of expr where exported identifiers from the current package are qualified with pkgName.
+func qualify(expr ast.Expr, pkgName string) ast.Expr {
+	switch typed := expr.(type) {
+	case *ast.Ident:
+		if typed.IsExported() {
+			return &ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: typed}
+		}
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualify(typed.X, pkgName)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: typed.Len, Elt: qualify(typed.Elt, pkgName)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualify(typed.Key, pkgName), Value: qualify(typed.Value, pkgName)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: typed.Dir, Value: qualify(typed.Value, pkgName)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualify(typed.Elt, pkgName)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: qualify(typed.X, pkgName), Index: qualify(typed.Index, pkgName)}
+	}
+	return expr
+}
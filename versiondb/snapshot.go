@@ -0,0 +1,107 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	errSnapshotLine     = errors.New("snapshot failure : malformed line")
+	errSnapshotMismatch = errors.New("snapshot failure : content differs from the pinned one")
+	errSnapshotMissing  = errors.New("snapshot failure : pinned file not found")
+)
+
+// Check the content of the api file fileName against the snapshot (when there is one) and keep its hash.
+func (dl dataLoader) record(fileName string, data []byte) error {
+	hash := hashData(data)
+	if pinned, ok := dl.snapshot[fileName]; ok && pinned != hash {
+		return fmt.Errorf("%s : %w", fileName, errSnapshotMismatch)
+	}
+
+	dl.hashes[fileName] = hash
+	return nil
+}
+
+// Return the sha256 of the loaded api files by file name (like "go1.21.txt"), to pin them with WriteSnapshot.
+func (vd VersionDatas) Snapshot() map[string]string {
+	return maps.Clone(vd.hashes)
+}
+
+// Read a snapshot file, in the format of sha256sum ("<hash>  <file name>" lines).
+func ReadSnapshot(filePath string) (map[string]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSnapshot(file, filePath)
+}
+
+// Write a snapshot file, in the format of sha256sum sorted by file name.
+func WriteSnapshot(filePath string, snapshot map[string]string) error {
+	return os.WriteFile(filePath, formatSnapshot(snapshot), 0644)
+}
+
+func parseSnapshot(reader io.Reader, name string) (map[string]string, error) {
+	res := map[string]string{}
+	lineNumber := 0
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		hash, fileName, ok := strings.Cut(line, " ")
+		fileName = strings.TrimSpace(fileName)
+		if !ok || fileName == "" {
+			return nil, fmt.Errorf("%s:%d: %w", name, lineNumber, errSnapshotLine)
+		}
+		res[fileName] = hash
+	}
+	return res, scanner.Err()
+}
+
+func formatSnapshot(snapshot map[string]string) []byte {
+	var builder strings.Builder
+	for _, fileName := range slices.Sorted(maps.Keys(snapshot)) {
+		builder.WriteString(snapshot[fileName])
+		builder.WriteString("  ")
+		builder.WriteString(fileName)
+		builder.WriteByte('\n')
+	}
+	return []byte(builder.String())
+}
+
+func hashData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,78 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+const (
+	SortName    = "name"
+	SortPackage = "package"
+	SortVersion = "version"
+)
+
+var ErrUnknownSort = errors.New("unknown sort order, expect name, package or version")
+
+// Sort in place entries (as returned by Search) following order, ties are broken with the other criteria.
+func SortEntries(entries [][3]string, order string) error {
+	var cmp func([3]string, [3]string) int
+	switch order {
+	case SortName:
+		cmp = func(a [3]string, b [3]string) int {
+			if res := strings.Compare(strings.ToLower(entryName(a[0])), strings.ToLower(entryName(b[0]))); res != 0 {
+				return res
+			}
+			if res := strings.Compare(a[0], b[0]); res != 0 {
+				return res
+			}
+			return CompareVersion(a[1], b[1])
+		}
+	case SortPackage:
+		cmp = func(a [3]string, b [3]string) int {
+			if res := strings.Compare(a[0], b[0]); res != 0 {
+				return res
+			}
+			return CompareVersion(a[1], b[1])
+		}
+	case SortVersion:
+		cmp = func(a [3]string, b [3]string) int {
+			if res := CompareVersion(a[1], b[1]); res != 0 {
+				return res
+			}
+			return strings.Compare(a[0], b[0])
+		}
+	default:
+		return ErrUnknownSort
+	}
+
+	slices.SortStableFunc(entries, cmp)
+	return nil
+}
+
+// Return the symbol part of an entry, or the last element of the package path.
+func entryName(entry string) string {
+	if indexSpace := strings.IndexByte(entry, ' '); indexSpace != -1 {
+		return entry[indexSpace+1:]
+	}
+	indexSlash := strings.LastIndexByte(entry, '/')
+	return entry[indexSlash+1:] // no error when indexSlash is -1
+}
@@ -0,0 +1,538 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+const (
+	docsFile    = "docs.json" // doc comments extracted from the cached sources, by package
+	examplesDir = "examples"  // test files declaring examples, by package (empty directory when there is none)
+	srcDir      = "src"
+)
+
+var (
+	ErrNoSource  = errors.New("documentation failure : no source file found")
+	errNoArchive = errors.New("documentation failure : no source archive")
+)
+
+// Base of the urls of the tarballs of the GitHub repositories, followed by "owner/repo/tar.gz/ref".
+var githubArchiveBase = "https://codeload.github.com/"
+
+// Doc comment of an exported package or symbol.
+type DocEntry struct {
+	Package string `json:"package"`
+	Symbol  string `json:"symbol,omitempty"` // like "Client.Do", empty for the package itself
+	Text    string `json:"text"`
+}
+
+// Parse the documentation of the package pkg (like "net/http") from its source files at conf.DocRef, read from
+// the local cache or downloaded from the Go source when they are not cached yet or older than conf.MaxAge.
+func LoadDocPackage(conf config.Config, pkg string) (*doc.Package, *token.FileSet, error) {
+	names, cacheDir, err := loadSourceFiles(conf, pkg, srcDir, isSourceFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkgFiles := map[string][]*ast.File{}
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, filepath.Join(cacheDir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		pkgFiles[file.Name.Name] = append(pkgFiles[file.Name.Name], file)
+	}
+
+	var files []*ast.File // the package with the most files, others are generators and the like
+	for name, candidates := range pkgFiles {
+		if len(candidates) > len(files) || (len(candidates) == len(files) && name != "main") {
+			files = candidates
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil, ErrNoSource
+	}
+
+	docPkg, err := doc.NewFromFiles(fset, files, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return docPkg, fset, nil
+}
+
+// Parse the examples of the package pkg (like "net/http") from its test files at conf.DocRef, read from the local cache
+// or downloaded from the Go source when they are not cached yet or older than conf.MaxAge (only the files with "example"
+// in their name).
+func LoadExamples(conf config.Config, pkg string) ([]*doc.Example, *token.FileSet, error) {
+	names, cacheDir, err := loadSourceFiles(conf, pkg, examplesDir, isExampleFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, filepath.Join(cacheDir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, file)
+	}
+	return doc.Examples(files...), fset, nil
+}
+
+// Return the doc comments of the packages by package : the ones already extracted are read from the cache
+// (docs.json in the cache directory of conf.DocRef, ignored when older than conf.MaxAge), the other ones are extracted
+// (see LoadDocPackage) then added to the cache. The packages without source file have no entry.
+func LoadDocs(conf config.Config, packages []string) (map[string][]DocEntry, error) {
+	byPackage := readDocs(conf)
+	var missing []string
+	for _, pkg := range packages {
+		if _, ok := byPackage[pkg]; !ok {
+			missing = append(missing, pkg)
+		}
+	}
+	if len(missing) > 1 {
+		prefetchSources(conf, missing)
+	}
+
+	changed, err := extractDocs(conf, byPackage, packages)
+	if changed { // keep the extracted ones even after a failure
+		if writeErr := writeDocs(conf, byPackage); err == nil {
+			err = writeErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return byPackage, nil
+}
+
+// Return the doc comments cached in docs.json, empty when it is missing, unreadable or older than conf.MaxAge.
+func readDocs(conf config.Config) map[string][]DocEntry {
+	filePath := filepath.Join(docConfig(conf).RepoPath, docsFile)
+	byPackage := map[string][]DocEntry{}
+	if info, err := os.Stat(filePath); err == nil && expired(conf, info.ModTime()) {
+		newVerboseLog(conf)("Extract again the doc comments cached in", filePath)
+	} else if data, err := os.ReadFile(filePath); err == nil {
+		if err = json.Unmarshal(data, &byPackage); err != nil {
+			newVerboseLog(conf)("Ignore", filePath, ":", err)
+			return map[string][]DocEntry{}
+		}
+	}
+	return byPackage
+}
+
+// Add the doc comments of the packages missing from byPackage, return true when any was added.
+func extractDocs(conf config.Config, byPackage map[string][]DocEntry, packages []string) (bool, error) {
+	changed := false
+	for _, pkg := range packages {
+		if _, ok := byPackage[pkg]; ok {
+			continue
+		}
+
+		docPkg, _, err := LoadDocPackage(conf, pkg)
+		switch {
+		case err == nil:
+			byPackage[pkg] = docEntries(docPkg)
+		case errors.Is(err, ErrNoSource):
+			byPackage[pkg] = []DocEntry{} // not retried
+		default:
+			return changed, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+func writeDocs(conf config.Config, byPackage map[string][]DocEntry) error {
+	data, err := json.Marshal(byPackage)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(docConfig(conf).RepoPath, docsFile), data)
+}
+
+// Cache the sources of the packages which are not cached yet (or older than conf.MaxAge) from a single archive of the
+// Go source tree at conf.DocRef (or at the ref of conf.SourceUrl when the tag does not exist yet), instead of one
+// listing request by package. Failures are only logged, the packages are then downloaded one by one.
+func prefetchSources(conf config.Config, packages []string) {
+	if conf.Offline {
+		return
+	}
+
+	verboseLog := newVerboseLog(conf)
+	refConf := docConfig(conf)
+	var uncached []string
+	for _, pkg := range packages {
+		names, modTime, err := listCachedFiles(filepath.Join(refConf.RepoPath, srcDir, filepath.FromSlash(pkg)), isSourceFile)
+		if err != nil || len(names) == 0 || expired(refConf, modTime) {
+			uncached = append(uncached, pkg)
+		}
+	}
+	if len(uncached) < 2 {
+		return // a single listing costs less than the archive
+	}
+
+	err := downloadSourceArchive(refConf, uncached)
+	if err != nil && refConf.SourceUrl != conf.SourceUrl {
+		verboseLog("Failed to download the sources at", conf.DocRef, ":", err)
+		err = downloadSourceArchive(conf, uncached)
+	}
+	if err != nil {
+		verboseLog("Failed to download the source archive, download the packages one by one :", err)
+	}
+}
+
+// Download the archive of the Go source tree at conf.SourceUrl and replace the cached sources of the packages
+// by its kept go files.
+func downloadSourceArchive(conf config.Config, packages []string) error {
+	archiveUrl, err := sourceArchiveUrl(conf.SourceUrl)
+	if err != nil {
+		return err
+	}
+
+	newVerboseLog(conf)("Download the sources of", len(packages), "packages from", archiveUrl)
+	data, err := DownloadWith(conf.Client, archiveUrl, conf.Progress)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(data)) == notFoundBody {
+		return fmt.Errorf("%w : %s", errNoArchive, archiveUrl)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]struct{}, len(packages))
+	for _, pkg := range packages {
+		wanted[pkg] = struct{}{}
+	}
+
+	files := map[string]map[string][]byte{} // by package then file name
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// names are like "go-go1.25.0/src/net/http/client.go"
+		_, name, _ := strings.Cut(header.Name, "/")
+		dir, fileName := path.Split(strings.TrimPrefix(name, srcDir+"/"))
+		pkg := strings.TrimSuffix(dir, "/")
+		if _, ok := wanted[pkg]; !ok || header.Typeflag != tar.TypeReg || !strings.HasPrefix(name, srcDir+"/") || !isSourceFile(fileName) {
+			continue
+		}
+
+		fileData, err := io.ReadAll(tarReader)
+		if err != nil {
+			return err
+		}
+		if files[pkg] == nil {
+			files[pkg] = map[string][]byte{}
+		}
+		files[pkg][fileName] = fileData
+	}
+
+	for pkg, pkgFiles := range files {
+		cacheDir := filepath.Join(conf.RepoPath, srcDir, filepath.FromSlash(pkg))
+		if err = os.RemoveAll(cacheDir); err != nil {
+			return err
+		}
+		if err = os.MkdirAll(cacheDir, 0755); err != nil {
+			return err
+		}
+		for fileName, fileData := range pkgFiles {
+			if err = os.WriteFile(filepath.Join(cacheDir, fileName), fileData, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Return the url of the gzipped tarball of the source tree, only GitHub sources (raw.githubusercontent.com) have one.
+func sourceArchiveUrl(sourceUrl string) (string, error) {
+	parsed, err := url.Parse(sourceUrl)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Host != githubRawHost {
+		return "", errNoArchive
+	}
+
+	// path is like "/golang/go/master"
+	owner, rest, _ := strings.Cut(strings.Trim(parsed.Path, "/"), "/")
+	repo, ref, _ := strings.Cut(rest, "/")
+	return githubArchiveBase + owner + "/" + repo + "/tar.gz/" + ref, nil
+}
+
+// Lazy loader of the first sentence of the doc comments, the ones of a package are only extracted
+// (downloading its sources when they are not cached) when one of its answers needs them.
+type summaryLoader struct {
+	conf      config.Config
+	mutex     sync.Mutex
+	docs      map[string][]DocEntry        // nil until docs.json is read
+	summaries map[string]map[string]string // by package then symbol name
+}
+
+func newSummaryLoader(conf config.Config) *summaryLoader {
+	return &summaryLoader{conf: conf, summaries: map[string]map[string]string{}}
+}
+
+// Return the first sentence of the doc comments of pkg and of its symbols, by symbol name. It is empty when they can
+// not be extracted, the failure is only logged (and the extraction not retried before the next loading).
+func (sl *summaryLoader) get(pkg string) map[string]string {
+	if sl == nil { // not loaded with Docs
+		return nil
+	}
+
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+	if summaries, ok := sl.summaries[pkg]; ok {
+		return summaries
+	}
+
+	if sl.docs == nil {
+		sl.docs = readDocs(sl.conf)
+	}
+	changed, err := extractDocs(sl.conf, sl.docs, []string{pkg})
+	if err != nil {
+		newVerboseLog(sl.conf)("Failed to extract the doc comments of", pkg, ":", err)
+	} else if changed {
+		if err = writeDocs(sl.conf, sl.docs); err != nil {
+			newVerboseLog(sl.conf)("Failed to cache the doc comments of", pkg, ":", err)
+		}
+	}
+
+	var docPkg doc.Package // only used for its Synopsis method
+	entries := sl.docs[pkg]
+	summaries := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if summary := docPkg.Synopsis(entry.Text); summary != "" {
+			summaries[entry.Symbol] = summary
+		}
+	}
+	sl.summaries[pkg] = summaries
+	return summaries
+}
+
+// Return the first sentence of the doc comment of the package or symbol, empty unless the database was loaded
+// with Docs (or without doc comment). The doc comments of the package are extracted on the first call.
+func (vd VersionDatas) Summary(pkg string, symbol string) string {
+	data, err := vd.lookup(pkg, symbol)
+	if err != nil {
+		return ""
+	}
+	return vd.summaries.get(strings.ToLower(pkg))[data.name]
+}
+
+// Return the doc comments of the package and of its exported symbols (fields excepted).
+func docEntries(docPkg *doc.Package) []DocEntry {
+	pkg := docPkg.ImportPath
+	res := []DocEntry{{Package: pkg, Text: docPkg.Doc}}
+	addValues := func(values []*doc.Value) {
+		for _, value := range values {
+			for _, name := range value.Names {
+				res = append(res, DocEntry{Package: pkg, Symbol: name, Text: value.Doc})
+			}
+		}
+	}
+	addFuncs := func(prefix string, funcs []*doc.Func) {
+		for _, fn := range funcs {
+			res = append(res, DocEntry{Package: pkg, Symbol: prefix + fn.Name, Text: fn.Doc})
+		}
+	}
+
+	addValues(docPkg.Consts)
+	addValues(docPkg.Vars)
+	addFuncs("", docPkg.Funcs)
+	for _, typ := range docPkg.Types {
+		res = append(res, DocEntry{Package: pkg, Symbol: typ.Name, Text: typ.Doc})
+		addValues(typ.Consts)
+		addValues(typ.Vars)
+		addFuncs("", typ.Funcs)
+		addFuncs(typ.Name+".", typ.Methods)
+	}
+	return res
+}
+
+// Return the configuration reading the package sources at conf.DocRef (cached in a directory of its own),
+// conf itself when it is empty or when the source does not follow master (like with a git source or another ref).
+func docConfig(conf config.Config) config.Config {
+	if conf.DocRef == "" || conf.GitUrl != "" {
+		return conf
+	}
+
+	refConf, err := conf.WithRef(conf.DocRef)
+	if err != nil {
+		return conf
+	}
+	return refConf
+}
+
+// Return the names of the kept go files of pkg at conf.DocRef (falling back on the ref of conf.SourceUrl, the tag
+// does not exist before the release) and the directory where they are cached (under dirName).
+func loadSourceFiles(conf config.Config, pkg string, dirName string, keep func(string) bool) ([]string, string, error) {
+	refConf := docConfig(conf)
+	names, cacheDir, err := cachedSourceFiles(refConf, pkg, dirName, keep)
+	if err != nil && refConf.SourceUrl != conf.SourceUrl {
+		newVerboseLog(conf)("Failed to read the sources of", pkg, "at", conf.DocRef, ":", err)
+		return cachedSourceFiles(conf, pkg, dirName, keep)
+	}
+	return names, cacheDir, err
+}
+
+// Return the names of the kept go files of pkg and the directory where they are cached, they are downloaded when
+// not cached (an empty directory is a valid cache for examples only) or older than conf.MaxAge (the cached files
+// are kept when the download fails).
+func cachedSourceFiles(conf config.Config, pkg string, dirName string, keep func(string) bool) ([]string, string, error) {
+	cacheDir := filepath.Join(conf.RepoPath, dirName, filepath.FromSlash(pkg))
+	names, modTime, err := listCachedFiles(cacheDir, keep)
+	if err != nil || (len(names) == 0 && dirName == srcDir) {
+		names, err = downloadSourceFiles(conf, pkg, cacheDir, keep)
+		return names, cacheDir, err
+	}
+	if !expired(conf, modTime) {
+		return names, cacheDir, nil
+	}
+
+	newVerboseLog(conf)("Revalidate the sources of", pkg, "cached on", modTime.Format(time.DateTime))
+	downloaded, err := downloadSourceFiles(conf, pkg, cacheDir, keep)
+	if err != nil {
+		newVerboseLog(conf)("Keep the cached sources of", pkg, ":", err)
+		return names, cacheDir, nil
+	}
+	return downloaded, cacheDir, nil
+}
+
+// Return true when a file cached at modTime is older than the max age of conf and can be downloaded again.
+func expired(conf config.Config, modTime time.Time) bool {
+	return !conf.Offline && conf.MaxAge > 0 && time.Since(modTime) > conf.MaxAge
+}
+
+// Return the names of the files kept in the cache directory and its modification time.
+func listCachedFiles(cacheDir string, keep func(string) bool) ([]string, time.Time, error) {
+	info, err := os.Stat(cacheDir)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if keep(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, info.ModTime(), nil
+}
+
+// Download the kept go files of pkg into the cache directory (created even when none is kept) and return their names.
+func downloadSourceFiles(conf config.Config, pkg string, cacheDir string, keep func(string) bool) ([]string, error) {
+	dir := path.Join(srcDir, pkg)
+	verboseLog := newVerboseLog(conf)
+	verboseLog("Download the sources of", pkg)
+
+	names, err := ListSourceFilesWith(conf.Client, conf.SourceUrl, dir, ".go")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, name := range names {
+		if !keep(name) {
+			continue
+		}
+
+		fileUrl, err := url.JoinPath(conf.SourceUrl, dir, name)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := DownloadWith(conf.Client, fileUrl, conf.Progress)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(string(data)) == notFoundBody { // listed but missing at the ref, not cached
+			verboseLog("Skip", fileUrl, ": not found")
+			continue
+		}
+
+		if err = os.WriteFile(filepath.Join(cacheDir, name), data, 0644); err != nil {
+			return nil, err
+		}
+		res = append(res, name)
+	}
+
+	if previous, _, err := listCachedFiles(cacheDir, keep); err == nil {
+		for _, name := range previous {
+			if !slices.Contains(res, name) { // removed from the Go source since the last download
+				os.Remove(filepath.Join(cacheDir, name))
+			}
+		}
+	}
+
+	now := time.Now() // overwritten files do not change the modification time of the directory
+	return res, os.Chtimes(cacheDir, now, now)
+}
+
+func isSourceFile(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+func isExampleFile(name string) bool {
+	return strings.HasSuffix(name, "_test.go") && strings.Contains(name, "example")
+}
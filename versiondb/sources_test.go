@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+func TestLoadDocsArchive(t *testing.T) {
+	SetDownloadRate(0)
+	archive := sourceTarball(t, map[string]string{
+		"go-master/src/strings/strings.go":      "// Package strings implements simple functions.\npackage strings\n\n// Cut slices s around the first instance of sep.\nfunc Cut(s, sep string) (before, after string, found bool) { return }\n",
+		"go-master/src/strings/strings_test.go": "package strings_test\n",
+		"go-master/src/fmt/print.go":            "// Package fmt implements formatted I/O.\npackage fmt\n\n// Println formats and writes to standard output.\nfunc Println(a ...any) (n int, err error) { return }\n",
+		"go-master/src/cmd/go/main.go":          "package main\n",
+	})
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/golang/go/tar.gz/master" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	previousBase := githubArchiveBase
+	githubArchiveBase = server.URL + "/"
+	defer func() { githubArchiveBase = previousBase }()
+
+	conf := config.Config{RepoPath: t.TempDir(), SourceUrl: "https://" + githubRawHost + "/golang/go/master", Client: server.Client()}
+	byPackage, err := LoadDocs(conf, []string{"strings", "fmt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := requests.Load(); count != 1 {
+		t.Errorf("%d requests, want a single archive download", count)
+	}
+	for pkg, symbol := range map[string]string{"strings": "Cut", "fmt": "Println"} {
+		entries := byPackage[pkg]
+		if len(entries) != 2 || entries[1].Symbol != symbol {
+			t.Errorf("doc entries of %s = %+v, want the package and %s", pkg, entries, symbol)
+		}
+	}
+}
+
+func sourceTarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
@@ -76,12 +76,33 @@ func sendChar(chars chan<- rune, line string) {
 	close(chars)
 }
 
+// Same as smartSplit, with the parsing failure returned as error.
+func safeSplit(line string) (firstPart []node, secondPart []node, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			parsingErr, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			err = parsingErr
+		}
+	}()
+
+	firstPart, secondPart = smartSplit(line)
+	return firstPart, secondPart, nil
+}
+
 func smartSplit(line string) ([]node, []node) {
 	chars := make(chan rune)
 	go sendChar(chars, line)
+	defer func() {
+		for range chars { // unblock sendChar after a parsing failure
+		}
+	}()
 
 	var buffer []rune
 	var splitted, splitted2 []node
+firstLoop:
 	for char := range chars {
 		switch char {
 		case '"', '\'':
@@ -99,7 +120,7 @@ func smartSplit(line string) ([]node, []node) {
 		case ')', ']', '}':
 			panic(errParsingUnexpectedClosing)
 		case ',':
-			break
+			break firstLoop
 		case ' ':
 			splitted, buffer = appendBuffer(splitted, buffer)
 		default:
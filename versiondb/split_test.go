@@ -0,0 +1,43 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSmartSplitMember(t *testing.T) {
+	firstPart, secondPart := smartSplit("type T struct, F int")
+	if got, want := castStrings(firstPart), []string{"type", "T", "struct"}; !slices.Equal(got, want) {
+		t.Errorf("first part is %q, want %q", got, want)
+	}
+	if got, want := castStrings(secondPart), []string{"F", "int"}; !slices.Equal(got, want) {
+		t.Errorf("second part is %q, want %q", got, want)
+	}
+}
+
+func castStrings(nodes []node) []string {
+	res := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		str, _ := n.cast()
+		res = append(res, str)
+	}
+	return res
+}
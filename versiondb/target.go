@@ -0,0 +1,233 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+//go:generate go run targets/gentinygo.go -o targets/tinygo.txt 0.30.0 0.31.0 0.32.0 0.33.0 0.34.0 0.35.0 0.36.0 0.37.0 0.38.0 0.39.0 0.40.1 0.41.0 0.42.0
+
+const (
+	targetReleasePrefix = "release "
+	targetsDir          = "targets"
+)
+
+var (
+	ErrNoTarget          = errors.New("target failure : no data found (see " + config.EnvTargetUrl + ")")
+	ErrTargetUnknown     = errors.New("no data about the package for the target")
+	ErrTargetUnsupported = errors.New("not supported by the latest release of the target")
+
+	errTargetLine = errors.New("target failure : expect a release line, or an entry prefixed by + or -")
+	errTargetName = errors.New("target failure : expect a name without path separator")
+
+	//go:embed targets/*.txt
+	embeddedTargets embed.FS
+)
+
+// Support of the standard library by a Go implementation other than gc (like TinyGo), by release.
+type TargetDatas struct {
+	Name     string
+	Releases []string          // in file order
+	maxGo    []string          // last Go version supported by each release, empty when unknown
+	states   []map[string]bool // support by lowercased index entry, for each release
+}
+
+// Read the data of the target name (like "tinygo") from the local cache (name.txt in the targets directory,
+// downloaded again from conf.TargetUrl when older than conf.MaxAge), or download them from conf.TargetUrl,
+// else use the copy shipped with gosince (targets/tinygo.txt, generated by targets/gentinygo.go). The file lists
+// the changes of each release, from the oldest, each release line can end with the last Go version it supports :
+//
+//	release 0.31.0 go1.22
+//	+ reflect
+//	- reflect Value.Grow
+//	release 0.32.0 go1.22
+//	+ reflect Value.Grow
+//
+// A package is supported once added (a package never added is unknown), its symbols too unless removed.
+func LoadTarget(conf config.Config, name string) (TargetDatas, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return TargetDatas{}, fmt.Errorf("%w : %q", errTargetName, name)
+	}
+
+	fileName := strings.ToLower(name) + ".txt"
+	filePath := filepath.Join(conf.RepoPath, targetsDir, fileName)
+	verboseLog := newVerboseLog(conf)
+	data, err := os.ReadFile(filePath)
+	if err == nil {
+		if info, err := os.Stat(filePath); err == nil && conf.TargetUrl != "" && expired(conf, info.ModTime()) {
+			verboseLog("Revalidate", filePath)
+			if downloaded, err := downloadTarget(conf, name, fileName, filePath); err != nil {
+				verboseLog("Keep the cached", filePath, ":", err)
+			} else if downloaded != nil {
+				data = downloaded
+			}
+		}
+		return parseTarget(name, data)
+	}
+
+	verboseLog("Failed to read", filePath, ":", err)
+	if conf.TargetUrl == "" || conf.Offline {
+		return loadEmbeddedTarget(name, fileName)
+	}
+	if data, err = downloadTarget(conf, name, fileName, filePath); err != nil {
+		return TargetDatas{}, err
+	}
+	if data == nil {
+		return loadEmbeddedTarget(name, fileName)
+	}
+	return parseTarget(name, data)
+}
+
+// Download the data of the target from conf.TargetUrl and cache them, nil when the file is not found there.
+func downloadTarget(conf config.Config, name string, fileName string, filePath string) ([]byte, error) {
+	fileUrl, err := url.JoinPath(conf.TargetUrl, fileName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := DownloadWith(conf.Client, fileUrl, conf.Progress)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(data)) == notFoundBody {
+		return nil, nil
+	}
+	if _, err = parseTarget(name, data); err != nil {
+		return nil, fmt.Errorf("%s : %w", fileUrl, err)
+	}
+	return data, writeFile(filePath, data)
+}
+
+func loadEmbeddedTarget(name string, fileName string) (TargetDatas, error) {
+	data, err := embeddedTargets.ReadFile(targetsDir + "/" + fileName)
+	if err != nil {
+		return TargetDatas{}, ErrNoTarget
+	}
+	return parseTarget(name, data)
+}
+
+// Return the latest release of the target, "" when there is none.
+func (td TargetDatas) Latest() string {
+	if len(td.Releases) == 0 {
+		return ""
+	}
+	return td.Releases[len(td.Releases)-1]
+}
+
+// Return the release since which the package or symbol (like "Value.Grow") added in the Go version addedIn
+// (ignored when empty) is supported without interruption until the latest release (case is ignored).
+// A package without data in the latest release gives ErrTargetUnknown rather than ErrTargetUnsupported.
+func (td TargetDatas) Since(pkg string, symbol string, addedIn string) (string, error) {
+	pkg = strings.ToLower(pkg)
+	if len(td.states) == 0 {
+		return "", ErrTargetUnknown
+	}
+	if _, ok := td.states[len(td.states)-1][pkg]; !ok {
+		return "", ErrTargetUnknown
+	}
+
+	since := ""
+	for index := len(td.states) - 1; index >= 0; index-- {
+		if !td.supported(index, pkg, symbol, addedIn) {
+			break
+		}
+		since = td.Releases[index]
+	}
+	if since == "" {
+		return "", ErrTargetUnsupported
+	}
+	return since, nil
+}
+
+// A symbol is supported when its package is, its Go version is not newer than the release one,
+// and neither it nor its parent type (for a method or field) was removed.
+func (td TargetDatas) supported(index int, pkg string, symbol string, addedIn string) bool {
+	state := td.states[index]
+	if !state[pkg] {
+		return false
+	}
+	if maxGo := td.maxGo[index]; maxGo != "" && addedIn != "" && CompareVersion(addedIn, maxGo) > 0 {
+		return false
+	}
+
+	key := pkg + " "
+	for part := range strings.SplitSeq(strings.ToLower(symbol), ".") {
+		if part == "" {
+			continue
+		}
+
+		key += part
+		if supported, ok := state[key]; ok && !supported {
+			return false
+		}
+		key += "."
+	}
+	return true
+}
+
+func parseTarget(name string, data []byte) (TargetDatas, error) {
+	td := TargetDatas{Name: name}
+	var state map[string]bool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if release, ok := strings.CutPrefix(line, targetReleasePrefix); ok {
+			if state = maps.Clone(state); state == nil {
+				state = map[string]bool{}
+			}
+			version, maxGo, _ := strings.Cut(strings.TrimSpace(release), " ")
+			td.Releases = append(td.Releases, version)
+			td.maxGo = append(td.maxGo, NormalizeVersion(strings.TrimSpace(maxGo)))
+			td.states = append(td.states, state)
+			continue
+		}
+
+		if state == nil || (line[0] != '+' && line[0] != '-') {
+			return TargetDatas{}, fmt.Errorf("%s:%d: %w", name, lineNumber, errTargetLine)
+		}
+		pkg, symbol, _ := strings.Cut(strings.TrimSpace(line[1:]), " ")
+		key := strings.ToLower(pkg)
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			key += " " + strings.ToLower(symbol)
+		}
+		state[key] = line[0] == '+'
+	}
+	if err := scanner.Err(); err != nil {
+		return TargetDatas{}, err
+	}
+	if len(td.Releases) == 0 {
+		return TargetDatas{}, ErrNoTarget
+	}
+	return td, nil
+}
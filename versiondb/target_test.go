@@ -0,0 +1,131 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dvaumoron/gosince/config"
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+func TestLoadTarget(t *testing.T) {
+	conf := config.Config{RepoPath: t.TempDir(), Offline: true}
+
+	// the shipped data
+	td, err := versiondb.LoadTarget(conf, "TinyGo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		pkg     string
+		symbol  string
+		addedIn string
+		want    string
+		err     error
+	}{
+		{pkg: "fmt", symbol: "Println", addedIn: "go1", want: "0.30.0"},
+		{pkg: "strings", symbol: "Cut", addedIn: "go1.18", want: "0.30.0"},
+		{pkg: "unique", symbol: "Make", addedIn: "go1.23", want: "0.33.0"},
+		{pkg: "reflect", symbol: "Value.Seq", addedIn: "go1.23", want: "0.37.0"}, // declared by the reflect of TinyGo since 0.37.0
+		{pkg: "os", symbol: "CopyFS", addedIn: "go1.23", err: versiondb.ErrTargetUnsupported},
+		{pkg: "go/parser", symbol: "ParseFile", addedIn: "go1", err: versiondb.ErrTargetUnknown},
+	} {
+		since, err := td.Since(test.pkg, test.symbol, test.addedIn)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("Since(%q, %q, %q) = %q, %v, want %v", test.pkg, test.symbol, test.addedIn, since, err, test.err)
+			}
+		} else if since != test.want || err != nil {
+			t.Errorf("Since(%q, %q, %q) = %q, %v, want %q", test.pkg, test.symbol, test.addedIn, since, err, test.want)
+		}
+	}
+
+	// the cached file comes first
+	data := "release 1.0 go1.20\n+ reflect\n- reflect Value.Grow\nrelease 1.1 go1.21\n+ reflect Value.Grow\n"
+	if err = os.MkdirAll(filepath.Join(conf.RepoPath, "targets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(conf.RepoPath, "targets", "tinygo.txt"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if td, err = versiondb.LoadTarget(conf, "tinygo"); err != nil || td.Latest() != "1.1" {
+		t.Fatalf("LoadTarget() = %v, %v, want the cached file", td.Releases, err)
+	}
+	if since, err := td.Since("reflect", "Value.Grow", "go1.20"); since != "1.1" || err != nil {
+		t.Errorf(`Since("reflect", "Value.Grow", "go1.20") = %q, %v, want "1.1"`, since, err)
+	}
+	if since, err := td.Since("reflect", "Value.Clear", "go1.21"); since != "1.1" || err != nil {
+		t.Errorf(`Since("reflect", "Value.Clear", "go1.21") = %q, %v, want "1.1" (newer than the Go of 1.0)`, since, err)
+	}
+
+	for _, name := range []string{"", "../tinygo", `targets\tinygo`} {
+		if _, err = versiondb.LoadTarget(conf, name); err == nil {
+			t.Errorf("LoadTarget(%q) succeeded, want a failure", name)
+		}
+	}
+	if _, err = versiondb.LoadTarget(conf, "gccgo"); !errors.Is(err, versiondb.ErrNoTarget) {
+		t.Errorf(`LoadTarget("gccgo") = %v, want %v`, err, versiondb.ErrNoTarget)
+	}
+}
+
+func TestLoadTargetRevalidate(t *testing.T) {
+	versiondb.SetDownloadRate(0)
+	data := "release 1.0 go1.20\n+ reflect\nrelease 1.1 go1.21\n+ unique\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tinygo.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(data))
+	}))
+	defer server.Close()
+
+	conf := config.Config{RepoPath: t.TempDir(), TargetUrl: server.URL, MaxAge: time.Hour}
+	filePath := filepath.Join(conf.RepoPath, "targets", "tinygo.txt")
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte("release 1.0 go1.20\n+ reflect\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a fresh cache is kept
+	if td, err := versiondb.LoadTarget(conf, "tinygo"); err != nil || td.Latest() != "1.0" {
+		t.Fatalf("LoadTarget() = %v, %v, want the cached file", td.Releases, err)
+	}
+
+	// an expired one is downloaded again
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filePath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if td, err := versiondb.LoadTarget(conf, "tinygo"); err != nil || td.Latest() != "1.1" {
+		t.Fatalf("LoadTarget() = %v, %v, want the downloaded file", td.Releases, err)
+	}
+	if cached, err := os.ReadFile(filePath); err != nil || string(cached) != data {
+		t.Errorf("cached file = %q, %v, want the downloaded one", cached, err)
+	}
+}
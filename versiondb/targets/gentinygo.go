@@ -0,0 +1,430 @@
+//go:build ignore
+
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Generate tinygo.txt from the module zips of TinyGo releases (from proxy.golang.org) and the api files of Go :
+//
+//	go run gentinygo.go -o tinygo.txt 0.30.0 0.31.0
+//
+// For each release, a package is supported when TinyGo builds it on linux : its standard library tests pass
+// (TEST_PACKAGES_FAST, TEST_PACKAGES_SLOW and TEST_PACKAGES_LINUX in the makefiles), a program of testdata imports
+// it, or TinyGo replaces it (a directory of src, like reflect or os). The symbols of the Go api (up to the last
+// Go version supported by the release) not declared by a replacing package are removed.
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+const proxyBase = "https://proxy.golang.org/github.com/tinygo-org/tinygo/@v/v"
+
+var (
+	errNoMaxGo   = errors.New("no maximum go version found in builder/config.go")
+	errNoRelease = errors.New("expect the TinyGo releases as arguments (like 0.30.0)")
+
+	maxGoRegexp      = regexp.MustCompile(`(?:minor > |minorMax = )(\d+)`)
+	testPackagesVars = []string{"TEST_PACKAGES_FAST", "TEST_PACKAGES_SLOW", "TEST_PACKAGES_LINUX"}
+	symbolKinds      = []string{versiondb.KindConst, versiondb.KindFunc, versiondb.KindMethod, versiondb.KindType, versiondb.KindVar}
+)
+
+// Support of a TinyGo release.
+type release struct {
+	version  string
+	maxGo    string          // like "go1.21"
+	packages map[string]bool // supported packages
+	missing  map[string]bool // "pkg Symbol" of the Go api not declared by TinyGo
+}
+
+func main() {
+	output := flag.String("o", "tinygo.txt", "Output file")
+	apiDir := flag.String("api", filepath.Join(build.Default.GOROOT, "api"), "Directory of the api files of Go")
+	flag.Parse()
+
+	if err := run(*output, *apiDir, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(output string, apiDir string, versions []string) error {
+	vd, err := versiondb.LoadFS(os.DirFS(apiDir))
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		return errNoRelease
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`# TinyGo support of the standard library on linux, for the releases ` + versions[0] + ` to ` + versions[len(versions)-1] + `.
+#
+# Generated by gentinygo.go (go generate in versiondb) from the module zips of github.com/tinygo-org/tinygo
+# on proxy.golang.org : a package is supported when its standard library tests pass with TinyGo (the test
+# package lists of the makefiles), when a program of testdata imports it or when TinyGo replaces it (a
+# directory of src), and the symbols of the Go api not declared by a replacing package are removed.
+# Packages without such evidence are not listed (no data, rather than unsupported). Each release line
+# ends with the last Go version supported by the release.
+`)
+
+	previous := release{packages: map[string]bool{}, missing: map[string]bool{}}
+	for _, version := range versions {
+		current, err := loadRelease(vd, version)
+		if err != nil {
+			return fmt.Errorf("%s : %w", version, err)
+		}
+		for pkg := range previous.packages { // once built, a package is kept
+			current.packages[pkg] = true
+		}
+
+		fmt.Fprintln(&buffer, "release", current.version, current.maxGo)
+		for _, pkg := range slices.Sorted(maps.Keys(current.packages)) {
+			if !previous.packages[pkg] {
+				fmt.Fprintln(&buffer, "+", pkg)
+			}
+		}
+		for _, entry := range slices.Sorted(maps.Keys(previous.missing)) {
+			if !current.missing[entry] {
+				fmt.Fprintln(&buffer, "+", entry)
+			}
+		}
+		for _, entry := range slices.Sorted(maps.Keys(current.missing)) {
+			if !previous.missing[entry] {
+				fmt.Fprintln(&buffer, "-", entry)
+			}
+		}
+		previous = current
+	}
+	return os.WriteFile(output, buffer.Bytes(), 0644)
+}
+
+func loadRelease(vd versiondb.VersionDatas, version string) (release, error) {
+	dir, err := extractModule(version)
+	if err != nil {
+		return release{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	configData, err := os.ReadFile(filepath.Join(dir, "builder", "config.go"))
+	if err != nil {
+		return release{}, err
+	}
+	match := maxGoRegexp.FindSubmatch(configData)
+	if match == nil {
+		return release{}, errNoMaxGo
+	}
+	maxMinor, _ := strconv.Atoi(string(match[1]))
+
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH, ctx.CgoEnabled = "linux", "amd64", true
+	ctx.BuildTags = []string{"tinygo", "gc.conservative", "scheduler.tasks", "osusergo", "math_big_pure_go"}
+	ctx.ReleaseTags = nil
+	for minor := 1; minor <= maxMinor; minor++ {
+		ctx.ReleaseTags = append(ctx.ReleaseTags, "go1."+strconv.Itoa(minor))
+	}
+
+	current := release{version: version, maxGo: "go1." + strconv.Itoa(maxMinor), packages: map[string]bool{}, missing: map[string]bool{}}
+	stdPackages := map[string]bool{}
+	for _, pkg := range vd.Packages() {
+		stdPackages[pkg] = !strings.Contains(pkg, "internal")
+	}
+
+	makefiles, err := filepath.Glob(filepath.Join(dir, "*akefile"))
+	if err != nil {
+		return release{}, err
+	}
+	included, err := filepath.Glob(filepath.Join(dir, "make", "*.mk"))
+	if err != nil {
+		return release{}, err
+	}
+
+	var testPackages []string
+	for _, makefile := range append(makefiles, included...) {
+		packages, err := readTestPackages(makefile)
+		if err != nil {
+			return release{}, err
+		}
+		testPackages = append(testPackages, packages...)
+	}
+	imports, err := readImports(ctx, filepath.Join(dir, "testdata"))
+	if err != nil {
+		return release{}, err
+	}
+	for _, pkg := range append(testPackages, imports...) {
+		if stdPackages[pkg] {
+			current.packages[pkg] = true
+		}
+	}
+
+	srcDir := filepath.Join(dir, "src")
+	return current, filepath.WalkDir(srcDir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return err
+		}
+
+		pkg := filepath.ToSlash(strings.TrimPrefix(filePath, srcDir+string(filepath.Separator)))
+		if pkg == "syscall" || strings.HasPrefix(pkg, "syscall/") || !stdPackages[pkg] {
+			return nil // the syscall package of Go is used on linux
+		}
+
+		declared, err := readDeclarations(ctx, filePath)
+		if err != nil || declared == nil {
+			return err
+		}
+		current.packages[pkg] = true
+
+		return vd.Walk(versiondb.Filter{PackagePrefix: pkg, To: current.maxGo, Kinds: symbolKinds}, func(entry versiondb.Entry) bool {
+			if entry.Package == pkg && onLinux(entry.Platforms) && !declared.has(entry.Name) {
+				current.missing[pkg+" "+entry.Name] = true
+			}
+			return true
+		})
+	})
+}
+
+// Extract the module zip of the release in a temporary directory and return the module root.
+func extractModule(version string) (string, error) {
+	data, err := versiondb.DownloadWith(nil, proxyBase+version+".zip", nil)
+	if err != nil {
+		return "", err
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "tinygo")
+	if err != nil {
+		return "", err
+	}
+	prefix := "github.com/tinygo-org/tinygo@v" + version + "/"
+	for _, file := range reader.File {
+		name, ok := strings.CutPrefix(file.Name, prefix)
+		if !ok || strings.HasSuffix(name, "/") || !(strings.HasPrefix(name, "src/") || strings.HasPrefix(name, "testdata/") || isMakefile(name) || name == "builder/config.go") {
+			continue
+		}
+		if err = extractFile(file, filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// The makefile was renamed GNUmakefile (0.31.0), then split into included files of the make directory.
+func isMakefile(name string) bool {
+	return name == "Makefile" || name == "GNUmakefile" || (strings.HasPrefix(name, "make/") && strings.HasSuffix(name, ".mk"))
+}
+
+func extractFile(file *zip.File, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// Return the packages of the variables listing the standard library tests passing on linux.
+func readTestPackages(makefilePath string) ([]string, error) {
+	file, err := os.Open(makefilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var packages []string
+	inList := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(strings.TrimSuffix(line, `\`))
+		if !inList && len(fields) > 1 && slices.Contains(testPackagesVars, fields[0]) && strings.HasSuffix(fields[1], "=") {
+			fields = fields[2:]
+			inList = true
+		} else if !inList {
+			continue
+		}
+
+		for _, field := range fields {
+			if !strings.HasPrefix(field, "$") {
+				packages = append(packages, field)
+			}
+		}
+		inList = strings.HasSuffix(line, `\`)
+	}
+	return packages, scanner.Err()
+}
+
+// Return the imports of the go files built on linux in the directory tree of root.
+func readImports(ctx build.Context, root string) ([]string, error) {
+	var imports []string
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(filePath, ".go") {
+			return err
+		}
+		if match, err := ctx.MatchFile(filepath.Dir(filePath), entry.Name()); err != nil || !match {
+			return nil // not built on linux, or not even parsable
+		}
+
+		file, err := parser.ParseFile(fset, filePath, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		for _, spec := range file.Imports {
+			if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports = append(imports, importPath)
+			}
+		}
+		return nil
+	})
+	return imports, err
+}
+
+// Exported names of a package, methods as "Type.Method".
+type declarations struct {
+	names    map[string]bool
+	embedded map[string]bool // types with embedded fields or interfaces, whose promoted methods are not declared
+}
+
+// A method of a type with embedded members is supposed declared (it can be promoted).
+func (declared declarations) has(name string) bool {
+	typeName, _, isMember := strings.Cut(name, ".")
+	return declared.names[name] || (isMember && declared.embedded[typeName])
+}
+
+// Return the declarations of the go files of dir built on linux, nil when there is none.
+func readDeclarations(ctx build.Context, dir string) (*declarations, error) {
+	pkg, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		var noGo *build.NoGoError
+		if errors.As(err, &noGo) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	declared := &declarations{names: map[string]bool{}, embedded: map[string]bool{}}
+	fset := token.NewFileSet()
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			declared.add(decl)
+		}
+	}
+	return declared, nil
+}
+
+func (declared declarations) add(decl ast.Decl) {
+	switch typed := decl.(type) {
+	case *ast.FuncDecl:
+		if typed.Recv == nil {
+			declared.names[typed.Name.Name] = true
+		} else if typeName := receiverName(typed.Recv.List[0].Type); typeName != "" {
+			declared.names[typeName+"."+typed.Name.Name] = true
+		}
+	case *ast.GenDecl:
+		for _, spec := range typed.Specs {
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				for _, name := range spec.Names {
+					declared.names[name.Name] = true
+				}
+			case *ast.TypeSpec:
+				typeName := spec.Name.Name
+				declared.names[typeName] = true
+				var members []*ast.Field
+				switch typeExpr := spec.Type.(type) {
+				case *ast.StructType:
+					members = typeExpr.Fields.List
+				case *ast.InterfaceType:
+					members = typeExpr.Methods.List
+				default:
+					declared.embedded[typeName] = spec.Assign.IsValid() // an alias has the members of another type
+				}
+				for _, member := range members {
+					if len(member.Names) == 0 {
+						declared.embedded[typeName] = true
+					}
+					for _, name := range member.Names {
+						declared.names[typeName+"."+name.Name] = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// Return the type name of a method receiver (like "Value" for "*Value[T]").
+func receiverName(expr ast.Expr) string {
+	for {
+		switch typed := expr.(type) {
+		case *ast.StarExpr:
+			expr = typed.X
+		case *ast.IndexExpr:
+			expr = typed.X
+		case *ast.IndexListExpr:
+			expr = typed.X
+		case *ast.ParenExpr:
+			expr = typed.X
+		case *ast.Ident:
+			return typed.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// The symbol is available on linux/amd64 (nil platforms mean everywhere).
+func onLinux(platforms []string) bool {
+	return platforms == nil || slices.ContainsFunc(platforms, func(platform string) bool {
+		return strings.HasPrefix(platform, "linux-amd64")
+	})
+}
@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	errDuplicateLine = errors.New("validation failure : duplicate declaration")
+	errMissingIssue  = errors.New("validation failure : missing proposal issue (like #12345)")
+
+	issueFileRegexp = regexp.MustCompile(`^[0-9]+\.txt$`)
+	issueRegexp     = regexp.MustCompile(`#[0-9]+`)
+)
+
+// Return the problems of the api file fileName : malformed and duplicate lines,
+// and lines without proposal issue in files named after an issue (like api/next/12345.txt).
+func Validate(fileName string, data []byte) ([]Diagnostic, error) {
+	requireIssue := issueFileRegexp.MatchString(filepath.Base(fileName))
+	seen := map[string]struct{}{}
+	var res []Diagnostic
+	lineNumber := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		parsed, ok, err := parseLine(line)
+		if err != nil {
+			res = append(res, Diagnostic{File: fileName, Line: lineNumber, Text: line, Err: err})
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		key := parsed.platform + " " + parsed.pkg + " " + parsed.definition
+		if parsed.deprecated {
+			key += " //deprecated"
+		}
+		if _, duplicate := seen[key]; duplicate {
+			res = append(res, Diagnostic{File: fileName, Line: lineNumber, Text: line, Err: errDuplicateLine})
+		} else {
+			seen[key] = struct{}{}
+		}
+
+		if requireIssue && !issueRegexp.MatchString(line) {
+			res = append(res, Diagnostic{File: fileName, Line: lineNumber, Text: line, Err: errMissingIssue})
+		}
+	}
+	return res, scanner.Err()
+}
@@ -0,0 +1,160 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dvaumoron/gosince/config"
+)
+
+const checksumsFile = "api.sha256" // in the format of sha256sum
+
+var (
+	errCacheChecksum  = errors.New("cache failure : content differs from the recorded checksum")
+	errCacheMalformed = errors.New("cache failure : malformed api line")
+	errCacheTruncated = errors.New("cache failure : truncated file")
+)
+
+// Problem found in a cached api file.
+type CacheProblem struct {
+	File     string // like "go1.21.txt"
+	Err      error
+	Repaired bool
+}
+
+// Check each cached api file against its recorded checksum (when there is one) and check that all its lines parse,
+// with repair the faulty files are downloaded again. Return the number of checked files and the problems found.
+func VerifyCache(conf config.Config, repair bool) (int, []CacheProblem, error) {
+	dl, err := newDataLoader(config.Config{Archive: conf.Archive, RepoPath: conf.RepoPath, SourceUrl: conf.SourceUrl, Client: conf.Client, Logger: conf.Logger, Verbose: conf.Verbose})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	cached, err := dl.cachedFiles()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var sums map[string]string
+	if dl.archive == nil {
+		if sums, err = ReadSnapshot(dl.sumsPath); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return 0, nil, err
+			}
+			err = nil // nothing recorded yet
+		}
+	}
+
+	var problems []CacheProblem
+	if dl.archive != nil {
+		for _, fileName := range dl.archive.corrupted {
+			problems = append(problems, CacheProblem{File: fileName, Err: errCacheChecksum})
+		}
+	}
+
+	checked := len(cached) + len(problems)
+	for _, fileName := range slices.Sorted(maps.Keys(cached)) {
+		data := cached[fileName]
+		if sum, ok := sums[fileName]; ok && sum != hashData(data) {
+			problems = append(problems, CacheProblem{File: fileName, Err: errCacheChecksum})
+		} else if err := checkApiFile(data); err != nil {
+			problems = append(problems, CacheProblem{File: fileName, Err: err})
+		}
+	}
+
+	if repair {
+		for index, problem := range problems {
+			if err = dl.repair(problem.File); err != nil {
+				problems[index].Err = fmt.Errorf("%w (repair failure : %w)", problem.Err, err)
+				continue
+			}
+			problems[index].Repaired = true
+		}
+		err = dl.archive.flush()
+	}
+	return checked, problems, err
+}
+
+// Return the cached api files by file name.
+func (dl dataLoader) cachedFiles() (map[string][]byte, error) {
+	if dl.archive != nil {
+		return dl.archive.files, nil
+	}
+
+	filePaths, err := filepath.Glob(dl.repobase + "*txt") // go1.txt and go1.N.txt
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]byte, len(filePaths))
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		res[filepath.Base(filePath)] = data
+	}
+	return res, nil
+}
+
+// Download the api file again, replacing the cached one.
+func (dl dataLoader) repair(fileName string) error {
+	_, err := dl.download(strings.TrimPrefix(fileName, go1Dot))
+	return err
+}
+
+// Append the checksum of the api file fileName to the recorded ones.
+func (dl dataLoader) recordChecksum(fileName string) error {
+	file, err := os.OpenFile(dl.sumsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(formatSnapshot(map[string]string{fileName: dl.hashes[fileName]}))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Check that data ends with a new line and that all its lines parse.
+func checkApiFile(data []byte) error {
+	if len(data) != 0 && data[len(data)-1] != '\n' {
+		return errCacheTruncated
+	}
+
+	lineNumber := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNumber++
+		if _, _, err := parseLine(scanner.Text()); err != nil {
+			return fmt.Errorf("line %d : %w", lineNumber, errCacheMalformed)
+		}
+	}
+	return scanner.Err()
+}
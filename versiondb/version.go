@@ -0,0 +1,116 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Return the minor number of a go1 release, accepted forms are "go1", "go1.21", "1.21" and "go1.21.5" (patch is ignored).
+// Return -1 when the version is not recognized.
+func MinorVersion(version string) int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "go")
+	if version == "1" {
+		return 0
+	}
+
+	minor, ok := strings.CutPrefix(version, "1.")
+	if !ok {
+		return -1
+	}
+
+	if index := strings.IndexAny(minor, ".rcbeta"); index != -1 {
+		minor = minor[:index]
+	}
+
+	res, err := strconv.Atoi(minor)
+	if err != nil || res < 0 {
+		return -1
+	}
+	return res
+}
+
+// Return the version in the form used by api files ("go1" or "go1.N"), or "" when the version is not recognized.
+func NormalizeVersion(version string) string {
+	switch minor := MinorVersion(version); minor {
+	case -1:
+		return ""
+	case 0:
+		return "go1"
+	default:
+		return go1Dot + strconv.Itoa(minor)
+	}
+}
+
+// Compare two go1 releases, the result is negative when a is older than b, positive when newer and 0 when equal.
+// An empty or unknown version is older than any known one.
+func CompareVersion(a string, b string) int {
+	return MinorVersion(a) - MinorVersion(b)
+}
+
+// Return the most recent loaded version.
+func (vd VersionDatas) LatestVersion() string {
+	if len(vd.versions) == 0 {
+		return ""
+	}
+	return vd.versions[len(vd.versions)-1]
+}
+
+// Return the most recent published release, the latest loaded version or the previous one when the latest
+// is not released yet (its api file lands on master at the API freeze).
+func (vd VersionDatas) LatestRelease() string {
+	if vd.unreleased {
+		return vd.PreviousVersion(vd.LatestVersion())
+	}
+	return vd.LatestVersion()
+}
+
+// Return the Go source tag of the latest published release (like "go1.22.0", or "go1.20" before go1.21),
+// "" when no version is loaded.
+func (vd VersionDatas) ReleaseTag() string {
+	if latest := vd.LatestRelease(); latest != "" {
+		return releaseTag(latest)
+	}
+	return ""
+}
+
+// Return the loaded versions, in release order.
+func (vd VersionDatas) Versions() []string {
+	return slices.Clone(vd.versions)
+}
+
+// Return the loaded version released just before version, "" for go1 or an unknown version.
+func (vd VersionDatas) PreviousVersion(version string) string {
+	index := slices.Index(vd.versions, NormalizeVersion(version))
+	if index < 1 {
+		return ""
+	}
+	return vd.versions[index-1]
+}
+
+// Return the published versions still in the support window (the most recent ones), in release order.
+func (vd VersionDatas) SupportedVersions() []string {
+	released := vd.versions
+	if vd.unreleased && len(released) != 0 {
+		released = released[:len(released)-1]
+	}
+	return released[max(0, len(released)-supportedCount):]
+}
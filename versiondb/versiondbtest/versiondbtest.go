@@ -0,0 +1,142 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package versiondbtest provides a small canned database and a fake Go source server,
+// for hermetic tests of the tools embedding versiondb.
+package versiondbtest
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dvaumoron/gosince/versiondb"
+)
+
+// Last release of the canned database.
+const Latest = "go1.22"
+
+const notFoundBody = "404: Not Found" // answer of raw.githubusercontent.com for a missing file
+
+// Content of the canned api files by release, the releases without entry have empty api files.
+var apiFiles = map[string]string{
+	"go1": `pkg bytes, method (*Buffer) String() string
+pkg bytes, type Buffer struct
+pkg fmt, func Println(...interface{}) (int, error)
+pkg fmt, func Sprintf(string, ...interface{}) string
+pkg io/ioutil, func ReadAll(io.Reader) ([]uint8, error)
+pkg os, var Args []string
+pkg strings, func Contains(string, string) bool
+`,
+	"go1.7": `pkg io, const SeekStart = 0
+pkg io, const SeekStart ideal-int
+`,
+	"go1.10": `pkg strings, method (*Builder) String() string
+pkg strings, method (*Builder) WriteString(string) (int, error)
+pkg strings, type Builder struct
+`,
+	"go1.13": `pkg net/http, type Transport struct, ForceAttemptHTTP2 bool
+pkg syscall (linux-amd64), const O_DIRECT = 16384
+pkg syscall (linux-amd64), const O_DIRECT ideal-int
+`,
+	"go1.16": `pkg io, func ReadAll(io.Reader) ([]uint8, error)
+`,
+	"go1.18": `pkg strings, func Cut(string, string) (string, string, bool)
+`,
+	"go1.19": `pkg io/ioutil, func ReadAll //deprecated #42026
+`,
+	"go1.20": `pkg strings, func CutPrefix(string, string) (string, bool)
+`,
+	"go1.21": `pkg slices, func Sort[$0 interface{ ~[]$1 }, $1 cmp.Ordered]($0)
+`,
+	"go1.22": `pkg slices, func Concat[$0 interface{ ~[]$1 }, $1 interface{}](...$0) $0
+`,
+}
+
+// Return the canned api files (from go1.txt to go1.22.txt), by file name.
+//
+// They declare, among others, fmt.Println (go1), strings.Builder (go1.10), net/http.Transport.ForceAttemptHTTP2
+// (a struct field, go1.13), syscall.O_DIRECT (linux/amd64 only, go1.13), io/ioutil.ReadAll (deprecated in go1.19)
+// and slices.Sort (generic, go1.21).
+func Files() fstest.MapFS {
+	files := fstest.MapFS{}
+	for minor := 0; ; minor++ {
+		version := "go1." + strconv.Itoa(minor)
+		if minor == 0 {
+			version = "go1"
+		}
+		files[version+".txt"] = &fstest.MapFile{Data: []byte(apiFiles[version])}
+		if version == Latest {
+			return files
+		}
+	}
+}
+
+// Return the canned database, t fails when it can not be loaded.
+func New(t testing.TB) versiondb.VersionDatas {
+	t.Helper()
+
+	vd, err := versiondb.LoadFS(Files())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vd
+}
+
+// Start a server of the canned api files, answering like raw.githubusercontent.com (a missing file gets
+// a "404: Not Found" body), with an HTML listing of its api directory. Its URL is a source url for
+// versiondb.WithSource (or config.Config.SourceUrl), it is closed at the end of the test.
+// The downloads are rate limited, versiondb.SetDownloadRate(0) speeds up the tests.
+func NewServer(t testing.TB) *httptest.Server {
+	t.Helper()
+
+	files := Files()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dir, name := path.Split(r.URL.Path)
+		if dir != "/api/" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(notFoundBody))
+			return
+		}
+
+		if name == "" {
+			var listing strings.Builder
+			for _, fileName := range slices.Sorted(maps.Keys(files)) {
+				listing.WriteString(`<a href="` + fileName + `">` + fileName + "</a>\n")
+			}
+			w.Write([]byte(listing.String()))
+			return
+		}
+
+		file, ok := files[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(notFoundBody))
+			return
+		}
+		w.Write(file.Data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
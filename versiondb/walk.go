@@ -0,0 +1,95 @@
+/*
+ *
+ * Copyright 2024 gosince authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versiondb
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Restriction of Walk, its zero value keeps everything.
+type Filter struct {
+	PackagePrefix string   // keep the package with this path and the ones under it (like "crypto" or "crypto/")
+	From          string   // keep the symbols added in this version or later
+	To            string   // keep the symbols added in this version or before
+	Kinds         []string // keep the symbols of these kinds (see the Kind constants)
+	Deprecated    bool     // keep only the deprecated symbols
+}
+
+// Symbol of a package, as visited by Walk.
+type Entry struct {
+	Package string
+	SymbolInfo
+}
+
+// Call fn on the entries (the packages themselves and their symbols) kept by filter, by package path
+// then name, until fn returns false. Packages outside PackagePrefix are skipped without visiting their symbols.
+func (vd VersionDatas) Walk(filter Filter, fn func(Entry) bool) error {
+	from, to := filter.From, filter.To
+	if from != "" {
+		if from = NormalizeVersion(from); from == "" {
+			return ErrUnknownVersion
+		}
+	}
+	if to != "" {
+		if to = NormalizeVersion(to); to == "" {
+			return ErrUnknownVersion
+		}
+	}
+
+	prefix := strings.TrimSuffix(strings.ToLower(filter.PackagePrefix), "/")
+	keep := func(data symbolData) bool {
+		switch {
+		case filter.Deprecated && data.versions[1] == "",
+			from != "" && CompareVersion(data.versions[0], from) < 0,
+			to != "" && CompareVersion(data.versions[0], to) > 0:
+			return false
+		}
+		return len(filter.Kinds) == 0 || slices.Contains(filter.Kinds, definitionKind(data.definition))
+	}
+
+	for _, pkg := range slices.Sorted(maps.Keys(vd.data)) {
+		if prefix != "" && pkg != prefix && !strings.HasPrefix(pkg, prefix+"/") {
+			continue
+		}
+
+		var kept []symbolData
+		if data := vd.data[pkg][""]; keep(data) {
+			kept = append(kept, data)
+		}
+		for _, symbols := range []map[string]symbolData{vd.data[pkg], vd.variants[pkg]} {
+			for _, data := range symbols {
+				if data.name != "" && keep(data) { // without name, the package itself or a deprecation of an undeclared symbol
+					kept = append(kept, data)
+				}
+			}
+		}
+		slices.SortFunc(kept, func(a symbolData, b symbolData) int {
+			return strings.Compare(a.name, b.name) // the package itself (without name) first
+		})
+
+		for _, data := range kept {
+			if !fn(Entry{Package: pkg, SymbolInfo: vd.symbolInfo(pkg, data)}) {
+				return nil
+			}
+		}
+	}
+	return nil
+}